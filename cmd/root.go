@@ -3,6 +3,7 @@ package cmd
 import (
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -40,14 +41,44 @@ var rootCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		// Time zone for job schedules, defaults to the exporter's local time zone
+		scheduleLocation := time.Local
+		if tz := viper.GetString("schedule-timezone"); tz != "" {
+			scheduleLocation, err = time.LoadLocation(tz)
+			if err != nil {
+				log.Logger.Error("invalid schedule time zone", "timezone", tz, "error", err.Error())
+				os.Exit(1)
+			}
+		}
+
 		// Settings for metrics exporter
 		if viper.GetBool("enable-snapshot-metrics") {
 			log.Logger.Info("Guest snapshot metrics enabled ✓")
 		} else {
 			log.Logger.Info("Guest snapshot metrics disabled ˟")
 		}
+		if viper.GetBool("enable-guest-agent-metrics") {
+			log.Logger.Info("Guest agent metrics enabled ✓")
+		} else {
+			log.Logger.Info("Guest agent metrics disabled ˟")
+		}
+		if viper.GetBool("enable-sd-guest-agent") {
+			log.Logger.Info("Guest agent service discovery enabled ✓")
+		} else {
+			log.Logger.Info("Guest agent service discovery disabled ˟")
+		}
+		if viper.GetBool("enable-guest-agent-metrics") || viper.GetBool("enable-sd-guest-agent") {
+			proxmox.SetGuestAgentLimits(viper.GetDuration("guest-agent-timeout"), viper.GetInt("guest-agent-concurrency"))
+		}
 		prometheus.Init(prometheus.Config{
-			EnableSnapshotMetrics: viper.GetBool("enable-snapshot-metrics"),
+			EnableSnapshotMetrics:         viper.GetBool("enable-snapshot-metrics"),
+			EnableGuestAgentMetrics:       viper.GetBool("enable-guest-agent-metrics"),
+			EnableSDGuestAgent:            viper.GetBool("enable-sd-guest-agent"),
+			SDDeadline:                    viper.GetDuration("sd-deadline"),
+			GuestAgentDeadline:            viper.GetDuration("guest-agent-deadline"),
+			BackupCoverageIgnoreTemplates: viper.GetBool("backup-coverage-ignore-templates"),
+			BackupCoverageIgnoreTags:      splitList(viper.GetString("backup-coverage-ignore-tags")),
+			ScheduleLocation:              scheduleLocation,
 		})
 
 		// Start http server
@@ -79,6 +110,15 @@ func init() {
 	rootCmd.PersistentFlags().String("proxmox-token", "", "Proxmox API token")
 	rootCmd.PersistentFlags().Bool("proxmox-api-insecure", false, "Whether or not this client should accept insecure connections to Proxmox (default: false)")
 	rootCmd.PersistentFlags().Bool("enable-snapshot-metrics", true, "Enable to export Qemu/LXC snapshot metrics")
+	rootCmd.PersistentFlags().Bool("enable-guest-agent-metrics", false, "Enable to export metrics read from the QEMU guest agent of running VMs, like their filesystem usage, OS and IP addresses. Requires the VM.Monitor privilege")
+	rootCmd.PersistentFlags().Bool("enable-sd-guest-agent", false, "Enable to read the addresses of VMs returned by the /sd/guests service discovery endpoint from their QEMU guest agent. Without it only LXCs are discovered. Requires the VM.Monitor privilege")
+	rootCmd.PersistentFlags().Duration("sd-deadline", 10*time.Second, "Maximum time the /sd/guests service discovery endpoint waits for the addresses of guests. Guests whose addresses weren't read by then are left out of the response")
+	rootCmd.PersistentFlags().Duration("guest-agent-timeout", 5*time.Second, "Timeout of each request to a VM's QEMU guest agent")
+	rootCmd.PersistentFlags().Int("guest-agent-concurrency", 4, "Maximum number of requests to QEMU guest agents that may be in flight at once")
+	rootCmd.PersistentFlags().Duration("guest-agent-deadline", 10*time.Second, "Maximum time a scrape waits for the QEMU guest agents of a node. Agents that haven't responded by then are left out of the scrape")
+	rootCmd.PersistentFlags().Bool("backup-coverage-ignore-templates", false, "Exclude templates from guest backup coverage metrics")
+	rootCmd.PersistentFlags().String("backup-coverage-ignore-tags", "", "Exclude guests with any of these tags from guest backup coverage metrics, you can pass in multiple tags separated by commas")
+	rootCmd.PersistentFlags().String("schedule-timezone", "", "The IANA time zone PVE evaluates job schedules in, used to calculate their next run. Should match your PVE nodes' time zone (ex: Europe/Berlin) (default: the exporter's local time zone)")
 
 	err := viper.BindPFlag("log-level", rootCmd.PersistentFlags().Lookup("log-level"))
 	if err != nil {
@@ -127,4 +167,70 @@ func init() {
 		log.Logger.Error(err.Error())
 		os.Exit(1)
 	}
+
+	err = viper.BindPFlag("enable-guest-agent-metrics", rootCmd.PersistentFlags().Lookup("enable-guest-agent-metrics"))
+	if err != nil {
+		log.Logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = viper.BindPFlag("enable-sd-guest-agent", rootCmd.PersistentFlags().Lookup("enable-sd-guest-agent"))
+	if err != nil {
+		log.Logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = viper.BindPFlag("sd-deadline", rootCmd.PersistentFlags().Lookup("sd-deadline"))
+	if err != nil {
+		log.Logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = viper.BindPFlag("guest-agent-timeout", rootCmd.PersistentFlags().Lookup("guest-agent-timeout"))
+	if err != nil {
+		log.Logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = viper.BindPFlag("guest-agent-concurrency", rootCmd.PersistentFlags().Lookup("guest-agent-concurrency"))
+	if err != nil {
+		log.Logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = viper.BindPFlag("guest-agent-deadline", rootCmd.PersistentFlags().Lookup("guest-agent-deadline"))
+	if err != nil {
+		log.Logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = viper.BindPFlag("backup-coverage-ignore-templates", rootCmd.PersistentFlags().Lookup("backup-coverage-ignore-templates"))
+	if err != nil {
+		log.Logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = viper.BindPFlag("backup-coverage-ignore-tags", rootCmd.PersistentFlags().Lookup("backup-coverage-ignore-tags"))
+	if err != nil {
+		log.Logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = viper.BindPFlag("schedule-timezone", rootCmd.PersistentFlags().Lookup("schedule-timezone"))
+	if err != nil {
+		log.Logger.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+// splitList splits a comma separated flag value into its non-empty, trimmed items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,340 @@
+package calendarevent
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// horizon is how far ahead Next searches for a matching time before giving up
+const horizon = 5 * 366
+
+// specialEvents are the shorthands PVE accepts in place of a full calendar event
+var specialEvents = map[string]string{
+	"minutely":      "*-*-* *:*:00",
+	"hourly":        "*-*-* *:00:00",
+	"daily":         "*-*-* 00:00:00",
+	"weekly":        "mon *-*-* 00:00:00",
+	"monthly":       "*-*-01 00:00:00",
+	"yearly":        "*-01-01 00:00:00",
+	"annually":      "*-01-01 00:00:00",
+	"quarterly":     "*-01,04,07,10-01 00:00:00",
+	"semiannually":  "*-01,07-01 00:00:00",
+	"semi-annually": "*-01,07-01 00:00:00",
+}
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// weekdayFullNames are the unabbreviated weekday names, in the same order as weekdayNames
+var weekdayFullNames = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// Event is a parsed systemd-like calendar event, the format PVE uses for backup and replication job schedules.
+// It takes the form of "[WEEKDAY] [[YEARS-]MONTHS-DAYS] [HOURS:MINUTES[:SECONDS]]", see: https://pve.proxmox.com/pve-docs/pve-admin-guide.html#chapter_calendar_events
+type Event struct {
+	weekdays [7]bool
+	years    field
+	months   field
+	days     field
+	hours    field
+	minutes  field
+	seconds  field
+}
+
+// field matches the values of one part of a calendar event. An empty field matches any value
+type field []component
+
+// component is a single value, range or repetition of a field, matching start through end in increments of step
+type component struct {
+	start int
+	end   int
+	step  int
+}
+
+// fieldBounds contains the minimum and maximum values of a field
+type fieldBounds struct {
+	name string
+	min  int
+	max  int
+}
+
+var (
+	yearBounds   = fieldBounds{"year", 1970, 9999}
+	monthBounds  = fieldBounds{"month", 1, 12}
+	dayBounds    = fieldBounds{"day", 1, 31}
+	hourBounds   = fieldBounds{"hour", 0, 23}
+	minuteBounds = fieldBounds{"minute", 0, 59}
+	secondBounds = fieldBounds{"second", 0, 59}
+)
+
+// Parse parses a calendar event, such as "mon..fri 21:00", "sat *-1..7 15:00", "*/30" or "daily"
+func Parse(s string) (*Event, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if special, ok := specialEvents[s]; ok {
+		s = special
+	}
+	if s == "" {
+		return nil, fmt.Errorf("empty calendar event")
+	}
+
+	e := &Event{
+		weekdays: [7]bool{true, true, true, true, true, true, true},
+		// The time defaults to midnight when only a weekday and/or date is given
+		hours:   field{{0, 0, 1}},
+		minutes: field{{0, 0, 1}},
+		seconds: field{{0, 0, 1}},
+	}
+
+	tokens := strings.Fields(s)
+	if len(tokens) > 0 && tokens[0][0] >= 'a' && tokens[0][0] <= 'z' {
+		weekdays, err := parseWeekdays(tokens[0])
+		if err != nil {
+			return nil, err
+		}
+		e.weekdays = weekdays
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 0 && strings.Contains(tokens[0], "-") {
+		err := e.parseDate(tokens[0])
+		if err != nil {
+			return nil, err
+		}
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 0 {
+		err := e.parseTime(tokens[0])
+		if err != nil {
+			return nil, err
+		}
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 0 {
+		return nil, fmt.Errorf("unexpected %q in calendar event %q", tokens[0], s)
+	}
+
+	return e, nil
+}
+
+// Next returns the first time after t that the event occurs in t's location. It returns false if the event doesn't occur again
+func (e *Event) Next(t time.Time) (time.Time, bool) {
+	from := t.Truncate(time.Second).Add(time.Second)
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+
+	for i := 0; i < horizon; i++ {
+		if e.matchesDate(day) {
+			// Only times after t are valid on the first day, any time of day is valid after that
+			fromHour, fromMinute, fromSecond := 0, 0, 0
+			if i == 0 {
+				fromHour, fromMinute, fromSecond = from.Hour(), from.Minute(), from.Second()
+			}
+			if next, ok := e.nextTimeOfDay(day, fromHour, fromMinute, fromSecond); ok {
+				return next, true
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return time.Time{}, false
+}
+
+// matchesDate returns true if the event occurs on the date of day
+func (e *Event) matchesDate(day time.Time) bool {
+	return e.weekdays[day.Weekday()] &&
+		e.years.matches(day.Year()) &&
+		e.months.matches(int(day.Month())) &&
+		e.days.matches(day.Day())
+}
+
+// nextTimeOfDay returns the first time on day, at or after the given hour, minute and second, that the event occurs
+func (e *Event) nextTimeOfDay(day time.Time, fromHour, fromMinute, fromSecond int) (time.Time, bool) {
+	for hour := fromHour; hour <= hourBounds.max; hour++ {
+		if !e.hours.matches(hour) {
+			continue
+		}
+		minute := 0
+		if hour == fromHour {
+			minute = fromMinute
+		}
+		for ; minute <= minuteBounds.max; minute++ {
+			if !e.minutes.matches(minute) {
+				continue
+			}
+			second := 0
+			if hour == fromHour && minute == fromMinute {
+				second = fromSecond
+			}
+			for ; second <= secondBounds.max; second++ {
+				if e.seconds.matches(second) {
+					return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, second, 0, day.Location()), true
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseWeekdays parses a comma separated list of weekdays and weekday ranges, such as "mon..fri,sun"
+func parseWeekdays(s string) ([7]bool, error) {
+	var weekdays [7]bool
+	for _, item := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(item, "..")
+		start, err := parseWeekday(first)
+		if err != nil {
+			return weekdays, err
+		}
+		end := start
+		if isRange {
+			end, err = parseWeekday(last)
+			if err != nil {
+				return weekdays, err
+			}
+		}
+
+		// Ranges may wrap around the end of the week, ex: "sat..mon"
+		for day := start; ; day = (day + 1) % 7 {
+			weekdays[day] = true
+			if day == end {
+				break
+			}
+		}
+	}
+	return weekdays, nil
+}
+
+// parseWeekday parses a weekday name, either in full or abbreviated to its first 3 letters
+func parseWeekday(s string) (int, error) {
+	for i := range weekdayNames {
+		if s == weekdayNames[i] || s == weekdayFullNames[i] {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q", s)
+}
+
+// parseDate parses a date of the form "[YEARS-]MONTHS-DAYS"
+func (e *Event) parseDate(s string) error {
+	parts := strings.Split(s, "-")
+	if len(parts) == 3 {
+		years, err := parseField(parts[0], yearBounds)
+		if err != nil {
+			return err
+		}
+		e.years = years
+		parts = parts[1:]
+	}
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid date %q", s)
+	}
+
+	months, err := parseField(parts[0], monthBounds)
+	if err != nil {
+		return err
+	}
+	days, err := parseField(parts[1], dayBounds)
+	if err != nil {
+		return err
+	}
+	e.months = months
+	e.days = days
+
+	return nil
+}
+
+// parseTime parses a time of the form "[HOURS:]MINUTES[:SECONDS]". A time of only minutes occurs every hour
+func (e *Event) parseTime(s string) error {
+	parts := strings.Split(s, ":")
+	hours, minutes, seconds := "*", "", "0"
+	switch len(parts) {
+	case 1:
+		minutes = parts[0]
+	case 2:
+		hours, minutes = parts[0], parts[1]
+	case 3:
+		hours, minutes, seconds = parts[0], parts[1], parts[2]
+	default:
+		return fmt.Errorf("invalid time %q", s)
+	}
+
+	var err error
+	e.hours, err = parseField(hours, hourBounds)
+	if err != nil {
+		return err
+	}
+	e.minutes, err = parseField(minutes, minuteBounds)
+	if err != nil {
+		return err
+	}
+	e.seconds, err = parseField(seconds, secondBounds)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseField parses a comma separated list of values, ranges ("8..17") and repetitions ("*/15", "5/20", "8..17/2")
+func parseField(s string, bounds fieldBounds) (field, error) {
+	var f field
+	for _, item := range strings.Split(s, ",") {
+		value, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid %s repetition %q", bounds.name, item)
+			}
+		}
+
+		// A wildcard without repetition matches anything, which makes the whole field match anything
+		if value == "*" && !hasStep {
+			return nil, nil
+		}
+
+		c := component{start: bounds.min, end: bounds.max, step: step}
+		if value != "*" {
+			first, last, isRange := strings.Cut(value, "..")
+			var err error
+			c.start, err = parseValue(first, bounds)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case isRange:
+				c.end, err = parseValue(last, bounds)
+				if err != nil {
+					return nil, err
+				}
+			case !hasStep:
+				c.end = c.start
+			}
+		}
+		if c.end < c.start {
+			return nil, fmt.Errorf("invalid %s range %q", bounds.name, item)
+		}
+		f = append(f, c)
+	}
+	return f, nil
+}
+
+// parseValue parses a single value of a field, and checks that it's within the field's bounds
+func parseValue(s string, bounds fieldBounds) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < bounds.min || v > bounds.max {
+		return 0, fmt.Errorf("invalid %s %q", bounds.name, s)
+	}
+	return v, nil
+}
+
+// matches returns true if any component of the field matches v
+func (f field) matches(v int) bool {
+	if len(f) == 0 {
+		return true
+	}
+	for _, c := range f {
+		if v >= c.start && v <= c.end && (v-c.start)%c.step == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package calendarevent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// Wednesday, 2024-01-03 10:20:30 UTC
+	now := time.Date(2024, time.January, 3, 10, 20, 30, 0, time.UTC)
+
+	tests := []struct {
+		event    string
+		expected time.Time
+	}{
+		{"daily", time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{"hourly", time.Date(2024, 1, 3, 11, 0, 0, 0, time.UTC)},
+		{"minutely", time.Date(2024, 1, 3, 10, 21, 0, 0, time.UTC)},
+		{"weekly", time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)},
+		{"monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"yearly", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"quarterly", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"21:00", time.Date(2024, 1, 3, 21, 0, 0, 0, time.UTC)},
+		{"02:30", time.Date(2024, 1, 4, 2, 30, 0, 0, time.UTC)},
+		{"10:20:45", time.Date(2024, 1, 3, 10, 20, 45, 0, time.UTC)},
+		{"sat 02:00", time.Date(2024, 1, 6, 2, 0, 0, 0, time.UTC)},
+		{"Sat 02:00", time.Date(2024, 1, 6, 2, 0, 0, 0, time.UTC)},
+		{"mon..fri 21:00", time.Date(2024, 1, 3, 21, 0, 0, 0, time.UTC)},
+		{"Saturday 02:00", time.Date(2024, 1, 6, 2, 0, 0, 0, time.UTC)},
+		{"monday..friday 21:00", time.Date(2024, 1, 3, 21, 0, 0, 0, time.UTC)},
+		{"sat..mon", time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{"mon,wed,fri", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"*/5", time.Date(2024, 1, 3, 10, 25, 0, 0, time.UTC)},
+		{"*", time.Date(2024, 1, 3, 10, 21, 0, 0, time.UTC)},
+		{"mon..wed 30/10", time.Date(2024, 1, 3, 10, 30, 0, 0, time.UTC)},
+		{"mon..fri 8..17,22:0/15", time.Date(2024, 1, 3, 10, 30, 0, 0, time.UTC)},
+		{"fri 12..13:5/20", time.Date(2024, 1, 5, 12, 5, 0, 0, time.UTC)},
+		{"12,14,16,18,20,22:5", time.Date(2024, 1, 3, 12, 5, 0, 0, time.UTC)},
+		{"*-05", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"sat *-1..7 15:00", time.Date(2024, 1, 6, 15, 0, 0, 0, time.UTC)},
+		{"*-02-29", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"2024-12-24 18:00", time.Date(2024, 12, 24, 18, 0, 0, 0, time.UTC)},
+		{"*-*-* 0/6:00", time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.event, func(t *testing.T) {
+			e, err := Parse(tt.event)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			next, ok := e.Next(now)
+			if !ok {
+				t.Fatalf("expected a next occurrence")
+			}
+			if !next.Equal(tt.expected) {
+				t.Errorf("expected %s, got %s", tt.expected, next)
+			}
+		})
+	}
+}
+
+func TestNext_Location(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	e, err := Parse("21:00")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 20:00 UTC is already past 21:00 in UTC+2, so the next run is 21:00 the following day in that zone
+	next, ok := e.Next(time.Date(2024, 1, 3, 20, 0, 0, 0, time.UTC).In(loc))
+	if !ok {
+		t.Fatalf("expected a next occurrence")
+	}
+	if expected := time.Date(2024, 1, 4, 19, 0, 0, 0, time.UTC); !next.Equal(expected) {
+		t.Errorf("expected %s, got %s", expected, next)
+	}
+}
+
+func TestNext_NeverAgain(t *testing.T) {
+	e, err := Parse("2015-10-21")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := e.Next(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)); ok {
+		t.Error("expected no next occurrence for a date in the past")
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"funday",
+		"mo",
+		"month",
+		"sunny 12:00",
+		"mon..fridays",
+		"tues",
+		"25:00",
+		"12:60",
+		"*-13-01",
+		"*-*-32",
+		"1-2-3-4",
+		"*/0",
+		"17..8:00",
+		"daily 12:00 extra",
+		"1:2:3:4",
+		"abc:00",
+	}
+
+	for _, event := range tests {
+		t.Run(event, func(t *testing.T) {
+			if _, err := Parse(event); err == nil {
+				t.Errorf("expected error parsing %q", event)
+			}
+		})
+	}
+}
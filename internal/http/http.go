@@ -44,6 +44,9 @@ func (s *Server) StartServer() error {
 	prometheus.MustRegister(internalProm.NewCollector())
 	r.Handle("/metrics", promhttp.Handler())
 
+	// Set service discovery handler
+	r.HandleFunc("/sd/guests", sdGuests).Methods(http.MethodGet)
+
 	srv := &http.Server{
 		Handler: r,
 		Addr:    fmt.Sprintf("%s:%d", s.addr, s.port),
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	log "github.com/starttoaster/proxmox-exporter/internal/logger"
+
+	internalProm "github.com/starttoaster/proxmox-exporter/internal/prometheus"
+)
+
+// defaultSDPort is the port of guest targets when none is requested, the port node_exporter listens on
+const defaultSDPort = 9100
+
+// sdIgnoredInterfaces are prefixes of the names of guest interfaces that usually can't be reached from outside the guest,
+// like the bridges of container runtimes and VPN tunnels, so their addresses aren't targeted
+var sdIgnoredInterfaces = []string{"docker", "br-", "veth", "virbr", "lxcbr", "lxdbr", "podman", "cni", "flannel", "cali", "vxlan", "kube-", "tun", "tap", "wg", "tailscale", "zt"}
+
+// sdTargetGroup is a group of targets in the Prometheus HTTP service discovery format
+type sdTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// sdGuests is a Prometheus HTTP service discovery endpoint that returns a target for each running guest with a known address.
+// Guests can be filtered with the tags (comma separated, matching guests with any of them) and pool query parameters,
+// the addresses targeted limited to the comma separated CIDRs of the cidr query parameter, and the port of the targets set
+// with the port query parameter
+func sdGuests(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	port := defaultSDPort
+	if p := query.Get("port"); p != "" {
+		var err error
+		port, err = strconv.Atoi(p)
+		if err != nil || port < 1 || port > 65535 {
+			http.Error(w, "invalid port: "+p, http.StatusBadRequest)
+			return
+		}
+	}
+
+	var prefixes []netip.Prefix
+	for _, cidr := range strings.Split(query.Get("cidr"), ",") {
+		if cidr = strings.TrimSpace(cidr); cidr == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			http.Error(w, "invalid cidr: "+cidr, http.StatusBadRequest)
+			return
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	var tags []string
+	for _, tag := range strings.Split(query.Get("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	guests, err := internalProm.DiscoverGuests(tags, query.Get("pool"))
+	if err != nil {
+		log.Logger.Error("failed to discover guests for the sd endpoint", "error", err.Error())
+		http.Error(w, "failed to discover guests", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(sdTargetGroups(guests, port, prefixes))
+	if err != nil {
+		log.Logger.Error("failed to write response for sd endpoint", "error", err.Error())
+	}
+}
+
+// sdTargetGroups returns a target group for each guest with an address to target, see sdTargetAddress.
+// All of a guest's addresses are listed in its __meta_proxmox_ips label, with a comma at each end so each can be matched by relabeling
+func sdTargetGroups(guests []internalProm.DiscoveredGuest, port int, prefixes []netip.Prefix) []sdTargetGroup {
+	groups := make([]sdTargetGroup, 0, len(guests))
+	for _, guest := range guests {
+		address := sdTargetAddress(guest, prefixes)
+		if address == "" {
+			continue
+		}
+		ips := ","
+		for _, ip := range guest.IPs {
+			ips += ip.Address + ","
+		}
+
+		groups = append(groups, sdTargetGroup{
+			Targets: []string{net.JoinHostPort(address, strconv.Itoa(port))},
+			Labels: map[string]string{
+				"__meta_proxmox_node": guest.Node,
+				"__meta_proxmox_type": guest.Type,
+				"__meta_proxmox_name": guest.Name,
+				"__meta_proxmox_vmid": guest.VMID,
+				"__meta_proxmox_tags": guest.Tags,
+				"__meta_proxmox_pool": guest.Pool,
+				"__meta_proxmox_ips":  ips,
+			},
+		})
+	}
+	return groups
+}
+
+// sdTargetAddress returns the address a guest is targeted at. When prefixes are given, only addresses in any of them are targeted,
+// otherwise addresses on interfaces that are usually unreachable (see sdIgnoredInterfaces) aren't. Addresses on the guest's primary
+// interface are preferred over those on other interfaces, and IPv4 addresses over IPv6 addresses. It's empty if no address may be targeted
+func sdTargetAddress(guest internalProm.DiscoveredGuest, prefixes []netip.Prefix) string {
+	address := ""
+	bestRank := 0
+	for _, ip := range guest.IPs {
+		if len(prefixes) > 0 {
+			addr, err := netip.ParseAddr(ip.Address)
+			if err != nil || !slices.ContainsFunc(prefixes, func(p netip.Prefix) bool { return p.Contains(addr) }) {
+				continue
+			}
+		} else if slices.ContainsFunc(sdIgnoredInterfaces, func(prefix string) bool { return strings.HasPrefix(ip.Interface, prefix) }) {
+			continue
+		}
+
+		rank := 1
+		if guest.PrimaryInterface != "" && ip.Interface == guest.PrimaryInterface {
+			rank += 2
+		}
+		if ip.Family == "ipv4" {
+			rank++
+		}
+		if rank > bestRank {
+			address, bestRank = ip.Address, rank
+		}
+	}
+	return address
+}
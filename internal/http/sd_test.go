@@ -0,0 +1,200 @@
+package http
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	internalProm "github.com/starttoaster/proxmox-exporter/internal/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestSDTargetGroups(t *testing.T) {
+	guests := []internalProm.DiscoveredGuest{
+		{Node: "node1", Type: "qemu", Name: "web", VMID: "100", Tags: "prod;web", Pool: "prod", IPs: []internalProm.GuestIP{
+			{Interface: "eth0", Address: "2001:db8::100", Family: "ipv6"},
+			{Interface: "eth0", Address: "192.168.1.100", Family: "ipv4"},
+			{Interface: "eth1", Address: "10.0.0.100", Family: "ipv4"},
+		}},
+		{Node: "node1", Type: "lxc", Name: "dns", VMID: "200", IPs: []internalProm.GuestIP{
+			{Interface: "eth0", Address: "2001:db8::53", Family: "ipv6"},
+		}},
+		{Node: "node2", Type: "qemu", Name: "db", VMID: "101"},
+	}
+
+	groups := sdTargetGroups(guests, 9100, nil)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 target groups, got %d: %+v", len(groups), groups)
+	}
+
+	expectedTargets := []string{"192.168.1.100:9100", "[2001:db8::53]:9100"}
+	for i, group := range groups {
+		if len(group.Targets) != 1 || group.Targets[0] != expectedTargets[i] {
+			t.Errorf("group %d: expected target %s, got %v", i, expectedTargets[i], group.Targets)
+		}
+	}
+
+	expectedLabels := map[string]string{
+		"__meta_proxmox_node": "node1",
+		"__meta_proxmox_type": "qemu",
+		"__meta_proxmox_name": "web",
+		"__meta_proxmox_vmid": "100",
+		"__meta_proxmox_tags": "prod;web",
+		"__meta_proxmox_pool": "prod",
+		"__meta_proxmox_ips":  ",2001:db8::100,192.168.1.100,10.0.0.100,",
+	}
+	if len(groups[0].Labels) != len(expectedLabels) {
+		t.Errorf("expected %d labels, got %v", len(expectedLabels), groups[0].Labels)
+	}
+	for label, value := range expectedLabels {
+		if groups[0].Labels[label] != value {
+			t.Errorf("%s: expected %q, got %q", label, value, groups[0].Labels[label])
+		}
+	}
+}
+
+func TestSDTargetAddress(t *testing.T) {
+	ips := []internalProm.GuestIP{
+		{Interface: "docker0", Address: "172.17.0.1", Family: "ipv4"},
+		{Interface: "br-5f1c2a", Address: "172.18.0.1", Family: "ipv4"},
+		{Interface: "wg0", Address: "10.8.0.2", Family: "ipv4"},
+		{Interface: "eth0", Address: "2001:db8::100", Family: "ipv6"},
+		{Interface: "eth1", Address: "10.0.0.100", Family: "ipv4"},
+		{Interface: "eth0", Address: "192.168.1.100", Family: "ipv4"},
+	}
+
+	tests := []struct {
+		name     string
+		guest    internalProm.DiscoveredGuest
+		cidrs    []string
+		expected string
+	}{
+		{"skips bridge and vpn interfaces", internalProm.DiscoveredGuest{IPs: ips}, nil, "10.0.0.100"},
+		{"prefers the primary interface", internalProm.DiscoveredGuest{IPs: ips, PrimaryInterface: "eth0"}, nil, "192.168.1.100"},
+		{"prefers ipv6 on the primary interface", internalProm.DiscoveredGuest{IPs: ips[:5], PrimaryInterface: "eth0"}, nil, "2001:db8::100"},
+		{"cidr", internalProm.DiscoveredGuest{IPs: ips, PrimaryInterface: "eth0"}, []string{"10.0.0.0/8"}, "10.8.0.2"},
+		{"cidr prefers the primary interface", internalProm.DiscoveredGuest{IPs: ips, PrimaryInterface: "eth1"}, []string{"10.0.0.0/8"}, "10.0.0.100"},
+		{"ipv6 cidr", internalProm.DiscoveredGuest{IPs: ips}, []string{"192.0.2.0/24", "2001:db8::/32"}, "2001:db8::100"},
+		{"no address in cidr", internalProm.DiscoveredGuest{IPs: ips}, []string{"192.0.2.0/24"}, ""},
+		{"only ignored interfaces", internalProm.DiscoveredGuest{IPs: ips[:3]}, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prefixes []netip.Prefix
+			for _, cidr := range tt.cidrs {
+				prefixes = append(prefixes, netip.MustParsePrefix(cidr))
+			}
+			if got := sdTargetAddress(tt.guest, prefixes); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSDTargetGroups_NoGuests(t *testing.T) {
+	body, err := json.Marshal(sdTargetGroups(nil, 9100, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Prometheus expects an empty list rather than null
+	if string(body) != "[]" {
+		t.Errorf("expected [], got %s", body)
+	}
+}
+
+func TestSDGuests(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/cluster/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"data": [{"type": "node", "id": "node/node1", "name": "node1", "online": 1}]}`)
+	})
+	mux.HandleFunc("/api2/json/cluster/resources", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"data": [
+			{"id": "node/node1", "node": "node1", "type": "node", "status": "online"},
+			{"id": "lxc/200", "node": "node1", "type": "lxc", "status": "running", "name": "dns-server", "vmid": 200, "tags": "infra", "pool": "core"},
+			{"id": "lxc/201", "node": "node1", "type": "lxc", "status": "running", "name": "proxy", "vmid": 201, "tags": "edge"},
+			{"id": "lxc/202", "node": "node1", "type": "lxc", "status": "stopped", "name": "old", "vmid": 202, "tags": "infra"}
+		]}`)
+	})
+	mux.HandleFunc("/api2/json/nodes/{node}/lxc/{vmid}/interfaces", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"data": [
+			{"name": "lo", "inet": "127.0.0.1/8"},
+			{"name": "docker0", "hwaddr": "02:42:ac:11:00:01", "inet": "172.17.0.1/16"},
+			{"name": "eth1", "hwaddr": "bc:24:11:00:00:02", "inet": "10.0.0.%[1]s/24"},
+			{"name": "eth0", "hwaddr": "bc:24:11:00:00:01", "inet": "192.168.1.%[1]s/24"}
+		]}`, r.PathValue("vmid"))
+	})
+	mux.HandleFunc("/api2/json/nodes/{node}/lxc/{vmid}/config", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"data": {"net0": "name=eth0,bridge=vmbr0,hwaddr=BC:24:11:00:00:01,ip=dhcp,type=veth"}}`)
+	})
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	http.DefaultTransport = &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	defer func() {
+		http.DefaultTransport = &http.Transport{}
+	}()
+
+	tests := []struct {
+		name            string
+		query           string
+		expectedStatus  int
+		expectedTargets []string
+	}{
+		{"all running guests", "", http.StatusOK, []string{"192.168.1.200:9100", "192.168.1.201:9100"}},
+		{"tags", "?tags=INFRA,missing", http.StatusOK, []string{"192.168.1.200:9100"}},
+		{"pool", "?pool=core", http.StatusOK, []string{"192.168.1.200:9100"}},
+		{"port", "?port=9256&tags=edge", http.StatusOK, []string{"192.168.1.201:9256"}},
+		{"cidr", "?cidr=10.0.0.0/24", http.StatusOK, []string{"10.0.0.200:9100", "10.0.0.201:9100"}},
+		{"no match", "?pool=missing", http.StatusOK, []string{}},
+		{"invalid cidr", "?cidr=10.0.0.0/33", http.StatusBadRequest, nil},
+		{"invalid port", "?port=http", http.StatusBadRequest, nil},
+		{"port out of range", "?port=70000", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := wrappedProxmox.Init([]string{server.URL}, "test-id", "test-token", true); err != nil {
+				t.Fatalf("failed to init proxmox: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/sd/guests"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			sdGuests(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("expected application/json content type, got %q", ct)
+			}
+
+			var groups []sdTargetGroup
+			if err := json.Unmarshal(w.Body.Bytes(), &groups); err != nil {
+				t.Fatalf("invalid response body: %v", err)
+			}
+			if len(groups) != len(tt.expectedTargets) {
+				t.Fatalf("expected %d target groups, got %d: %s", len(tt.expectedTargets), len(groups), w.Body.String())
+			}
+			for i, group := range groups {
+				if len(group.Targets) != 1 || group.Targets[0] != tt.expectedTargets[i] {
+					t.Errorf("group %d: expected target %s, got %v", i, tt.expectedTargets[i], group.Targets)
+				}
+			}
+		})
+	}
+}
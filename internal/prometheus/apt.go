@@ -0,0 +1,78 @@
+package prometheus
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// kernelPackagePrefixes are the prefixes of the kernel image package names, before the kernel version (ex: proxmox-kernel-6.5.13-1-pve-signed).
+// pve-kernel is the name of the packages before PVE 8
+var kernelPackagePrefixes = []string{"proxmox-kernel-", "pve-kernel-"}
+
+// updateKey groups pending package updates by where they come from
+type updateKey struct {
+	origin   string
+	priority string
+}
+
+// collectUpdatesPendingMetrics exports the number of a node's packages that have an update available, by origin and priority
+func (c *Collector) collectUpdatesPendingMetrics(ch chan<- prometheus.Metric, nodeName string, updates *wrappedProxmox.GetNodeAptUpdateResponse) {
+	pending := make(map[updateKey]int)
+	for _, pkg := range updates.Data {
+		pending[updateKey{origin: pkg.Origin, priority: pkg.Priority}]++
+	}
+	for key, count := range pending {
+		ch <- prometheus.MustNewConstMetric(c.updatesPending, prometheus.GaugeValue, float64(count), nodeName, key.origin, key.priority)
+	}
+}
+
+// collectRebootRequiredMetric exports whether a node has a newer kernel installed than the one it's running
+func (c *Collector) collectRebootRequiredMetric(ch chan<- prometheus.Metric, nodeName, kversion string, versions *wrappedProxmox.GetNodeAptVersionsResponse) {
+	running := runningKernelVersion(kversion)
+	newest := newestInstalledKernelVersion(versions)
+	if running == "" || newest == "" {
+		return
+	}
+
+	required := 0.0
+	if compareVersions(newest, running) > 0 {
+		required = 1.0
+	}
+	ch <- prometheus.MustNewConstMetric(c.rebootRequired, prometheus.GaugeValue, required, nodeName)
+}
+
+// runningKernelVersion returns the package version of a node's running kernel from its kversion (ex: "Linux 6.5.11-8-pve #1 SMP ..." is 6.5.11-8)
+func runningKernelVersion(kversion string) string {
+	fields := strings.Fields(kversion)
+	if len(fields) < 2 {
+		return ""
+	}
+	return strings.TrimSuffix(fields[1], "-pve")
+}
+
+// newestInstalledKernelVersion returns the newest version of the kernel packages installed on a node
+func newestInstalledKernelVersion(versions *wrappedProxmox.GetNodeAptVersionsResponse) string {
+	newest := ""
+	for _, pkg := range versions.Data {
+		if !isKernelPackage(pkg.Package) || !strings.EqualFold(pkg.CurrentState, "Installed") {
+			continue
+		}
+		if newest == "" || compareVersions(pkg.Version, newest) > 0 {
+			newest = pkg.Version
+		}
+	}
+	return newest
+}
+
+// isKernelPackage returns true for kernel image packages, which are named after a kernel version, but not for kernel tooling like proxmox-kernel-helper
+func isKernelPackage(name string) bool {
+	for _, prefix := range kernelPackagePrefixes {
+		rest, found := strings.CutPrefix(name, prefix)
+		if found && rest != "" && rest[0] >= '0' && rest[0] <= '9' {
+			return true
+		}
+	}
+	return false
+}
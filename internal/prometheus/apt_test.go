@@ -0,0 +1,122 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestCollectUpdatesPendingMetrics(t *testing.T) {
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 10)
+
+	c.collectUpdatesPendingMetrics(ch, "node1", &wrappedProxmox.GetNodeAptUpdateResponse{
+		Data: []wrappedProxmox.GetNodeAptUpdateData{
+			{Package: "pve-manager", Origin: "Proxmox", Priority: "optional"},
+			{Package: "qemu-server", Origin: "Proxmox", Priority: "optional"},
+			{Package: "openssl", Origin: "Debian", Priority: "important"},
+			{Package: "libc6", Origin: "Debian", Priority: "optional"},
+		},
+	})
+	metrics := drainMetrics(ch)
+
+	expected := map[string]float64{
+		"Proxmox/optional": 2,
+		"Debian/important": 1,
+		"Debian/optional":  1,
+	}
+	if len(metrics) != len(expected) {
+		t.Fatalf("expected %d metrics, got %d", len(expected), len(metrics))
+	}
+	for _, m := range metrics {
+		labels := getMetricLabels(m)
+		key := labels["origin"] + "/" + labels["priority"]
+		if v := getMetricValue(m); v != expected[key] {
+			t.Errorf("%s: expected %f, got %f", key, expected[key], v)
+		}
+	}
+}
+
+func TestCollectRebootRequiredMetric(t *testing.T) {
+	installed := func(pkgs ...string) *wrappedProxmox.GetNodeAptVersionsResponse {
+		versions := &wrappedProxmox.GetNodeAptVersionsResponse{
+			Data: []wrappedProxmox.GetNodeAptVersionsData{
+				{Package: "proxmox-kernel-helper", Version: "8.1.0", CurrentState: "Installed"},
+				{Package: "pve-manager", Version: "8.1.3", CurrentState: "Installed"},
+			},
+		}
+		for i := 0; i < len(pkgs); i += 2 {
+			versions.Data = append(versions.Data, wrappedProxmox.GetNodeAptVersionsData{Package: pkgs[i], Version: pkgs[i+1], CurrentState: "Installed"})
+		}
+		return versions
+	}
+
+	tests := []struct {
+		name          string
+		kversion      string
+		versions      *wrappedProxmox.GetNodeAptVersionsResponse
+		expectedCount int
+		expectedValue float64
+	}{
+		{
+			name:          "running newest kernel",
+			kversion:      "Linux 6.5.13-1-pve #1 SMP PREEMPT_DYNAMIC PMX 6.5.13-1 (2024-02-05T13:50Z)",
+			versions:      installed("proxmox-kernel-6.5.11-8-pve-signed", "6.5.11-8", "proxmox-kernel-6.5.13-1-pve-signed", "6.5.13-1"),
+			expectedCount: 1,
+			expectedValue: 0,
+		},
+		{
+			name:          "newer kernel installed",
+			kversion:      "Linux 6.5.11-8-pve #1 SMP PREEMPT_DYNAMIC PMX 6.5.11-8 (2024-01-30T12:27Z)",
+			versions:      installed("proxmox-kernel-6.5.11-8-pve-signed", "6.5.11-8", "proxmox-kernel-6.5.13-1-pve-signed", "6.5.13-1"),
+			expectedCount: 1,
+			expectedValue: 1,
+		},
+		{
+			name:          "newer kernel series installed",
+			kversion:      "Linux 6.5.13-1-pve #1 SMP",
+			versions:      installed("proxmox-kernel-6.5", "6.5.13-1", "proxmox-kernel-6.8", "6.8.4-2"),
+			expectedCount: 1,
+			expectedValue: 1,
+		},
+		{
+			name:          "pre PVE 8 kernel package names",
+			kversion:      "Linux 5.15.131-2-pve #1 SMP",
+			versions:      installed("pve-kernel-5.15.131-2-pve", "5.15.131-2", "pve-kernel-5.15.136-1-pve", "5.15.136-1"),
+			expectedCount: 1,
+			expectedValue: 1,
+		},
+		{
+			name:          "no kernel packages",
+			kversion:      "Linux 6.5.13-1-pve #1 SMP",
+			versions:      installed(),
+			expectedCount: 0,
+		},
+		{
+			name:          "unknown running kernel",
+			kversion:      "",
+			versions:      installed("proxmox-kernel-6.5.13-1-pve-signed", "6.5.13-1"),
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCollector()
+			ch := make(chan prometheus.Metric, 10)
+
+			c.collectRebootRequiredMetric(ch, "node1", tt.kversion, tt.versions)
+			metrics := drainMetrics(ch)
+
+			if len(metrics) != tt.expectedCount {
+				t.Fatalf("expected %d metrics, got %d", tt.expectedCount, len(metrics))
+			}
+			if tt.expectedCount > 0 {
+				if v := getMetricValue(metrics[0]); v != tt.expectedValue {
+					t.Errorf("expected %f, got %f", tt.expectedValue, v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package prometheus
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// collectBackupCoverageMetrics exports whether each guest is included in a backup job, and the number of guests that aren't
+func (c *Collector) collectBackupCoverageMetrics(ch chan<- prometheus.Metric, guests []proxmox.GetClusterResourcesData, notBackedUp *wrappedProxmox.GetClusterBackupInfoNotBackedUpResponse) {
+	uncoveredIDs := make(map[string]bool)
+	for _, guest := range notBackedUp.Data {
+		uncoveredIDs[strconv.Itoa(guest.VMID)] = true
+	}
+
+	uncovered := 0
+	for _, guest := range guests {
+		if cfg.BackupCoverageIgnoreTemplates && guest.Template != nil && *guest.Template == 1 {
+			continue
+		}
+		name, vmid, tags := guestLabels(guest)
+		if hasAnyTag(tags, cfg.BackupCoverageIgnoreTags) {
+			continue
+		}
+
+		covered := 1.0
+		if uncoveredIDs[string(vmid)] {
+			covered = 0.0
+			uncovered++
+		}
+		ch <- prometheus.MustNewConstMetric(c.guestBackupCovered, prometheus.GaugeValue, covered, guest.Node, guest.Type, name, string(vmid), tags)
+	}
+	ch <- prometheus.MustNewConstMetric(c.clusterGuestsNotBackedUp, prometheus.GaugeValue, float64(uncovered))
+}
@@ -0,0 +1,81 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestCollectBackupCoverageMetrics(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+	vmidPtr := func(v string) *proxmox.IntOrString {
+		ios := proxmox.IntOrString(v)
+		return &ios
+	}
+
+	guests := []proxmox.GetClusterResourcesData{
+		{Node: "node1", Type: "qemu", Name: strPtr("vm1"), VMID: vmidPtr("100"), Tags: strPtr("prod")},
+		{Node: "node1", Type: "qemu", Name: strPtr("vm2"), VMID: vmidPtr("101"), Tags: strPtr("scratch;dev")},
+		{Node: "node2", Type: "qemu", Name: strPtr("template"), VMID: vmidPtr("900"), Template: intPtr(1)},
+		{Node: "node2", Type: "lxc", Name: strPtr("ct1"), VMID: vmidPtr("200")},
+	}
+	notBackedUp := &wrappedProxmox.GetClusterBackupInfoNotBackedUpResponse{
+		Data: []wrappedProxmox.GetClusterBackupInfoNotBackedUpData{
+			{VMID: 101, Type: "qemu"},
+			{VMID: 900, Type: "qemu"},
+			{VMID: 200, Type: "lxc"},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		config            Config
+		expectedGuests    int
+		expectedUncovered float64
+	}{
+		{"no filters", Config{}, 4, 3},
+		{"ignore templates", Config{BackupCoverageIgnoreTemplates: true}, 3, 2},
+		{"ignore tags", Config{BackupCoverageIgnoreTags: []string{"scratch"}}, 3, 2},
+		{"ignore templates and tags", Config{BackupCoverageIgnoreTemplates: true, BackupCoverageIgnoreTags: []string{"SCRATCH"}}, 2, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldCfg := cfg
+			cfg = tt.config
+			defer func() { cfg = oldCfg }()
+
+			c := testCollector()
+			ch := make(chan prometheus.Metric, 100)
+
+			c.collectBackupCoverageMetrics(ch, guests, notBackedUp)
+			metrics := drainMetrics(ch)
+
+			covered := findByDesc(metrics, c.guestBackupCovered)
+			if len(covered) != tt.expectedGuests {
+				t.Errorf("guestBackupCovered: expected %d, got %d", tt.expectedGuests, len(covered))
+			}
+			for _, m := range covered {
+				labels := getMetricLabels(m)
+				want := 0.0
+				if labels["vmid"] == "100" {
+					want = 1.0
+				}
+				if v := getMetricValue(m); v != want {
+					t.Errorf("vmid %s: expected covered=%f, got %f", labels["vmid"], want, v)
+				}
+			}
+
+			counts := findByDesc(metrics, c.clusterGuestsNotBackedUp)
+			if len(counts) != 1 {
+				t.Fatalf("expected 1 uncovered count metric, got %d", len(counts))
+			}
+			if v := getMetricValue(counts[0]); v != tt.expectedUncovered {
+				t.Errorf("expected %f uncovered guests, got %f", tt.expectedUncovered, v)
+			}
+		})
+	}
+}
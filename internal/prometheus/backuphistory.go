@@ -0,0 +1,304 @@
+package prometheus
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	"github.com/starttoaster/proxmox-exporter/internal/logger"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+const (
+	// vzdumpTaskPageSize is the number of tasks requested per page of a node's vzdump task history
+	vzdumpTaskPageSize = 500
+	// vzdumpTaskLogPageSize is the number of lines requested per page of a vzdump task's log
+	vzdumpTaskLogPageSize = 5000
+	// vzdumpTaskMaxAttempts is the number of updates a finished vzdump task is tried to be read in before it's given up on
+	vzdumpTaskMaxAttempts = 3
+)
+
+var (
+	vzdumpFinishedRegexp = regexp.MustCompile(`^INFO: Finished Backup of VM (\d+) \((\d+):(\d{2}):(\d{2})\)`)
+	vzdumpFailedRegexp   = regexp.MustCompile(`^ERROR: Backup of VM (\d+) failed`)
+)
+
+// backupHistory keeps the results of vzdump tasks read from each node's task history.
+// Task history is read incrementally, so each scrape only requests the tasks started since the previous one.
+type backupHistory struct {
+	mu     sync.Mutex
+	nodes  map[string]*backupTaskCursor
+	guests map[string]*guestBackupResult
+}
+
+// backupTaskCursor tracks how far a node's vzdump task history has been read
+type backupTaskCursor struct {
+	mu sync.Mutex
+	// since is the start time of the oldest task that may still need to be read
+	since int
+	// seen contains the start times of finished tasks, by UPID, that started at or after since and were already read or given up on
+	seen map[string]int
+	// attempts contains the number of failed reads of finished tasks, by UPID, that will be tried again
+	attempts map[string]int
+	// unreadable is the number of finished tasks that were given up on after failing to be read vzdumpTaskMaxAttempts times
+	unreadable int
+}
+
+// guestBackupResult contains the vzdump backup results of a single guest
+type guestBackupResult struct {
+	lastSuccess  int
+	lastDuration int
+	failures     int
+}
+
+// vzdumpGuestResult is the result of backing up a single guest in a vzdump task
+type vzdumpGuestResult struct {
+	vmid     string
+	success  bool
+	duration int
+}
+
+func newBackupHistory() *backupHistory {
+	return &backupHistory{
+		nodes:  make(map[string]*backupTaskCursor),
+		guests: make(map[string]*guestBackupResult),
+	}
+}
+
+// cursor returns the task history cursor for a node, creating it if it doesn't exist yet
+func (h *backupHistory) cursor(nodeName string) *backupTaskCursor {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cur, ok := h.nodes[nodeName]
+	if !ok {
+		cur = &backupTaskCursor{
+			seen:     make(map[string]int),
+			attempts: make(map[string]int),
+		}
+		h.nodes[nodeName] = cur
+	}
+	return cur
+}
+
+// update reads the vzdump tasks of a node that started since the last update, and records the result of each finished task
+func (h *backupHistory) update(nodeName string) error {
+	cur := h.cursor(nodeName)
+	cur.mu.Lock()
+	defer cur.mu.Unlock()
+
+	tasks, err := getNodeVzdumpTasks(nodeName, cur.since)
+	if err != nil {
+		return err
+	}
+
+	// Move the cursor up to the newest task, unless an older task is still running or couldn't be read
+	next := cur.since
+	oldestUnread := 0
+	for _, task := range tasks {
+		if task.StartTime > next {
+			next = task.StartTime
+		}
+		if task.EndTime == nil || task.Status == nil {
+			if oldestUnread == 0 || task.StartTime < oldestUnread {
+				oldestUnread = task.StartTime
+			}
+			continue
+		}
+		if _, ok := cur.seen[task.UPID]; ok {
+			continue
+		}
+
+		if err := h.recordTask(nodeName, task); err != nil {
+			// A task that keeps failing to be read (ex: its log is missing) is given up on, so it doesn't hold the cursor back forever
+			cur.attempts[task.UPID]++
+			if cur.attempts[task.UPID] < vzdumpTaskMaxAttempts {
+				logger.Logger.Error("failed reading vzdump task", "node", nodeName, "upid", task.UPID, "attempt", cur.attempts[task.UPID], "error", err.Error())
+				if oldestUnread == 0 || task.StartTime < oldestUnread {
+					oldestUnread = task.StartTime
+				}
+				continue
+			}
+			logger.Logger.Error("giving up reading vzdump task", "node", nodeName, "upid", task.UPID, "attempts", cur.attempts[task.UPID], "error", err.Error())
+			cur.unreadable++
+		}
+		delete(cur.attempts, task.UPID)
+		cur.seen[task.UPID] = task.StartTime
+	}
+	if oldestUnread != 0 && oldestUnread < next {
+		next = oldestUnread
+	}
+
+	cur.since = next
+	for upid, startTime := range cur.seen {
+		if startTime < next {
+			delete(cur.seen, upid)
+		}
+	}
+
+	return nil
+}
+
+// unreadableTasks returns the number of a node's finished vzdump tasks that were given up on because they couldn't be read
+func (h *backupHistory) unreadableTasks(nodeName string) int {
+	cur := h.cursor(nodeName)
+	cur.mu.Lock()
+	defer cur.mu.Unlock()
+
+	return cur.unreadable
+}
+
+// recordTask records the result of each guest in a finished vzdump task
+func (h *backupHistory) recordTask(nodeName string, task wrappedProxmox.GetNodeTasksData) error {
+	// Tasks that back up a single guest have its VMID as the task ID
+	if task.ID != "" {
+		h.record(vzdumpGuestResult{
+			vmid:     task.ID,
+			success:  vzdumpStatusOK(*task.Status),
+			duration: *task.EndTime - task.StartTime,
+		}, *task.EndTime)
+		return nil
+	}
+
+	// Backup jobs that include multiple guests don't have a task ID, so each guest's result is read from the task log
+	lines, err := getNodeTaskLog(nodeName, task.UPID)
+	if err != nil {
+		return err
+	}
+	for _, result := range parseVzdumpTaskLog(lines) {
+		h.record(result, *task.EndTime)
+	}
+
+	return nil
+}
+
+// record adds the result of a guest's backup in a task that finished at endTime
+func (h *backupHistory) record(result vzdumpGuestResult, endTime int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	guest, ok := h.guests[result.vmid]
+	if !ok {
+		guest = &guestBackupResult{}
+		h.guests[result.vmid] = guest
+	}
+
+	if !result.success {
+		guest.failures++
+		return
+	}
+	// Task history is read newest first, so only keep this result if it's more recent than the one we have
+	if endTime >= guest.lastSuccess {
+		guest.lastSuccess = endTime
+		guest.lastDuration = result.duration
+	}
+}
+
+// vzdumpStatusOK returns true if a vzdump task's exit status means its backup was successful
+func vzdumpStatusOK(status string) bool {
+	return status == "OK" || strings.HasPrefix(status, "WARNINGS")
+}
+
+// parseVzdumpTaskLog reads the result of each guest backed up in a vzdump task from its log lines
+func parseVzdumpTaskLog(lines []string) []vzdumpGuestResult {
+	var results []vzdumpGuestResult
+	for _, line := range lines {
+		if m := vzdumpFinishedRegexp.FindStringSubmatch(line); m != nil {
+			hours, _ := strconv.Atoi(m[2])
+			minutes, _ := strconv.Atoi(m[3])
+			seconds, _ := strconv.Atoi(m[4])
+			results = append(results, vzdumpGuestResult{
+				vmid:     m[1],
+				success:  true,
+				duration: hours*3600 + minutes*60 + seconds,
+			})
+			continue
+		}
+		if m := vzdumpFailedRegexp.FindStringSubmatch(line); m != nil {
+			results = append(results, vzdumpGuestResult{
+				vmid: m[1],
+			})
+		}
+	}
+	return results
+}
+
+// getNodeVzdumpTasks returns all of a node's vzdump tasks, including running tasks, that started at or after since
+func getNodeVzdumpTasks(nodeName string, since int) ([]wrappedProxmox.GetNodeTasksData, error) {
+	var tasks []wrappedProxmox.GetNodeTasksData
+	for start := 0; ; start += vzdumpTaskPageSize {
+		resp, err := wrappedProxmox.GetNodeTasks(nodeName, wrappedProxmox.GetNodeTasksOptions{
+			TypeFilter: "vzdump",
+			Source:     "all",
+			Since:      since,
+			Start:      start,
+			Limit:      vzdumpTaskPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, resp.Data...)
+		if len(resp.Data) < vzdumpTaskPageSize {
+			return tasks, nil
+		}
+	}
+}
+
+// getNodeTaskLog returns all log lines of a task
+func getNodeTaskLog(nodeName, upid string) ([]string, error) {
+	var lines []string
+	for start := 0; ; start += vzdumpTaskLogPageSize {
+		resp, err := wrappedProxmox.GetNodeTaskLog(nodeName, upid, wrappedProxmox.GetNodeTaskLogOptions{
+			Start: start,
+			Limit: vzdumpTaskLogPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range resp.Data {
+			lines = append(lines, line.T)
+		}
+		if len(resp.Data) < vzdumpTaskLogPageSize {
+			return lines, nil
+		}
+	}
+}
+
+// collectBackupHistoryMetrics exports the last successful backup and the backup failures of each guest read from vzdump task history.
+// The results of guests that are no longer in the cluster (ex: destroyed) are forgotten
+func (c *Collector) collectBackupHistoryMetrics(ch chan<- prometheus.Metric, guests []proxmox.GetClusterResourcesData) {
+	c.backupHistory.mu.Lock()
+	defer c.backupHistory.mu.Unlock()
+
+	existing := make(map[string]struct{}, len(guests))
+	for _, guest := range guests {
+		if guest.VMID != nil {
+			existing[string(*guest.VMID)] = struct{}{}
+		}
+	}
+	for vmid := range c.backupHistory.guests {
+		if _, ok := existing[vmid]; !ok {
+			delete(c.backupHistory.guests, vmid)
+		}
+	}
+
+	for _, guest := range guests {
+		if guest.Template != nil && *guest.Template == 1 {
+			continue
+		}
+		name, vmid, tags := guestLabels(guest)
+
+		failures := 0
+		if result, ok := c.backupHistory.guests[string(vmid)]; ok {
+			if result.lastSuccess > 0 {
+				ch <- prometheus.MustNewConstMetric(c.guestLastBackupSuccess, prometheus.GaugeValue, float64(result.lastSuccess), guest.Node, guest.Type, name, string(vmid), tags)
+				ch <- prometheus.MustNewConstMetric(c.guestLastBackupDuration, prometheus.GaugeValue, float64(result.lastDuration), guest.Node, guest.Type, name, string(vmid), tags)
+			}
+			failures = result.failures
+		}
+		ch <- prometheus.MustNewConstMetric(c.guestBackupFailures, prometheus.CounterValue, float64(failures), guest.Node, guest.Type, name, string(vmid), tags)
+	}
+}
@@ -0,0 +1,311 @@
+package prometheus
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+)
+
+func TestParseVzdumpTaskLog(t *testing.T) {
+	lines := []string{
+		"INFO: starting new backup job: vzdump 100 101 102 --mode snapshot",
+		"INFO: Starting Backup of VM 100 (qemu)",
+		"INFO: Backup started at 2024-01-01 01:00:00",
+		"INFO: Finished Backup of VM 100 (00:01:23)",
+		"INFO: Starting Backup of VM 101 (lxc)",
+		"ERROR: Backup of VM 101 failed - command 'tar' failed: exit code 2",
+		"INFO: Starting Backup of VM 102 (qemu)",
+		"INFO: Finished Backup of VM 102 (12:00:05)",
+		"INFO: Backup job finished with errors",
+	}
+
+	results := parseVzdumpTaskLog(lines)
+	expected := []vzdumpGuestResult{
+		{vmid: "100", success: true, duration: 83},
+		{vmid: "101", success: false},
+		{vmid: "102", success: true, duration: 43205},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+	for i, want := range expected {
+		if results[i] != want {
+			t.Errorf("result %d: expected %+v, got %+v", i, want, results[i])
+		}
+	}
+}
+
+func TestVzdumpStatusOK(t *testing.T) {
+	tests := []struct {
+		status   string
+		expected bool
+	}{
+		{"OK", true},
+		{"WARNINGS: 2", true},
+		{"job errors", false},
+		{"unexpected status", false},
+		{"interrupted by signal", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := vzdumpStatusOK(tt.status); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBackupHistoryRecord(t *testing.T) {
+	h := newBackupHistory()
+
+	// Task history is read newest first, so the older success must not replace the newer one
+	h.record(vzdumpGuestResult{vmid: "100", success: true, duration: 60}, 2000)
+	h.record(vzdumpGuestResult{vmid: "100", success: true, duration: 30}, 1000)
+	h.record(vzdumpGuestResult{vmid: "100", success: false}, 1500)
+	h.record(vzdumpGuestResult{vmid: "101", success: false}, 1500)
+
+	vm := h.guests["100"]
+	if vm.lastSuccess != 2000 || vm.lastDuration != 60 {
+		t.Errorf("expected last success at 2000 taking 60s, got %d taking %ds", vm.lastSuccess, vm.lastDuration)
+	}
+	if vm.failures != 1 {
+		t.Errorf("expected 1 failure, got %d", vm.failures)
+	}
+
+	failed := h.guests["101"]
+	if failed.lastSuccess != 0 || failed.failures != 1 {
+		t.Errorf("expected no success and 1 failure, got %d and %d", failed.lastSuccess, failed.failures)
+	}
+}
+
+func TestCollectBackupHistoryMetrics(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+	vmidPtr := func(v string) *proxmox.IntOrString {
+		ios := proxmox.IntOrString(v)
+		return &ios
+	}
+
+	guests := []proxmox.GetClusterResourcesData{
+		{Node: "node1", Type: "qemu", Name: strPtr("vm1"), VMID: vmidPtr("100")},
+		{Node: "node1", Type: "lxc", Name: strPtr("ct1"), VMID: vmidPtr("200")},
+		{Node: "node2", Type: "qemu", Name: strPtr("never-backed-up"), VMID: vmidPtr("101")},
+		{Node: "node2", Type: "qemu", Name: strPtr("template"), VMID: vmidPtr("900"), Template: intPtr(1)},
+	}
+
+	c := testCollector()
+	c.backupHistory.record(vzdumpGuestResult{vmid: "100", success: true, duration: 245}, 1704067200)
+	c.backupHistory.record(vzdumpGuestResult{vmid: "200", success: false}, 1704067200)
+	c.backupHistory.record(vzdumpGuestResult{vmid: "200", success: false}, 1704153600)
+	c.backupHistory.record(vzdumpGuestResult{vmid: "900", success: true, duration: 10}, 1704067200)
+	c.backupHistory.record(vzdumpGuestResult{vmid: "300", success: false}, 1704067200)
+
+	ch := make(chan prometheus.Metric, 100)
+	c.collectBackupHistoryMetrics(ch, guests)
+	metrics := drainMetrics(ch)
+
+	// Guest 300 is no longer in the cluster, so its results are forgotten, while the template's are kept
+	if _, ok := c.backupHistory.guests["300"]; ok {
+		t.Error("expected the results of guest 300 to be removed")
+	}
+	if _, ok := c.backupHistory.guests["900"]; !ok {
+		t.Error("expected the results of template 900 to be kept")
+	}
+
+	success := findByDesc(metrics, c.guestLastBackupSuccess)
+	if len(success) != 1 {
+		t.Fatalf("guestLastBackupSuccess: expected 1, got %d", len(success))
+	}
+	if v := getMetricValue(success[0]); v != 1704067200 {
+		t.Errorf("expected last success timestamp 1704067200, got %f", v)
+	}
+	if labels := getMetricLabels(success[0]); labels["vmid"] != "100" || labels["name"] != "vm1" {
+		t.Errorf("unexpected labels: %v", labels)
+	}
+
+	duration := findByDesc(metrics, c.guestLastBackupDuration)
+	if len(duration) != 1 {
+		t.Fatalf("guestLastBackupDuration: expected 1, got %d", len(duration))
+	}
+	if v := getMetricValue(duration[0]); v != 245 {
+		t.Errorf("expected last duration 245, got %f", v)
+	}
+
+	// Every guest except the template gets a failure counter, even without any backup history
+	failures := findByDesc(metrics, c.guestBackupFailures)
+	if len(failures) != 3 {
+		t.Fatalf("guestBackupFailures: expected 3, got %d", len(failures))
+	}
+	expectedFailures := map[string]float64{"100": 0, "200": 2, "101": 0}
+	for _, m := range failures {
+		vmid := getMetricLabels(m)["vmid"]
+		if v := getCounterValue(m); v != expectedFailures[vmid] {
+			t.Errorf("guest %s: expected %f failures, got %f", vmid, expectedFailures[vmid], v)
+		}
+	}
+}
+
+func TestBackupHistoryUpdate_Incremental(t *testing.T) {
+	// The first page of tasks has a running backup job, the second only has the job after it finished
+	var mu sync.Mutex
+	var sinceParams []string
+	tasks := []string{
+		`{"data": [
+			{"upid": "UPID:node1:3:3:3:vzdump::root@pam:", "type": "vzdump", "id": "", "starttime": 3000},
+			{"upid": "UPID:node1:2:2:2:vzdump:101:root@pam:", "type": "vzdump", "id": "101", "starttime": 2000, "endtime": 2100, "status": "ERROR: interrupted"},
+			{"upid": "UPID:node1:1:1:1:vzdump:100:root@pam:", "type": "vzdump", "id": "100", "starttime": 1000, "endtime": 1060, "status": "OK"}
+		]}`,
+		`{"data": [
+			{"upid": "UPID:node1:3:3:3:vzdump::root@pam:", "type": "vzdump", "id": "", "starttime": 3000, "endtime": 3300, "status": "OK"}
+		]}`,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/cluster/status", intJSONHandler(`{"data": []}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/tasks", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(tasks[len(sinceParams)]))
+		sinceParams = append(sinceParams, r.URL.Query().Get("since"))
+	})
+	mux.HandleFunc("/api2/json/nodes/{node}/tasks/{upid}/log", intJSONHandler(`{"data": [
+		{"n": 1, "t": "INFO: Finished Backup of VM 100 (00:05:00)"},
+		{"n": 2, "t": "INFO: Finished Backup of VM 101 (00:00:30)"}
+	]}`))
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	http.DefaultTransport = &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	defer func() {
+		http.DefaultTransport = &http.Transport{}
+	}()
+
+	initProxmoxForIntegration(t, server.URL)
+
+	h := newBackupHistory()
+	if err := h.update("node1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The running job holds the cursor back at its start time
+	if got := h.nodes["node1"].since; got != 3000 {
+		t.Errorf("expected cursor at 3000 after first update, got %d", got)
+	}
+	if h.guests["100"].lastSuccess != 1060 || h.guests["101"].failures != 1 {
+		t.Errorf("unexpected results after first update: %+v %+v", *h.guests["100"], *h.guests["101"])
+	}
+
+	if err := h.update("node1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(sinceParams) != 2 || sinceParams[0] != "" || sinceParams[1] != "3000" {
+		t.Errorf("expected requests since '' then '3000', got %v", sinceParams)
+	}
+	if vm := h.guests["100"]; vm.lastSuccess != 3300 || vm.lastDuration != 300 {
+		t.Errorf("expected VM 100 last success at 3300 taking 300s, got %d taking %ds", vm.lastSuccess, vm.lastDuration)
+	}
+	if vm := h.guests["101"]; vm.lastSuccess != 3300 || vm.failures != 1 {
+		t.Errorf("expected VM 101 last success at 3300 with 1 failure, got %d with %d", vm.lastSuccess, vm.failures)
+	}
+
+	// Reading the same finished task again must not count it twice
+	if len(h.nodes["node1"].seen) != 1 {
+		t.Errorf("expected 1 seen task at the cursor, got %d", len(h.nodes["node1"].seen))
+	}
+}
+
+func TestBackupHistoryUpdate_GivesUpOnUnreadableTask(t *testing.T) {
+	// The backup job's log can't be read, while a newer single guest backup finished after it
+	var logRequests atomic.Int32
+	var sinceParams []string
+	var mu sync.Mutex
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/cluster/status", intJSONHandler(`{"data": []}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/tasks", func(w http.ResponseWriter, r *http.Request) {
+		since := r.URL.Query().Get("since")
+		mu.Lock()
+		sinceParams = append(sinceParams, since)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		// PVE only lists the tasks that started at or after since
+		if since == "2000" {
+			_, _ = w.Write([]byte(`{"data": [
+				{"upid": "UPID:node1:2:2:2:vzdump:100:root@pam:", "type": "vzdump", "id": "100", "starttime": 2000, "endtime": 2060, "status": "OK"}
+			]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": [
+			{"upid": "UPID:node1:2:2:2:vzdump:100:root@pam:", "type": "vzdump", "id": "100", "starttime": 2000, "endtime": 2060, "status": "OK"},
+			{"upid": "UPID:node1:1:1:1:vzdump::root@pam:", "type": "vzdump", "id": "", "starttime": 1000, "endtime": 1300, "status": "OK"}
+		]}`))
+	})
+	mux.HandleFunc("/api2/json/nodes/{node}/tasks/{upid}/log", func(w http.ResponseWriter, r *http.Request) {
+		logRequests.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+	})
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	http.DefaultTransport = &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	defer func() {
+		http.DefaultTransport = &http.Transport{}
+	}()
+
+	initProxmoxForIntegration(t, server.URL)
+
+	h := newBackupHistory()
+	for i := 1; i < vzdumpTaskMaxAttempts; i++ {
+		if err := h.update("node1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// The unread job holds the cursor back at its start time, so it's tried again
+		if got := h.nodes["node1"].since; got != 1000 {
+			t.Errorf("update %d: expected cursor at 1000, got %d", i, got)
+		}
+		if got := h.unreadableTasks("node1"); got != 0 {
+			t.Errorf("update %d: expected 0 unreadable tasks, got %d", i, got)
+		}
+	}
+
+	// The last attempt gives up on the job and moves the cursor past it
+	if err := h.update("node1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := h.nodes["node1"].since; got != 2000 {
+		t.Errorf("expected cursor at 2000 after giving up, got %d", got)
+	}
+	if got := h.unreadableTasks("node1"); got != 1 {
+		t.Errorf("expected 1 unreadable task, got %d", got)
+	}
+	if len(h.nodes["node1"].attempts) != 0 {
+		t.Errorf("expected no tasks left to retry, got %v", h.nodes["node1"].attempts)
+	}
+
+	// The job is no longer requested, and the single guest backup isn't counted again
+	if err := h.update("node1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := logRequests.Load(); got != vzdumpTaskMaxAttempts {
+		t.Errorf("expected %d log requests, got %d", vzdumpTaskMaxAttempts, got)
+	}
+	if got := sinceParams[len(sinceParams)-1]; got != "2000" {
+		t.Errorf("expected the last request since 2000, got %s", got)
+	}
+	if vm := h.guests["100"]; vm.lastSuccess != 2060 || vm.failures != 0 {
+		t.Errorf("unexpected VM 100 result: %+v", *vm)
+	}
+}
@@ -0,0 +1,82 @@
+package prometheus
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/starttoaster/proxmox-exporter/internal/calendarevent"
+	"github.com/starttoaster/proxmox-exporter/internal/logger"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// collectBackupJobMetrics exports the configuration of each vzdump backup job, and the next time each enabled job runs
+func (c *Collector) collectBackupJobMetrics(ch chan<- prometheus.Metric, jobs *wrappedProxmox.GetClusterBackupResponse) {
+	now := time.Now().In(scheduleLocation())
+	for _, job := range jobs.Data {
+		schedule := backupJobSchedule(job)
+		storage := ""
+		if job.Storage != nil {
+			storage = *job.Storage
+		}
+		mode := ""
+		if job.Mode != nil {
+			mode = *job.Mode
+		}
+		node := ""
+		if job.Node != nil {
+			node = *job.Node
+		}
+		ch <- prometheus.MustNewConstMetric(c.backupJobInfo, prometheus.GaugeValue, 1, job.ID, schedule, storage, mode, backupJobSelection(job), node)
+
+		// Jobs are enabled unless they're explicitly disabled
+		enabled := job.Enabled == nil || *job.Enabled == 1
+		enabledValue := 0.0
+		if enabled {
+			enabledValue = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.backupJobEnabled, prometheus.GaugeValue, enabledValue, job.ID)
+
+		if !enabled {
+			continue
+		}
+		event, err := calendarevent.Parse(schedule)
+		if err != nil {
+			logger.Logger.Warn("failed parsing backup job schedule", "id", job.ID, "schedule", schedule, "error", err.Error())
+			continue
+		}
+		if next, ok := event.Next(now); ok {
+			ch <- prometheus.MustNewConstMetric(c.backupJobNextRun, prometheus.GaugeValue, float64(next.Unix()), job.ID)
+		}
+	}
+}
+
+// backupJobSchedule returns the calendar event of a backup job. Jobs created before PVE 7 have a weekday list and start time instead
+func backupJobSchedule(job wrappedProxmox.GetClusterBackupData) string {
+	if job.Schedule != nil {
+		return *job.Schedule
+	}
+
+	var parts []string
+	if job.Dow != nil && *job.Dow != "" {
+		parts = append(parts, *job.Dow)
+	}
+	if job.StartTime != nil && *job.StartTime != "" {
+		parts = append(parts, *job.StartTime)
+	}
+	return strings.Join(parts, " ")
+}
+
+// backupJobSelection returns how a backup job selects its guests, one of all, exclude, pool or include
+func backupJobSelection(job wrappedProxmox.GetClusterBackupData) string {
+	switch {
+	case job.All != nil && *job.All == 1 && job.Exclude != nil && *job.Exclude != "":
+		return "exclude"
+	case job.All != nil && *job.All == 1:
+		return "all"
+	case job.Pool != nil && *job.Pool != "":
+		return "pool"
+	default:
+		return "include"
+	}
+}
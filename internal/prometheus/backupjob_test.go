@@ -0,0 +1,114 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestCollectBackupJobMetrics(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	oldCfg := cfg
+	cfg = Config{ScheduleLocation: loc}
+	defer func() { cfg = oldCfg }()
+
+	jobs := &wrappedProxmox.GetClusterBackupResponse{
+		Data: []wrappedProxmox.GetClusterBackupData{
+			{ID: "weekly", Enabled: intPtr(1), Schedule: strPtr("sat 02:00"), Storage: strPtr("pbs"), Mode: strPtr("snapshot"), All: intPtr(1)},
+			{ID: "disabled", Enabled: intPtr(0), Schedule: strPtr("daily"), Storage: strPtr("local"), Mode: strPtr("stop"), VMID: strPtr("100")},
+			{ID: "legacy", Dow: strPtr("mon,wed"), StartTime: strPtr("21:00"), Storage: strPtr("local"), Node: strPtr("node1"), Pool: strPtr("prod")},
+			{ID: "invalid", Schedule: strPtr("funday 25:00")},
+		},
+	}
+
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 100)
+	before := time.Now()
+	c.collectBackupJobMetrics(ch, jobs)
+	metrics := drainMetrics(ch)
+
+	infos := findByDesc(metrics, c.backupJobInfo)
+	if len(infos) != 4 {
+		t.Fatalf("backupJobInfo: expected 4, got %d", len(infos))
+	}
+	expectedInfo := map[string]map[string]string{
+		"weekly":   {"schedule": "sat 02:00", "storage": "pbs", "mode": "snapshot", "selection": "all", "node": ""},
+		"disabled": {"schedule": "daily", "storage": "local", "mode": "stop", "selection": "include", "node": ""},
+		"legacy":   {"schedule": "mon,wed 21:00", "storage": "local", "mode": "", "selection": "pool", "node": "node1"},
+		"invalid":  {"schedule": "funday 25:00", "storage": "", "mode": "", "selection": "include", "node": ""},
+	}
+	for _, m := range infos {
+		labels := getMetricLabels(m)
+		for k, v := range expectedInfo[labels["id"]] {
+			if labels[k] != v {
+				t.Errorf("job %s: expected %s=%q, got %q", labels["id"], k, v, labels[k])
+			}
+		}
+	}
+
+	expectedEnabled := map[string]float64{"weekly": 1, "disabled": 0, "legacy": 1, "invalid": 1}
+	enabled := findByDesc(metrics, c.backupJobEnabled)
+	if len(enabled) != 4 {
+		t.Fatalf("backupJobEnabled: expected 4, got %d", len(enabled))
+	}
+	for _, m := range enabled {
+		id := getMetricLabels(m)["id"]
+		if v := getMetricValue(m); v != expectedEnabled[id] {
+			t.Errorf("job %s: expected enabled %f, got %f", id, expectedEnabled[id], v)
+		}
+	}
+
+	// Disabled jobs and jobs with an invalid schedule don't have a next run
+	nextRuns := findByDesc(metrics, c.backupJobNextRun)
+	if len(nextRuns) != 2 {
+		t.Fatalf("backupJobNextRun: expected 2, got %d", len(nextRuns))
+	}
+	for _, m := range nextRuns {
+		id := getMetricLabels(m)["id"]
+		next := time.Unix(int64(getMetricValue(m)), 0).In(loc)
+		if !next.After(before) || next.After(before.Add(7*24*time.Hour)) {
+			t.Errorf("job %s: expected next run within a week, got %s", id, next)
+		}
+		switch id {
+		case "weekly":
+			if next.Weekday() != time.Saturday || next.Hour() != 2 || next.Minute() != 0 {
+				t.Errorf("job weekly: expected next run on a saturday at 02:00, got %s", next)
+			}
+		case "legacy":
+			if (next.Weekday() != time.Monday && next.Weekday() != time.Wednesday) || next.Hour() != 21 {
+				t.Errorf("job legacy: expected next run on a monday or wednesday at 21:00, got %s", next)
+			}
+		default:
+			t.Errorf("unexpected next run for job %s", id)
+		}
+	}
+}
+
+func TestBackupJobSelection(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+
+	tests := []struct {
+		name     string
+		job      wrappedProxmox.GetClusterBackupData
+		expected string
+	}{
+		{"all", wrappedProxmox.GetClusterBackupData{All: intPtr(1)}, "all"},
+		{"all with exclusions", wrappedProxmox.GetClusterBackupData{All: intPtr(1), Exclude: strPtr("100,101")}, "exclude"},
+		{"pool", wrappedProxmox.GetClusterBackupData{All: intPtr(0), Pool: strPtr("prod")}, "pool"},
+		{"included guests", wrappedProxmox.GetClusterBackupData{VMID: strPtr("100,101")}, "include"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := backupJobSelection(tt.job); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
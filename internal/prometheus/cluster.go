@@ -0,0 +1,55 @@
+package prometheus
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+)
+
+// collectClusterStatusMetrics processes quorum and membership data from the cluster status endpoint.
+// Standalone PVE hosts have no cluster entry in their status, so nothing is exported for them.
+func (c *Collector) collectClusterStatusMetrics(ch chan<- prometheus.Metric, status *proxmox.GetClusterStatusResponse) {
+	var cluster *proxmox.GetClusterStatusData
+	var nodes []proxmox.GetClusterStatusData
+	for i, entry := range status.Data {
+		switch {
+		case strings.EqualFold(entry.Type, "cluster"):
+			cluster = &status.Data[i]
+		case strings.EqualFold(entry.Type, "node"):
+			nodes = append(nodes, entry)
+		}
+	}
+	if cluster == nil {
+		return
+	}
+
+	quorate := 0.0
+	if cluster.Quorate != nil && *cluster.Quorate == 1 {
+		quorate = 1.0
+	}
+	ch <- prometheus.MustNewConstMetric(c.clusterQuorate, prometheus.GaugeValue, quorate)
+
+	if cluster.Nodes != nil {
+		ch <- prometheus.MustNewConstMetric(c.clusterNodesExpected, prometheus.GaugeValue, float64(*cluster.Nodes))
+	}
+
+	online := 0
+	for _, node := range nodes {
+		if node.Online != nil && *node.Online == 1 {
+			online++
+		}
+
+		nodeID := ""
+		if node.NodeID != nil {
+			nodeID = strconv.Itoa(*node.NodeID)
+		}
+		ip := ""
+		if node.IP != nil {
+			ip = *node.IP
+		}
+		ch <- prometheus.MustNewConstMetric(c.clusterNodeInfo, prometheus.GaugeValue, 1, node.Name, nodeID, ip)
+	}
+	ch <- prometheus.MustNewConstMetric(c.clusterNodesOnline, prometheus.GaugeValue, float64(online))
+}
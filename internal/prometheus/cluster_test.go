@@ -0,0 +1,125 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+)
+
+func TestCollectClusterStatusMetrics(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+
+	tests := []struct {
+		name             string
+		status           *proxmox.GetClusterStatusResponse
+		expectedCount    int
+		expectedQuorate  float64
+		expectedExpected float64
+		expectedOnline   float64
+	}{
+		{
+			name: "quorate cluster with all nodes online",
+			status: &proxmox.GetClusterStatusResponse{
+				Data: []proxmox.GetClusterStatusData{
+					{Type: "cluster", Name: "prd", Quorate: intPtr(1), Nodes: intPtr(2)},
+					{Type: "node", Name: "node1", Online: intPtr(1), NodeID: intPtr(1), IP: strPtr("10.0.0.1")},
+					{Type: "node", Name: "node2", Online: intPtr(1), NodeID: intPtr(2), IP: strPtr("10.0.0.2")},
+				},
+			},
+			expectedCount:    5,
+			expectedQuorate:  1,
+			expectedExpected: 2,
+			expectedOnline:   2,
+		},
+		{
+			name: "cluster without quorum",
+			status: &proxmox.GetClusterStatusResponse{
+				Data: []proxmox.GetClusterStatusData{
+					{Type: "cluster", Name: "prd", Quorate: intPtr(0), Nodes: intPtr(3)},
+					{Type: "node", Name: "node1", Online: intPtr(1), NodeID: intPtr(1), IP: strPtr("10.0.0.1")},
+					{Type: "node", Name: "node2", Online: intPtr(0), NodeID: intPtr(2), IP: strPtr("10.0.0.2")},
+					{Type: "node", Name: "node3", Online: intPtr(0), NodeID: intPtr(3), IP: strPtr("10.0.0.3")},
+				},
+			},
+			expectedCount:    6,
+			expectedQuorate:  0,
+			expectedExpected: 3,
+			expectedOnline:   1,
+		},
+		{
+			name: "standalone host",
+			status: &proxmox.GetClusterStatusResponse{
+				Data: []proxmox.GetClusterStatusData{
+					{Type: "node", Name: "pve", Online: intPtr(1)},
+				},
+			},
+			expectedCount: 0,
+		},
+		{
+			name:          "empty response",
+			status:        &proxmox.GetClusterStatusResponse{},
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCollector()
+			ch := make(chan prometheus.Metric, 100)
+
+			c.collectClusterStatusMetrics(ch, tt.status)
+
+			metrics := drainMetrics(ch)
+			if len(metrics) != tt.expectedCount {
+				t.Fatalf("expected %d metrics, got %d", tt.expectedCount, len(metrics))
+			}
+			if tt.expectedCount == 0 {
+				return
+			}
+
+			if m := findByDesc(metrics, c.clusterQuorate); len(m) != 1 || getMetricValue(m[0]) != tt.expectedQuorate {
+				t.Errorf("expected quorate=%f", tt.expectedQuorate)
+			}
+			if m := findByDesc(metrics, c.clusterNodesExpected); len(m) != 1 || getMetricValue(m[0]) != tt.expectedExpected {
+				t.Errorf("expected nodes_expected=%f", tt.expectedExpected)
+			}
+			if m := findByDesc(metrics, c.clusterNodesOnline); len(m) != 1 || getMetricValue(m[0]) != tt.expectedOnline {
+				t.Errorf("expected nodes_online=%f", tt.expectedOnline)
+			}
+		})
+	}
+}
+
+func TestCollectClusterStatusMetrics_NodeInfoLabels(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 10)
+
+	status := &proxmox.GetClusterStatusResponse{
+		Data: []proxmox.GetClusterStatusData{
+			{Type: "cluster", Name: "prd", Quorate: intPtr(1), Nodes: intPtr(1)},
+			{Type: "node", Name: "node1", Online: intPtr(1), NodeID: intPtr(4), IP: strPtr("192.168.1.10")},
+		},
+	}
+
+	c.collectClusterStatusMetrics(ch, status)
+
+	infos := findByDesc(drainMetrics(ch), c.clusterNodeInfo)
+	if len(infos) != 1 {
+		t.Fatalf("expected 1 node info metric, got %d", len(infos))
+	}
+	labels := getMetricLabels(infos[0])
+	if labels["node"] != "node1" {
+		t.Errorf("expected node=node1, got %s", labels["node"])
+	}
+	if labels["nodeid"] != "4" {
+		t.Errorf("expected nodeid=4, got %s", labels["nodeid"])
+	}
+	if labels["ip"] != "192.168.1.10" {
+		t.Errorf("expected ip=192.168.1.10, got %s", labels["ip"])
+	}
+}
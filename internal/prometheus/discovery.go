@@ -0,0 +1,196 @@
+package prometheus
+
+import (
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/starttoaster/proxmox-exporter/internal/logger"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// discoveryWorkers is the maximum number of guests whose addresses are requested at once for a service discovery request
+const discoveryWorkers = 4
+
+// defaultSDDeadline is the maximum time a service discovery request waits for the addresses of guests, if it isn't configured
+const defaultSDDeadline = 10 * time.Second
+
+// sdLookupsInFlight contains the VMIDs of guests whose addresses are still being requested for an earlier service discovery request
+var sdLookupsInFlight sync.Map
+
+// sdGuestAgentWarning makes sure the warning that VMs aren't discovered is only logged once
+var sdGuestAgentWarning sync.Once
+
+// DiscoveredGuest is a running guest and the addresses of its network interfaces, for service discovery.
+// PrimaryInterface is the name of the interface of the guest's first network device (net0), if it was found
+type DiscoveredGuest struct {
+	Node             string
+	Type             string
+	Name             string
+	VMID             string
+	Tags             string
+	Pool             string
+	IPs              []GuestIP
+	PrimaryInterface string
+}
+
+// DiscoverGuests returns the running guests that have any of the given tags and are members of the given pool, in cluster resources order.
+// Guests aren't filtered by tags or pool when they're empty. The addresses of VMs are read from their QEMU guest agent,
+// so VMs only have addresses when guest agent lookups are enabled for service discovery. Guests whose addresses weren't read
+// by the service discovery deadline are returned without them
+func DiscoverGuests(tags []string, pool string) ([]DiscoveredGuest, error) {
+	if !cfg.EnableSDGuestAgent {
+		sdGuestAgentWarning.Do(func() {
+			logger.Logger.Warn("guest agent lookups are disabled for service discovery, so only LXCs are discovered. Enable them with --enable-sd-guest-agent to discover VMs")
+		})
+	}
+
+	clusterResources, err := wrappedProxmox.GetClusterResources()
+	if err != nil {
+		return nil, err
+	}
+
+	var guests []DiscoveredGuest
+	for _, r := range clusterResources.Data {
+		if r.Type != "qemu" && r.Type != "lxc" {
+			continue
+		}
+		if !strings.EqualFold(r.Status, "running") || (r.Template != nil && *r.Template == 1) {
+			continue
+		}
+		name, vmid, guestTags := guestLabels(r)
+		if len(tags) > 0 && !hasAnyTag(guestTags, tags) {
+			continue
+		}
+		guestPool := ""
+		if r.Pool != nil {
+			guestPool = *r.Pool
+		}
+		if pool != "" && guestPool != pool {
+			continue
+		}
+
+		guests = append(guests, DiscoveredGuest{
+			Node: r.Node,
+			Type: r.Type,
+			Name: name,
+			VMID: string(vmid),
+			Tags: guestTags,
+			Pool: guestPool,
+		})
+	}
+
+	deadline := cfg.SDDeadline
+	if deadline <= 0 {
+		deadline = defaultSDDeadline
+	}
+	timeout := time.NewTimer(deadline)
+	defer timeout.Stop()
+
+	// Guests whose addresses are still being requested for an earlier request are returned without them, rather than requested again
+	var lookups []int
+	for i, guest := range guests {
+		if _, inFlight := sdLookupsInFlight.LoadOrStore(guest.VMID, true); inFlight {
+			logger.Logger.Debug("guest addresses are still being requested for an earlier service discovery request", "node", guest.Node, "vm_id", guest.VMID)
+			continue
+		}
+		lookups = append(lookups, i)
+	}
+
+	// Guest agents may be slow to respond, so the addresses of guests are requested by a fixed number of workers.
+	// Workers read their own copy of the guests and send what they found, since guests is returned once the deadline passed
+	type discoveredIPs struct {
+		index   int
+		ips     []GuestIP
+		primary string
+	}
+	results := make(chan discoveredIPs, len(lookups))
+	targets := slices.Clone(guests)
+	go forEachConcurrently(len(lookups), discoveryWorkers, func(j int) {
+		i := lookups[j]
+		defer sdLookupsInFlight.Delete(targets[i].VMID)
+		ips, primary := discoverGuestIPs(targets[i])
+		results <- discoveredIPs{index: i, ips: ips, primary: primary}
+	})
+
+	for pending := len(lookups); pending > 0; pending-- {
+		select {
+		case result := <-results:
+			guests[result.index].IPs, guests[result.index].PrimaryInterface = result.ips, result.primary
+		case <-timeout.C:
+			logger.Logger.Warn("stopped waiting for guest addresses after the service discovery deadline", "deadline", deadline, "remaining_guests", pending)
+			return guests, nil
+		}
+	}
+
+	return guests, nil
+}
+
+// discoverGuestIPs requests the addresses of a guest's network interfaces, and the name of the interface of its first network device
+func discoverGuestIPs(guest DiscoveredGuest) ([]GuestIP, string) {
+	vmID, err := strconv.Atoi(guest.VMID)
+	if err != nil {
+		return nil, ""
+	}
+
+	if guest.Type == "lxc" {
+		interfaces, err := wrappedProxmox.GetLxcInterfaces(guest.Node, vmID)
+		if err != nil {
+			logger.Logger.Error("failed making request to get lxc interfaces", "node", guest.Node, "vm_id", guest.VMID, "error", err.Error())
+			return nil, ""
+		}
+		primary := ""
+		config, err := wrappedProxmox.GetLxcConfig(guest.Node, vmID)
+		if err != nil {
+			logger.Logger.Error("failed making request to get LXC config", "node", guest.Node, "vm_id", guest.VMID, "error", err.Error())
+		} else if config.Data.Net0 != nil {
+			mac := netDeviceMAC(*config.Data.Net0)
+			for _, iface := range interfaces.Data {
+				if iface.HWAddr != nil && mac != "" && strings.EqualFold(*iface.HWAddr, mac) {
+					primary = iface.Name
+					break
+				}
+			}
+		}
+		return lxcGuestIPs(interfaces), primary
+	}
+
+	if !cfg.EnableSDGuestAgent {
+		return nil, ""
+	}
+	config, err := wrappedProxmox.GetQemuConfig(guest.Node, vmID)
+	if err != nil || config.Data.Agent == nil || !qemuAgentEnabled(string(*config.Data.Agent)) {
+		return nil, ""
+	}
+	interfaces, err := wrappedProxmox.GetQemuAgentNetworkInterfaces(guest.Node, vmID)
+	if err != nil {
+		logger.Logger.Debug("failed making request to get guest agent network interfaces", "node", guest.Node, "vm_id", guest.VMID, "error", err.Error())
+		return nil, ""
+	}
+	primary := ""
+	if config.Data.Net0 != nil {
+		mac := netDeviceMAC(*config.Data.Net0)
+		for _, iface := range interfaces.Data.Result {
+			if iface.HardwareAddress != nil && mac != "" && strings.EqualFold(*iface.HardwareAddress, mac) {
+				primary = iface.Name
+				break
+			}
+		}
+	}
+	return qemuGuestIPs(interfaces), primary
+}
+
+// netDeviceMAC returns the MAC address of a guest's network device option, like virtio=BC:24:11:2E:4F:1A,bridge=vmbr0 for VMs
+// or name=eth0,bridge=vmbr0,hwaddr=BC:24:11:2E:4F:1A for LXCs. It's empty if the option doesn't have a MAC address
+func netDeviceMAC(option string) string {
+	for _, field := range strings.Split(option, ",") {
+		_, value, _ := strings.Cut(field, "=")
+		if mac, err := net.ParseMAC(value); err == nil && len(mac) == 6 {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,25 @@
+package prometheus
+
+import "testing"
+
+func TestNetDeviceMAC(t *testing.T) {
+	tests := []struct {
+		name     string
+		option   string
+		expected string
+	}{
+		{"qemu", "virtio=BC:24:11:2E:4F:1A,bridge=vmbr0,firewall=1", "BC:24:11:2E:4F:1A"},
+		{"qemu other model", "e1000=bc:24:11:2e:4f:1a,bridge=vmbr0,tag=20", "bc:24:11:2e:4f:1a"},
+		{"lxc", "name=eth0,bridge=vmbr0,hwaddr=BC:24:11:2E:4F:1A,ip=dhcp,type=veth", "BC:24:11:2E:4F:1A"},
+		{"no mac", "name=eth0,bridge=vmbr0,ip=dhcp", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := netDeviceMAC(tt.option); got != tt.expected {
+				t.Errorf("netDeviceMAC(%q) = %q, want %q", tt.option, got, tt.expected)
+			}
+		})
+	}
+}
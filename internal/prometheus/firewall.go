@@ -0,0 +1,108 @@
+package prometheus
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	"github.com/starttoaster/proxmox-exporter/internal/logger"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// firewallPolicies are the default policies of a firewall for traffic that isn't matched by a rule
+var firewallPolicies = []string{"ACCEPT", "REJECT", "DROP"}
+
+// The firewall policies PVE applies to the cluster and guests when they weren't configured
+const (
+	defaultFirewallPolicyIn  = "DROP"
+	defaultFirewallPolicyOut = "ACCEPT"
+)
+
+// collectClusterFirewallMetrics exports whether the cluster wide firewall is enabled, its default policies and its number of rules
+func (c *Collector) collectClusterFirewallMetrics(ch chan<- prometheus.Metric, options *wrappedProxmox.GetClusterFirewallOptionsResponse, rules *wrappedProxmox.GetFirewallRulesResponse) {
+	if options != nil {
+		// The cluster firewall is disabled unless it was enabled
+		enabled := 0.0
+		if options.Data.Enable != nil && *options.Data.Enable == 1 {
+			enabled = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.clusterFirewallEnabled, prometheus.GaugeValue, enabled)
+		collectStateSet(ch, c.clusterFirewallPolicy, firewallPolicies, firewallPolicy(options.Data.PolicyIn, defaultFirewallPolicyIn), "in")
+		collectStateSet(ch, c.clusterFirewallPolicy, firewallPolicies, firewallPolicy(options.Data.PolicyOut, defaultFirewallPolicyOut), "out")
+	}
+	if rules != nil {
+		enabledRules, disabledRules := countFirewallRules(rules)
+		ch <- prometheus.MustNewConstMetric(c.clusterFirewallRules, prometheus.GaugeValue, float64(enabledRules), "true")
+		ch <- prometheus.MustNewConstMetric(c.clusterFirewallRules, prometheus.GaugeValue, float64(disabledRules), "false")
+	}
+}
+
+// collectNodeFirewallMetrics exports whether a node's firewall is enabled and its number of rules
+func (c *Collector) collectNodeFirewallMetrics(ch chan<- prometheus.Metric, nodeName string, options *wrappedProxmox.GetNodeFirewallOptionsResponse, rules *wrappedProxmox.GetFirewallRulesResponse) {
+	if options != nil {
+		// Unlike the cluster and guest firewalls, a node's firewall is enabled unless it was disabled
+		enabled := 1.0
+		if options.Data.Enable != nil && *options.Data.Enable == 0 {
+			enabled = 0.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.nodeFirewallEnabled, prometheus.GaugeValue, enabled, nodeName)
+	}
+	if rules != nil {
+		enabledRules, disabledRules := countFirewallRules(rules)
+		ch <- prometheus.MustNewConstMetric(c.nodeFirewallRules, prometheus.GaugeValue, float64(enabledRules), nodeName, "true")
+		ch <- prometheus.MustNewConstMetric(c.nodeFirewallRules, prometheus.GaugeValue, float64(disabledRules), nodeName, "false")
+	}
+}
+
+// collectGuestFirewallMetrics requests the firewall options and rules of a guest, and exports whether its firewall is enabled, its default policies and its number of rules
+func (c *Collector) collectGuestFirewallMetrics(ch chan<- prometheus.Metric, nodeName string, guest proxmox.GetClusterResourcesData) {
+	name, vmid, tags := guestLabels(guest)
+	vmID, err := strconv.Atoi(string(vmid))
+	if err != nil {
+		logger.Logger.Error("failed converting guest ID for firewall options", "node", nodeName, "vm_id", vmid, "error", err.Error())
+		return
+	}
+
+	options, err := wrappedProxmox.GetGuestFirewallOptions(nodeName, guest.Type, vmID)
+	if err != nil {
+		logger.Logger.Error("failed making request to get guest firewall options", "node", nodeName, "vm_id", vmid, "error", err.Error())
+	} else {
+		// A guest's firewall is disabled unless it was enabled
+		enabled := 0.0
+		if options.Data.Enable != nil && *options.Data.Enable == 1 {
+			enabled = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.guestFirewallEnabled, prometheus.GaugeValue, enabled, nodeName, guest.Type, name, string(vmid), tags)
+		collectStateSet(ch, c.guestFirewallPolicy, firewallPolicies, firewallPolicy(options.Data.PolicyIn, defaultFirewallPolicyIn), nodeName, guest.Type, name, string(vmid), tags, "in")
+		collectStateSet(ch, c.guestFirewallPolicy, firewallPolicies, firewallPolicy(options.Data.PolicyOut, defaultFirewallPolicyOut), nodeName, guest.Type, name, string(vmid), tags, "out")
+	}
+
+	rules, err := wrappedProxmox.GetGuestFirewallRules(nodeName, guest.Type, vmID)
+	if err != nil {
+		logger.Logger.Error("failed making request to get guest firewall rules", "node", nodeName, "vm_id", vmid, "error", err.Error())
+	} else {
+		enabledRules, disabledRules := countFirewallRules(rules)
+		ch <- prometheus.MustNewConstMetric(c.guestFirewallRules, prometheus.GaugeValue, float64(enabledRules), nodeName, guest.Type, name, string(vmid), tags, "true")
+		ch <- prometheus.MustNewConstMetric(c.guestFirewallRules, prometheus.GaugeValue, float64(disabledRules), nodeName, guest.Type, name, string(vmid), tags, "false")
+	}
+}
+
+// firewallPolicy returns a configured firewall policy, or the policy PVE defaults to if it wasn't configured
+func firewallPolicy(policy *string, defaultPolicy string) string {
+	if policy == nil || *policy == "" {
+		return defaultPolicy
+	}
+	return *policy
+}
+
+// countFirewallRules returns the number of enabled and disabled rules of a firewall. Rules without the enable option are disabled
+func countFirewallRules(rules *wrappedProxmox.GetFirewallRulesResponse) (enabled, disabled int) {
+	for _, rule := range rules.Data {
+		if rule.Enable != nil && *rule.Enable == 1 {
+			enabled++
+		} else {
+			disabled++
+		}
+	}
+	return enabled, disabled
+}
@@ -0,0 +1,130 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestCollectClusterFirewallMetrics(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+
+	rules := &wrappedProxmox.GetFirewallRulesResponse{
+		Data: []wrappedProxmox.GetFirewallRulesData{
+			{Pos: 0, Type: "in", Action: "ACCEPT", Enable: intPtr(1)},
+			{Pos: 1, Type: "group", Action: "webservers", Enable: intPtr(1)},
+			{Pos: 2, Type: "out", Action: "DROP"},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		options           wrappedProxmox.GetClusterFirewallOptionsData
+		expectedEnabled   float64
+		expectedPolicyIn  string
+		expectedPolicyOut string
+	}{
+		{"defaults", wrappedProxmox.GetClusterFirewallOptionsData{}, 0, "DROP", "ACCEPT"},
+		{"configured", wrappedProxmox.GetClusterFirewallOptionsData{Enable: intPtr(1), PolicyIn: strPtr("REJECT"), PolicyOut: strPtr("DROP")}, 1, "REJECT", "DROP"},
+		{"disabled", wrappedProxmox.GetClusterFirewallOptionsData{Enable: intPtr(0), PolicyIn: strPtr("ACCEPT")}, 0, "ACCEPT", "ACCEPT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCollector()
+			ch := make(chan prometheus.Metric, 100)
+
+			c.collectClusterFirewallMetrics(ch, &wrappedProxmox.GetClusterFirewallOptionsResponse{Data: tt.options}, rules)
+			metrics := drainMetrics(ch)
+
+			enabled := findByDesc(metrics, c.clusterFirewallEnabled)
+			if len(enabled) != 1 {
+				t.Fatalf("expected 1 enabled metric, got %d", len(enabled))
+			}
+			if v := getMetricValue(enabled[0]); v != tt.expectedEnabled {
+				t.Errorf("enabled: expected %f, got %f", tt.expectedEnabled, v)
+			}
+
+			policies := findByDesc(metrics, c.clusterFirewallPolicy)
+			if len(policies) != 2*len(firewallPolicies) {
+				t.Fatalf("expected %d policy metrics, got %d", 2*len(firewallPolicies), len(policies))
+			}
+			expected := map[string]string{"in": tt.expectedPolicyIn, "out": tt.expectedPolicyOut}
+			for _, m := range policies {
+				labels := getMetricLabels(m)
+				want := 0.0
+				if expected[labels["direction"]] == labels["policy"] {
+					want = 1.0
+				}
+				if v := getMetricValue(m); v != want {
+					t.Errorf("policy %v: expected %f, got %f", labels, want, v)
+				}
+			}
+
+			for _, m := range findByDesc(metrics, c.clusterFirewallRules) {
+				labels := getMetricLabels(m)
+				want := 1.0
+				if labels["enabled"] == "true" {
+					want = 2.0
+				}
+				if v := getMetricValue(m); v != want {
+					t.Errorf("rules %v: expected %f, got %f", labels, want, v)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectNodeFirewallMetrics(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name     string
+		enable   *int
+		expected float64
+	}{
+		{"enabled by default", nil, 1},
+		{"enabled", intPtr(1), 1},
+		{"disabled", intPtr(0), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCollector()
+			ch := make(chan prometheus.Metric, 100)
+
+			options := &wrappedProxmox.GetNodeFirewallOptionsResponse{Data: wrappedProxmox.GetNodeFirewallOptionsData{Enable: tt.enable}}
+			c.collectNodeFirewallMetrics(ch, "node1", options, nil)
+			metrics := drainMetrics(ch)
+
+			if len(metrics) != 1 {
+				t.Fatalf("expected 1 metric, got %d", len(metrics))
+			}
+			if v := getMetricValue(metrics[0]); v != tt.expected {
+				t.Errorf("expected %f, got %f", tt.expected, v)
+			}
+		})
+	}
+}
+
+func TestCountFirewallRules(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	enabled, disabled := countFirewallRules(&wrappedProxmox.GetFirewallRulesResponse{
+		Data: []wrappedProxmox.GetFirewallRulesData{
+			{Type: "in", Enable: intPtr(1)},
+			{Type: "out", Enable: intPtr(0)},
+			{Type: "in"},
+		},
+	})
+	if enabled != 1 || disabled != 2 {
+		t.Errorf("expected 1 enabled and 2 disabled rules, got %d and %d", enabled, disabled)
+	}
+
+	enabled, disabled = countFirewallRules(&wrappedProxmox.GetFirewallRulesResponse{})
+	if enabled != 0 || disabled != 0 {
+		t.Errorf("expected no rules, got %d and %d", enabled, disabled)
+	}
+}
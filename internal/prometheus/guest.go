@@ -0,0 +1,68 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+)
+
+// guestRequestWorkers is the maximum number of guests on a node whose configuration and firewall are requested at once
+const guestRequestWorkers = 8
+
+// guestLabels returns the identifying name, vmid and tags of a qemu or lxc entry from cluster resources
+func guestLabels(guest proxmox.GetClusterResourcesData) (name string, vmid proxmox.IntOrString, tags string) {
+	if guest.Name != nil {
+		name = *guest.Name
+	}
+	if guest.VMID != nil {
+		vmid = *guest.VMID
+	}
+	if guest.Tags != nil {
+		tags = *guest.Tags
+	}
+	return name, vmid, tags
+}
+
+// collectGuestInfoMetrics processes the lock, pool and HA state of qemu and lxc entries from cluster resources
+func (c *Collector) collectGuestInfoMetrics(ch chan<- prometheus.Metric, guests []proxmox.GetClusterResourcesData) {
+	for _, guest := range guests {
+		if guest.Template != nil && *guest.Template == 1 {
+			continue
+		}
+		name, vmid, tags := guestLabels(guest)
+
+		pool := ""
+		if guest.Pool != nil {
+			pool = *guest.Pool
+		}
+		haState := ""
+		if guest.HAState != nil {
+			haState = *guest.HAState
+		}
+		ch <- prometheus.MustNewConstMetric(c.guestInfo, prometheus.GaugeValue, 1, guest.Node, guest.Type, name, string(vmid), tags, pool, haState)
+
+		if guest.Lock != nil && *guest.Lock != "" {
+			ch <- prometheus.MustNewConstMetric(c.guestLock, prometheus.GaugeValue, 1, guest.Node, guest.Type, name, string(vmid), tags, *guest.Lock)
+		}
+	}
+}
+
+// collectPerGuestMetrics requests the configuration and the firewall options and rules of each guest on a node.
+// Nothing is cached yet after the exporter starts, so guests are requested by a fixed number of workers rather than one after another
+func (c *Collector) collectPerGuestMetrics(ch chan<- prometheus.Metric, nodeName string, guests []proxmox.GetClusterResourcesData) {
+	forEachConcurrently(len(guests), guestRequestWorkers, func(i int) {
+		c.collectGuestConfigMetrics(ch, nodeName, guests[i])
+		c.collectGuestFirewallMetrics(ch, nodeName, guests[i])
+	})
+}
+
+// guestsByNode groups qemu and lxc entries from cluster resources by the node they're on, excluding templates
+func guestsByNode(guests []proxmox.GetClusterResourcesData) map[string][]proxmox.GetClusterResourcesData {
+	nodes := make(map[string][]proxmox.GetClusterResourcesData)
+	for _, guest := range guests {
+		if guest.Template != nil && *guest.Template == 1 {
+			continue
+		}
+		nodes[guest.Node] = append(nodes[guest.Node], guest)
+	}
+	return nodes
+}
@@ -0,0 +1,177 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+)
+
+func TestGuestLabels(t *testing.T) {
+	strPtr := func(v string) *string { return &v }
+	vmidPtr := func(v string) *proxmox.IntOrString {
+		ios := proxmox.IntOrString(v)
+		return &ios
+	}
+
+	name, vmid, tags := guestLabels(proxmox.GetClusterResourcesData{
+		Name: strPtr("web-server"),
+		VMID: vmidPtr("100"),
+		Tags: strPtr("prod;web"),
+	})
+	if name != "web-server" || vmid != "100" || tags != "prod;web" {
+		t.Errorf("unexpected labels: name=%s vmid=%s tags=%s", name, vmid, tags)
+	}
+
+	name, vmid, tags = guestLabels(proxmox.GetClusterResourcesData{})
+	if name != "" || vmid != "" || tags != "" {
+		t.Errorf("expected empty labels for nil fields: name=%s vmid=%s tags=%s", name, vmid, tags)
+	}
+}
+
+func TestCollectGuestInfoMetrics(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+	vmidPtr := func(v string) *proxmox.IntOrString {
+		ios := proxmox.IntOrString(v)
+		return &ios
+	}
+
+	tests := []struct {
+		name          string
+		guests        []proxmox.GetClusterResourcesData
+		expectedInfo  int
+		expectedLocks int
+	}{
+		{
+			name: "unlocked guest",
+			guests: []proxmox.GetClusterResourcesData{
+				{Node: "node1", Type: "qemu", Name: strPtr("vm1"), VMID: vmidPtr("100")},
+			},
+			expectedInfo:  1,
+			expectedLocks: 0,
+		},
+		{
+			name: "locked guest",
+			guests: []proxmox.GetClusterResourcesData{
+				{Node: "node1", Type: "lxc", Name: strPtr("ct1"), VMID: vmidPtr("200"), Lock: strPtr("backup")},
+			},
+			expectedInfo:  1,
+			expectedLocks: 1,
+		},
+		{
+			name: "empty lock is not exported",
+			guests: []proxmox.GetClusterResourcesData{
+				{Node: "node1", Type: "qemu", Name: strPtr("vm1"), VMID: vmidPtr("100"), Lock: strPtr("")},
+			},
+			expectedInfo:  1,
+			expectedLocks: 0,
+		},
+		{
+			name: "template excluded",
+			guests: []proxmox.GetClusterResourcesData{
+				{Node: "node1", Type: "qemu", Name: strPtr("template"), VMID: vmidPtr("900"), Template: intPtr(1), Lock: strPtr("migrate")},
+			},
+			expectedInfo:  0,
+			expectedLocks: 0,
+		},
+		{
+			name:          "no guests",
+			guests:        []proxmox.GetClusterResourcesData{},
+			expectedInfo:  0,
+			expectedLocks: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCollector()
+			ch := make(chan prometheus.Metric, 100)
+
+			c.collectGuestInfoMetrics(ch, tt.guests)
+
+			metrics := drainMetrics(ch)
+			if n := countByDesc(metrics, c.guestInfo); n != tt.expectedInfo {
+				t.Errorf("guestInfo: expected %d, got %d", tt.expectedInfo, n)
+			}
+			if n := countByDesc(metrics, c.guestLock); n != tt.expectedLocks {
+				t.Errorf("guestLock: expected %d, got %d", tt.expectedLocks, n)
+			}
+		})
+	}
+}
+
+func TestCollectGuestInfoMetrics_Labels(t *testing.T) {
+	strPtr := func(v string) *string { return &v }
+	vmidPtr := func(v string) *proxmox.IntOrString {
+		ios := proxmox.IntOrString(v)
+		return &ios
+	}
+
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 10)
+
+	guests := []proxmox.GetClusterResourcesData{
+		{
+			Node:    "node1",
+			Type:    "qemu",
+			Name:    strPtr("web-server"),
+			VMID:    vmidPtr("100"),
+			Tags:    strPtr("prod"),
+			Pool:    strPtr("tenant-a"),
+			HAState: strPtr("started"),
+			Lock:    strPtr("migrate"),
+		},
+	}
+
+	c.collectGuestInfoMetrics(ch, guests)
+	metrics := drainMetrics(ch)
+
+	info := findByDesc(metrics, c.guestInfo)
+	if len(info) != 1 {
+		t.Fatalf("expected 1 info metric, got %d", len(info))
+	}
+	labels := getMetricLabels(info[0])
+	if labels["pool"] != "tenant-a" {
+		t.Errorf("expected pool=tenant-a, got %s", labels["pool"])
+	}
+	if labels["hastate"] != "started" {
+		t.Errorf("expected hastate=started, got %s", labels["hastate"])
+	}
+	if labels["type"] != "qemu" {
+		t.Errorf("expected type=qemu, got %s", labels["type"])
+	}
+
+	locks := findByDesc(metrics, c.guestLock)
+	if len(locks) != 1 {
+		t.Fatalf("expected 1 lock metric, got %d", len(locks))
+	}
+	labels = getMetricLabels(locks[0])
+	if labels["lock"] != "migrate" {
+		t.Errorf("expected lock=migrate, got %s", labels["lock"])
+	}
+	if v := getMetricValue(locks[0]); v != 1.0 {
+		t.Errorf("expected lock value 1, got %f", v)
+	}
+}
+
+func TestGuestsByNode(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	result := guestsByNode([]proxmox.GetClusterResourcesData{
+		{Type: "qemu", Node: "node1", Template: intPtr(0)},
+		{Type: "qemu", Node: "node1", Template: intPtr(1)},
+		{Type: "lxc", Node: "node1"},
+		{Type: "qemu", Node: "node2", Template: intPtr(0)},
+	})
+
+	if len(result) != 2 {
+		t.Fatalf("expected guests on 2 nodes, got %d", len(result))
+	}
+	if len(result["node1"]) != 2 {
+		t.Errorf("node1: expected 2 guests, got %d", len(result["node1"]))
+	}
+	if len(result["node2"]) != 1 {
+		t.Errorf("node2: expected 1 guest, got %d", len(result["node2"]))
+	}
+}
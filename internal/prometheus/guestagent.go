@@ -0,0 +1,155 @@
+package prometheus
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	"github.com/starttoaster/proxmox-exporter/internal/logger"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// defaultGuestAgentDeadline is the maximum time a scrape waits for the guest agents of a node, if it isn't configured
+const defaultGuestAgentDeadline = 10 * time.Second
+
+// collectGuestAgentMetrics queries the QEMU guest agent of each running VM on a node that has the agent enabled.
+// VMs are queried concurrently, and the scrape stops waiting for them after the guest agent deadline. VMs that haven't been
+// collected by then are left out of the scrape, and aren't queried again until their earlier queries finish in the background
+func (c *Collector) collectGuestAgentMetrics(ch chan<- prometheus.Metric, nodeName string, guests []proxmox.GetClusterResourcesData) {
+	deadline := cfg.GuestAgentDeadline
+	if deadline <= 0 {
+		deadline = defaultGuestAgentDeadline
+	}
+	timeout := time.NewTimer(deadline)
+	defer timeout.Stop()
+
+	// VMs send their metrics once they're all collected, since ch can't be used after the scrape stopped waiting for them
+	results := make(chan []prometheus.Metric, len(guests))
+	pending := 0
+	for _, guest := range guests {
+		if guest.Type != "qemu" || !strings.EqualFold(guest.Status, "running") {
+			continue
+		}
+		_, vmid, _ := guestLabels(guest)
+		vmID, err := strconv.Atoi(string(vmid))
+		if err != nil {
+			continue
+		}
+
+		// The config was already requested for the guest config metrics, and is cached
+		config, err := wrappedProxmox.GetQemuConfig(nodeName, vmID)
+		if err != nil || config.Data.Agent == nil || !qemuAgentEnabled(string(*config.Data.Agent)) {
+			continue
+		}
+
+		if _, inFlight := c.guestAgentsInFlight.LoadOrStore(vmID, true); inFlight {
+			logger.Logger.Debug("guest agent is still being queried from an earlier scrape", "node", nodeName, "vm_id", vmid)
+			continue
+		}
+		pending++
+		go func() {
+			defer c.guestAgentsInFlight.Delete(vmID)
+			results <- bufferMetrics(func(ch chan<- prometheus.Metric) {
+				c.collectQemuAgentMetrics(ch, nodeName, guest, vmID)
+			})
+		}()
+	}
+
+	for ; pending > 0; pending-- {
+		select {
+		case metrics := <-results:
+			for _, m := range metrics {
+				ch <- m
+			}
+		case <-timeout.C:
+			logger.Logger.Warn("stopped waiting for guest agents after the deadline", "node", nodeName, "deadline", deadline, "remaining_vms", pending)
+			return
+		}
+	}
+}
+
+// bufferMetrics returns the metrics sent by collect
+func bufferMetrics(collect func(ch chan<- prometheus.Metric)) []prometheus.Metric {
+	var metrics []prometheus.Metric
+	ch := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for m := range ch {
+			metrics = append(metrics, m)
+		}
+	}()
+	collect(ch)
+	close(ch)
+	<-done
+	return metrics
+}
+
+// collectQemuAgentMetrics exports whether a VM's guest agent responds, and the usage of the filesystems mounted inside the VM,
+// its operating system and the addresses of its network interfaces
+func (c *Collector) collectQemuAgentMetrics(ch chan<- prometheus.Metric, nodeName string, guest proxmox.GetClusterResourcesData, vmID int) {
+	name, vmid, tags := guestLabels(guest)
+
+	// An agent that isn't running is expected for some VMs, so failed pings aren't logged as errors
+	_, err := wrappedProxmox.PostQemuAgentPing(nodeName, vmID)
+	if err != nil {
+		logger.Logger.Debug("guest agent didn't respond to ping", "node", nodeName, "vm_id", vmid, "error", err.Error())
+		ch <- prometheus.MustNewConstMetric(c.guestAgentUp, prometheus.GaugeValue, 0, nodeName, "qemu", name, string(vmid), tags)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.guestAgentUp, prometheus.GaugeValue, 1, nodeName, "qemu", name, string(vmid), tags)
+
+	fsInfo, err := wrappedProxmox.GetQemuAgentFsInfo(nodeName, vmID)
+	if err != nil {
+		logger.Logger.Error("failed making request to get guest agent filesystems", "node", nodeName, "vm_id", vmid, "error", err.Error())
+	} else {
+		c.collectGuestFilesystemMetrics(ch, nodeName, name, string(vmid), tags, fsInfo)
+	}
+
+	osInfo, err := wrappedProxmox.GetQemuAgentOsInfo(nodeName, vmID)
+	if err != nil {
+		logger.Logger.Error("failed making request to get guest agent os info", "node", nodeName, "vm_id", vmid, "error", err.Error())
+	} else {
+		c.collectGuestOSMetrics(ch, nodeName, name, string(vmid), tags, osInfo)
+	}
+
+	interfaces, err := wrappedProxmox.GetQemuAgentNetworkInterfaces(nodeName, vmID)
+	if err != nil {
+		logger.Logger.Error("failed making request to get guest agent network interfaces", "node", nodeName, "vm_id", vmid, "error", err.Error())
+	} else {
+		c.collectQemuNetworkMetrics(ch, nodeName, name, string(vmid), tags, interfaces)
+	}
+}
+
+// collectGuestFilesystemMetrics exports the size and usage of each filesystem mounted inside a VM.
+// A mountpoint the agent reports more than once is only exported once, so it doesn't make duplicate series.
+// Bind mounts of a filesystem at different mountpoints are each exported
+func (c *Collector) collectGuestFilesystemMetrics(ch chan<- prometheus.Metric, nodeName, name, vmid, tags string, fsInfo *wrappedProxmox.GetQemuAgentFsInfoResponse) {
+	seen := make(map[string]bool)
+	for _, fs := range fsInfo.Data.Result {
+		if fs.TotalBytes == nil || seen[fs.Mountpoint] {
+			continue
+		}
+		seen[fs.Mountpoint] = true
+
+		ch <- prometheus.MustNewConstMetric(c.guestFilesystemSize, prometheus.GaugeValue, float64(*fs.TotalBytes), nodeName, "qemu", name, vmid, tags, fs.Mountpoint, fs.Type)
+		if fs.UsedBytes != nil {
+			ch <- prometheus.MustNewConstMetric(c.guestFilesystemUsed, prometheus.GaugeValue, float64(*fs.UsedBytes), nodeName, "qemu", name, vmid, tags, fs.Mountpoint, fs.Type)
+		}
+	}
+}
+
+// collectGuestOSMetrics exports the distribution, version and kernel of the operating system running inside a VM
+func (c *Collector) collectGuestOSMetrics(ch chan<- prometheus.Metric, nodeName, name, vmid, tags string, osInfo *wrappedProxmox.GetQemuAgentOsInfoResponse) {
+	value := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	result := osInfo.Data.Result
+	ch <- prometheus.MustNewConstMetric(c.guestOSInfo, prometheus.GaugeValue, 1, nodeName, "qemu", name, vmid, tags,
+		value(result.ID), value(result.VersionID), value(result.KernelRelease), value(result.PrettyName))
+}
@@ -0,0 +1,111 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestCollectGuestFilesystemMetrics(t *testing.T) {
+	oldCfg := cfg
+	cfg = Config{EnableGuestAgentMetrics: true}
+	defer func() { cfg = oldCfg }()
+
+	intPtr := func(v int) *int { return &v }
+
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 100)
+
+	c.collectGuestFilesystemMetrics(ch, "node1", "web", "100", "prod", &wrappedProxmox.GetQemuAgentFsInfoResponse{
+		Data: wrappedProxmox.GetQemuAgentFsInfoData{
+			Result: []wrappedProxmox.GetQemuAgentFsInfoResult{
+				{Name: "sda1", Mountpoint: "/", Type: "ext4", TotalBytes: intPtr(1000), UsedBytes: intPtr(400)},
+				{Name: "sda1", Mountpoint: "/", Type: "ext4", TotalBytes: intPtr(1000), UsedBytes: intPtr(400)},
+				{Name: "sdb1", Mountpoint: "/data", Type: "xfs", TotalBytes: intPtr(5000)},
+				{Name: "sr0", Mountpoint: "/media/cdrom", Type: "iso9660"},
+				{Name: "Volume{1234}", Mountpoint: "C:\\", Type: "NTFS", TotalBytes: intPtr(2000), UsedBytes: intPtr(1500)},
+			},
+		},
+	})
+	metrics := drainMetrics(ch)
+
+	sizes := findByDesc(metrics, c.guestFilesystemSize)
+	if len(sizes) != 3 {
+		t.Fatalf("expected 3 size metrics, got %d", len(sizes))
+	}
+	used := findByDesc(metrics, c.guestFilesystemUsed)
+	if len(used) != 2 {
+		t.Fatalf("expected 2 used metrics, got %d", len(used))
+	}
+
+	expectedSizes := map[string]float64{"/": 1000, "/data": 5000, "C:\\": 2000}
+	for _, m := range sizes {
+		labels := getMetricLabels(m)
+		if v := getMetricValue(m); v != expectedSizes[labels["mountpoint"]] {
+			t.Errorf("size of %s: expected %f, got %f", labels["mountpoint"], expectedSizes[labels["mountpoint"]], v)
+		}
+		if labels["type"] != "qemu" || labels["vmid"] != "100" {
+			t.Errorf("unexpected labels: %v", labels)
+		}
+	}
+	expectedUsed := map[string]float64{"/": 400, "C:\\": 1500}
+	for _, m := range used {
+		labels := getMetricLabels(m)
+		if v := getMetricValue(m); v != expectedUsed[labels["mountpoint"]] {
+			t.Errorf("used of %s: expected %f, got %f", labels["mountpoint"], expectedUsed[labels["mountpoint"]], v)
+		}
+	}
+}
+
+func TestCollectGuestOSMetrics(t *testing.T) {
+	oldCfg := cfg
+	cfg = Config{EnableGuestAgentMetrics: true}
+	defer func() { cfg = oldCfg }()
+
+	strPtr := func(v string) *string { return &v }
+
+	tests := []struct {
+		name     string
+		result   wrappedProxmox.GetQemuAgentOsInfoResult
+		expected map[string]string
+	}{
+		{
+			"linux",
+			wrappedProxmox.GetQemuAgentOsInfoResult{ID: strPtr("debian"), PrettyName: strPtr("Debian GNU/Linux 12 (bookworm)"), VersionID: strPtr("12"), KernelRelease: strPtr("6.1.0-18-amd64")},
+			map[string]string{"distro": "debian", "version": "12", "kernel": "6.1.0-18-amd64", "pretty_name": "Debian GNU/Linux 12 (bookworm)"},
+		},
+		{
+			"windows",
+			wrappedProxmox.GetQemuAgentOsInfoResult{ID: strPtr("mswindows"), PrettyName: strPtr("Windows Server 2022 Standard"), VersionID: strPtr("2022"), KernelRelease: strPtr("20348")},
+			map[string]string{"distro": "mswindows", "version": "2022", "kernel": "20348", "pretty_name": "Windows Server 2022 Standard"},
+		},
+		{
+			"nothing reported",
+			wrappedProxmox.GetQemuAgentOsInfoResult{},
+			map[string]string{"distro": "", "version": "", "kernel": "", "pretty_name": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCollector()
+			ch := make(chan prometheus.Metric, 10)
+
+			c.collectGuestOSMetrics(ch, "node1", "web", "100", "prod", &wrappedProxmox.GetQemuAgentOsInfoResponse{
+				Data: wrappedProxmox.GetQemuAgentOsInfoData{Result: tt.result},
+			})
+			metrics := drainMetrics(ch)
+
+			if len(metrics) != 1 {
+				t.Fatalf("expected 1 metric, got %d", len(metrics))
+			}
+			labels := getMetricLabels(metrics[0])
+			for label, value := range tt.expected {
+				if labels[label] != value {
+					t.Errorf("%s: expected %q, got %q", label, value, labels[label])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package prometheus
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	"github.com/starttoaster/proxmox-exporter/internal/logger"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// collectGuestConfigMetrics requests the configuration of a guest, and exports the options that affect how it starts and runs
+func (c *Collector) collectGuestConfigMetrics(ch chan<- prometheus.Metric, nodeName string, guest proxmox.GetClusterResourcesData) {
+	name, vmid, tags := guestLabels(guest)
+	vmID, err := strconv.Atoi(string(vmid))
+	if err != nil {
+		logger.Logger.Error("failed converting guest ID for guest config", "node", nodeName, "vm_id", vmid, "error", err.Error())
+		return
+	}
+
+	switch guest.Type {
+	case "qemu":
+		config, err := wrappedProxmox.GetQemuConfig(nodeName, vmID)
+		if err != nil {
+			logger.Logger.Error("failed making request to get qemu config", "node", nodeName, "vm_id", vmid, "error", err.Error())
+			return
+		}
+		c.collectQemuConfigMetrics(ch, nodeName, name, string(vmid), tags, config)
+	case "lxc":
+		config, err := wrappedProxmox.GetLxcConfig(nodeName, vmID)
+		if err != nil {
+			logger.Logger.Error("failed making request to get LXC config", "node", nodeName, "vm_id", vmid, "error", err.Error())
+			return
+		}
+		c.collectLxcConfigMetrics(ch, nodeName, name, string(vmid), tags, config)
+	}
+}
+
+// collectQemuConfigMetrics exports the start on boot, protection, guest agent, ballooning, CPU topology and type, machine type and OS type options of a VM
+func (c *Collector) collectQemuConfigMetrics(ch chan<- prometheus.Metric, nodeName, name, vmid, tags string, config *wrappedProxmox.GetQemuConfigResponse) {
+	labels := []string{nodeName, "qemu", name, vmid, tags}
+	options := config.Data
+
+	c.collectGuestStartupMetrics(ch, options.OnBoot, options.Protection, labels)
+
+	agent := 0.0
+	if options.Agent != nil && qemuAgentEnabled(string(*options.Agent)) {
+		agent = 1.0
+	}
+	ch <- prometheus.MustNewConstMetric(c.guestAgentEnabled, prometheus.GaugeValue, agent, labels...)
+
+	// Without the balloon option, the balloon device is enabled without a minimum below the VM's memory
+	if options.Balloon != nil {
+		ch <- prometheus.MustNewConstMetric(c.guestBalloonMin, prometheus.GaugeValue, float64(*options.Balloon)*1024*1024, labels...)
+	}
+
+	// VMs have 1 socket and 1 core per socket unless configured otherwise
+	sockets, cores := 1, 1
+	if options.Sockets != nil {
+		sockets = *options.Sockets
+	}
+	if options.Cores != nil {
+		cores = *options.Cores
+	}
+	ch <- prometheus.MustNewConstMetric(c.guestCPUSockets, prometheus.GaugeValue, float64(sockets), labels...)
+	ch <- prometheus.MustNewConstMetric(c.guestCPUCores, prometheus.GaugeValue, float64(cores), labels...)
+
+	cpuType := ""
+	if options.CPU != nil {
+		cpuType = qemuCPUType(*options.CPU)
+	}
+	machine := ""
+	if options.Machine != nil {
+		machine = *options.Machine
+	}
+	osType := ""
+	if options.OSType != nil {
+		osType = *options.OSType
+	}
+	ch <- prometheus.MustNewConstMetric(c.guestConfigInfo, prometheus.GaugeValue, 1, append(labels, cpuType, machine, osType)...)
+}
+
+// collectLxcConfigMetrics exports the start on boot, protection, CPU cores and OS type options of a LXC
+func (c *Collector) collectLxcConfigMetrics(ch chan<- prometheus.Metric, nodeName, name, vmid, tags string, config *wrappedProxmox.GetLxcConfigResponse) {
+	labels := []string{nodeName, "lxc", name, vmid, tags}
+	options := config.Data
+
+	c.collectGuestStartupMetrics(ch, options.OnBoot, options.Protection, labels)
+
+	// LXCs without the cores option may use all of the node's cores
+	if options.Cores != nil {
+		ch <- prometheus.MustNewConstMetric(c.guestCPUCores, prometheus.GaugeValue, float64(*options.Cores), labels...)
+	}
+
+	osType := ""
+	if options.OSType != nil {
+		osType = *options.OSType
+	}
+	ch <- prometheus.MustNewConstMetric(c.guestConfigInfo, prometheus.GaugeValue, 1, append(labels, "", "", osType)...)
+}
+
+// collectGuestStartupMetrics exports whether a guest starts on boot and is protected from removal, both of which are disabled unless configured
+func (c *Collector) collectGuestStartupMetrics(ch chan<- prometheus.Metric, onBoot, protection *int, labels []string) {
+	boot := 0.0
+	if onBoot != nil && *onBoot == 1 {
+		boot = 1.0
+	}
+	ch <- prometheus.MustNewConstMetric(c.guestOnBoot, prometheus.GaugeValue, boot, labels...)
+
+	protected := 0.0
+	if protection != nil && *protection == 1 {
+		protected = 1.0
+	}
+	ch <- prometheus.MustNewConstMetric(c.guestProtection, prometheus.GaugeValue, protected, labels...)
+}
+
+// qemuAgentEnabled parses the agent option of a VM, which is either a boolean or a property string (ex: 1, enabled=1,fstrim_cloned_disks=1)
+func qemuAgentEnabled(agent string) bool {
+	for _, prop := range strings.Split(agent, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(prop), "=")
+		if !found {
+			// The enabled property may be given without its key, but only as the first property
+			return key == "1"
+		}
+		if key == "enabled" {
+			return value == "1"
+		}
+	}
+	return false
+}
+
+// qemuCPUType returns the CPU type from a VM's cpu option, which is a property string that may include flags (ex: host,flags=+aes)
+func qemuCPUType(cpu string) string {
+	for _, prop := range strings.Split(cpu, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(prop), "=")
+		if !found {
+			return key
+		}
+		if key == "cputype" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,179 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestQemuAgentEnabled(t *testing.T) {
+	tests := []struct {
+		agent    string
+		expected bool
+	}{
+		{"1", true},
+		{"0", false},
+		{"1,fstrim_cloned_disks=1", true},
+		{"0,type=isa", false},
+		{"enabled=1", true},
+		{"enabled=0,fstrim_cloned_disks=1", false},
+		{"fstrim_cloned_disks=1,enabled=1", true},
+		{"type=virtio", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := qemuAgentEnabled(tt.agent); got != tt.expected {
+			t.Errorf("qemuAgentEnabled(%q): expected %v, got %v", tt.agent, tt.expected, got)
+		}
+	}
+}
+
+func TestQemuCPUType(t *testing.T) {
+	tests := []struct {
+		cpu      string
+		expected string
+	}{
+		{"host", "host"},
+		{"x86-64-v2-AES", "x86-64-v2-AES"},
+		{"host,flags=+aes;+pcid", "host"},
+		{"cputype=kvm64,hidden=1", "kvm64"},
+		{"flags=+aes,cputype=Skylake-Server", "Skylake-Server"},
+	}
+
+	for _, tt := range tests {
+		if got := qemuCPUType(tt.cpu); got != tt.expected {
+			t.Errorf("qemuCPUType(%q): expected %q, got %q", tt.cpu, tt.expected, got)
+		}
+	}
+}
+
+func TestCollectQemuConfigMetrics(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+	agentPtr := func(v string) *proxmox.IntOrString {
+		agent := proxmox.IntOrString(v)
+		return &agent
+	}
+
+	t.Run("configured", func(t *testing.T) {
+		c := testCollector()
+		ch := make(chan prometheus.Metric, 100)
+
+		c.collectQemuConfigMetrics(ch, "node1", "web", "100", "prod", &wrappedProxmox.GetQemuConfigResponse{
+			Data: wrappedProxmox.GetQemuConfigData{
+				OnBoot:     intPtr(1),
+				Protection: intPtr(1),
+				Agent:      agentPtr("enabled=1"),
+				Balloon:    intPtr(1024),
+				Sockets:    intPtr(2),
+				Cores:      intPtr(4),
+				CPU:        strPtr("host,flags=+aes"),
+				Machine:    strPtr("pc-q35-8.1"),
+				OSType:     strPtr("l26"),
+			},
+		})
+		metrics := drainMetrics(ch)
+
+		if len(metrics) != 7 {
+			t.Fatalf("expected 7 metrics, got %d", len(metrics))
+		}
+		expected := map[*prometheus.Desc]float64{
+			c.guestOnBoot:       1,
+			c.guestProtection:   1,
+			c.guestAgentEnabled: 1,
+			c.guestBalloonMin:   1073741824,
+			c.guestCPUSockets:   2,
+			c.guestCPUCores:     4,
+			c.guestConfigInfo:   1,
+		}
+		for desc, want := range expected {
+			found := findByDesc(metrics, desc)
+			if len(found) != 1 {
+				t.Errorf("%s: expected 1 metric, got %d", desc, len(found))
+				continue
+			}
+			if v := getMetricValue(found[0]); v != want {
+				t.Errorf("%s: expected %f, got %f", desc, want, v)
+			}
+		}
+
+		labels := getMetricLabels(findByDesc(metrics, c.guestConfigInfo)[0])
+		if labels["type"] != "qemu" || labels["cpu_type"] != "host" || labels["machine"] != "pc-q35-8.1" || labels["ostype"] != "l26" {
+			t.Errorf("unexpected info labels: %v", labels)
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		c := testCollector()
+		ch := make(chan prometheus.Metric, 100)
+
+		c.collectQemuConfigMetrics(ch, "node1", "web", "100", "", &wrappedProxmox.GetQemuConfigResponse{})
+		metrics := drainMetrics(ch)
+
+		// Without the balloon option, no minimum is exported
+		if len(metrics) != 6 {
+			t.Fatalf("expected 6 metrics, got %d", len(metrics))
+		}
+		expected := map[*prometheus.Desc]float64{
+			c.guestOnBoot:       0,
+			c.guestProtection:   0,
+			c.guestAgentEnabled: 0,
+			c.guestCPUSockets:   1,
+			c.guestCPUCores:     1,
+		}
+		for desc, want := range expected {
+			for _, m := range findByDesc(metrics, desc) {
+				if v := getMetricValue(m); v != want {
+					t.Errorf("%s: expected %f, got %f", desc, want, v)
+				}
+			}
+		}
+
+		labels := getMetricLabels(findByDesc(metrics, c.guestConfigInfo)[0])
+		if labels["cpu_type"] != "" || labels["machine"] != "" || labels["ostype"] != "" {
+			t.Errorf("expected empty info labels, got %v", labels)
+		}
+	})
+}
+
+func TestCollectLxcConfigMetrics(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+
+	tests := []struct {
+		name          string
+		config        wrappedProxmox.GetLxcConfigData
+		expectedCount int
+		expectedBoot  float64
+	}{
+		{"configured", wrappedProxmox.GetLxcConfigData{OnBoot: intPtr(1), Protection: intPtr(0), Cores: intPtr(2), OSType: strPtr("debian")}, 4, 1},
+		{"unlimited cores", wrappedProxmox.GetLxcConfigData{OSType: strPtr("alpine")}, 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCollector()
+			ch := make(chan prometheus.Metric, 100)
+
+			c.collectLxcConfigMetrics(ch, "node1", "dns", "200", "", &wrappedProxmox.GetLxcConfigResponse{Data: tt.config})
+			metrics := drainMetrics(ch)
+
+			if len(metrics) != tt.expectedCount {
+				t.Fatalf("expected %d metrics, got %d", tt.expectedCount, len(metrics))
+			}
+			if n := countByDesc(metrics, c.guestAgentEnabled) + countByDesc(metrics, c.guestCPUSockets) + countByDesc(metrics, c.guestBalloonMin); n != 0 {
+				t.Errorf("expected no VM only metrics for a LXC, got %d", n)
+			}
+			if v := getMetricValue(findByDesc(metrics, c.guestOnBoot)[0]); v != tt.expectedBoot {
+				t.Errorf("onboot: expected %f, got %f", tt.expectedBoot, v)
+			}
+			labels := getMetricLabels(findByDesc(metrics, c.guestConfigInfo)[0])
+			if labels["type"] != "lxc" || labels["ostype"] != *tt.config.OSType {
+				t.Errorf("unexpected info labels: %v", labels)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package prometheus
+
+import (
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	"github.com/starttoaster/proxmox-exporter/internal/logger"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// GuestIP is an address of one of a guest's network interfaces. Family is either ipv4 or ipv6
+type GuestIP struct {
+	Interface string
+	Address   string
+	Family    string
+}
+
+// collectLxcNetworkMetrics requests the network interfaces of each running LXC on a node, and exports their addresses
+func (c *Collector) collectLxcNetworkMetrics(ch chan<- prometheus.Metric, nodeName string, guests []proxmox.GetClusterResourcesData) {
+	for _, guest := range guests {
+		if guest.Type != "lxc" || !strings.EqualFold(guest.Status, "running") {
+			continue
+		}
+		name, vmid, tags := guestLabels(guest)
+		vmID, err := strconv.Atoi(string(vmid))
+		if err != nil {
+			continue
+		}
+
+		interfaces, err := wrappedProxmox.GetLxcInterfaces(nodeName, vmID)
+		if err != nil {
+			logger.Logger.Error("failed making request to get lxc interfaces", "node", nodeName, "vm_id", vmid, "error", err.Error())
+			continue
+		}
+		c.collectGuestIPMetrics(ch, nodeName, "lxc", name, string(vmid), tags, lxcGuestIPs(interfaces))
+	}
+}
+
+// collectQemuNetworkMetrics exports the addresses of a VM's network interfaces, as reported by its QEMU guest agent
+func (c *Collector) collectQemuNetworkMetrics(ch chan<- prometheus.Metric, nodeName, name, vmid, tags string, interfaces *wrappedProxmox.GetQemuAgentNetworkInterfacesResponse) {
+	c.collectGuestIPMetrics(ch, nodeName, "qemu", name, vmid, tags, qemuGuestIPs(interfaces))
+}
+
+// collectGuestIPMetrics exports each of a guest's addresses
+func (c *Collector) collectGuestIPMetrics(ch chan<- prometheus.Metric, nodeName, guestType, name, vmid, tags string, ips []GuestIP) {
+	for _, ip := range ips {
+		ch <- prometheus.MustNewConstMetric(c.guestIPInfo, prometheus.GaugeValue, 1, nodeName, guestType, name, vmid, tags, ip.Interface, ip.Address, ip.Family)
+	}
+}
+
+// lxcGuestIPs returns the addresses of a LXC's network interfaces
+func lxcGuestIPs(interfaces *wrappedProxmox.GetLxcInterfacesResponse) []GuestIP {
+	var ips []GuestIP
+	for _, iface := range interfaces.Data {
+		for _, address := range []*string{iface.Inet, iface.Inet6} {
+			if address != nil {
+				ips = appendGuestIP(ips, iface.Name, *address)
+			}
+		}
+	}
+	return ips
+}
+
+// qemuGuestIPs returns the addresses of a VM's network interfaces reported by its QEMU guest agent
+func qemuGuestIPs(interfaces *wrappedProxmox.GetQemuAgentNetworkInterfacesResponse) []GuestIP {
+	var ips []GuestIP
+	for _, iface := range interfaces.Data.Result {
+		for _, address := range iface.IPAddresses {
+			ips = appendGuestIP(ips, iface.Name, address.IPAddress)
+		}
+	}
+	return ips
+}
+
+// appendGuestIP parses an address of a guest's interface, which may be in CIDR notation (ex: 10.0.0.5/24), and appends it to ips
+// unless it's already listed for the interface. Addresses that can't be parsed, and loopback and link-local addresses, aren't useful
+// for reaching the guest and are left out
+func appendGuestIP(ips []GuestIP, iface, address string) []GuestIP {
+	address, _, _ = strings.Cut(strings.TrimSpace(address), "/")
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return ips
+	}
+	addr = addr.WithZone("").Unmap()
+	if addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsUnspecified() {
+		return ips
+	}
+
+	family := "ipv6"
+	if addr.Is4() {
+		family = "ipv4"
+	}
+	ip := GuestIP{Interface: iface, Address: addr.String(), Family: family}
+	for _, existing := range ips {
+		if existing == ip {
+			return ips
+		}
+	}
+	return append(ips, ip)
+}
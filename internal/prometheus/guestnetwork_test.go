@@ -0,0 +1,81 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestAppendGuestIP(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected *GuestIP
+	}{
+		{"192.168.1.10", &GuestIP{Interface: "eth0", Address: "192.168.1.10", Family: "ipv4"}},
+		{"192.168.1.10/24", &GuestIP{Interface: "eth0", Address: "192.168.1.10", Family: "ipv4"}},
+		{"2001:db8::10/64", &GuestIP{Interface: "eth0", Address: "2001:db8::10", Family: "ipv6"}},
+		{"::ffff:10.0.0.1", &GuestIP{Interface: "eth0", Address: "10.0.0.1", Family: "ipv4"}},
+		{"127.0.0.1/8", nil},
+		{"::1", nil},
+		{"169.254.10.1", nil},
+		{"fe80::1%eth0", nil},
+		{"0.0.0.0", nil},
+		{"not-an-ip", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			ips := appendGuestIP(nil, "eth0", tt.address)
+			if tt.expected == nil {
+				if len(ips) != 0 {
+					t.Errorf("expected no address, got %v", ips)
+				}
+				return
+			}
+			if len(ips) != 1 || ips[0] != *tt.expected {
+				t.Errorf("expected %+v, got %v", *tt.expected, ips)
+			}
+		})
+	}
+}
+
+func TestCollectQemuNetworkMetrics(t *testing.T) {
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 100)
+
+	c.collectQemuNetworkMetrics(ch, "node1", "web", "100", "prod", &wrappedProxmox.GetQemuAgentNetworkInterfacesResponse{
+		Data: wrappedProxmox.GetQemuAgentNetworkInterfacesData{
+			Result: []wrappedProxmox.GetQemuAgentNetworkInterfacesResult{
+				{Name: "lo", IPAddresses: []wrappedProxmox.GetQemuAgentNetworkInterfacesAddress{
+					{IPAddress: "127.0.0.1", IPAddressType: "ipv4", Prefix: 8},
+					{IPAddress: "::1", IPAddressType: "ipv6", Prefix: 128},
+				}},
+				{Name: "eth0", IPAddresses: []wrappedProxmox.GetQemuAgentNetworkInterfacesAddress{
+					{IPAddress: "10.0.0.5", IPAddressType: "ipv4", Prefix: 24},
+					{IPAddress: "10.0.0.5", IPAddressType: "ipv4", Prefix: 24},
+					{IPAddress: "fe80::1", IPAddressType: "ipv6", Prefix: 64},
+					{IPAddress: "2001:db8::5", IPAddressType: "ipv6", Prefix: 64},
+				}},
+				{Name: "eth1"},
+			},
+		},
+	})
+	metrics := drainMetrics(ch)
+
+	ips := findByDesc(metrics, c.guestIPInfo)
+	if len(ips) != 2 {
+		t.Fatalf("expected 2 addresses, got %d", len(ips))
+	}
+	expected := map[string]string{"10.0.0.5": "ipv4", "2001:db8::5": "ipv6"}
+	for _, m := range ips {
+		labels := getMetricLabels(m)
+		if family, ok := expected[labels["address"]]; !ok || labels["family"] != family {
+			t.Errorf("unexpected address: %v", labels)
+		}
+		if labels["interface"] != "eth0" || labels["type"] != "qemu" || labels["vmid"] != "100" {
+			t.Errorf("unexpected labels: %v", labels)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package prometheus
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// haLRMStates are the states a node's HA local resource manager can report
+var haLRMStates = []string{"active", "idle", "wait_for_agent_lock", "lost_agent_lock", "maintenance", "dead", "unknown"}
+
+// haServiceStates are the states the HA cluster resource manager can put a resource in
+var haServiceStates = []string{"started", "stopped", "request_start", "request_start_balance", "request_stop", "migrate", "relocate", "freeze", "fence", "recovery", "error"}
+
+// haRequestedStates are the states a HA resource can be configured to be in
+var haRequestedStates = []string{"started", "stopped", "disabled", "ignored"}
+
+// collectHAMetrics processes the HA manager status and configured HA resources
+func (c *Collector) collectHAMetrics(ch chan<- prometheus.Metric, status *wrappedProxmox.GetClusterHAStatusResponse, resources *wrappedProxmox.GetClusterHAResourcesResponse) {
+	if status != nil {
+		for _, entry := range status.Data {
+			switch entry.Type {
+			case "master":
+				ch <- prometheus.MustNewConstMetric(c.haMasterInfo, prometheus.GaugeValue, 1, entry.Node)
+			case "lrm":
+				state := ""
+				if entry.State != nil {
+					state = *entry.State
+				} else {
+					state = parseHALRMStatus(entry.Status)
+				}
+				collectStateSet(ch, c.haLRMState, haLRMStates, state, entry.Node)
+			case "service":
+				sid := ""
+				if entry.SID != nil {
+					sid = *entry.SID
+				}
+				state := ""
+				if entry.CRMState != nil {
+					state = *entry.CRMState
+				} else if entry.State != nil {
+					state = *entry.State
+				}
+				collectStateSet(ch, c.haResourceState, haServiceStates, state, sid, entry.Node)
+			}
+		}
+	}
+
+	if resources != nil {
+		for _, resource := range resources.Data {
+			group := ""
+			if resource.Group != nil {
+				group = *resource.Group
+			}
+			// HA resources are started by default when no state is configured, and "enabled" is an alias of "started"
+			requested := "started"
+			if resource.State != nil && *resource.State != "enabled" {
+				requested = *resource.State
+			}
+			collectStateSet(ch, c.haResourceRequestedState, haRequestedStates, requested, resource.SID, resource.Type, group)
+		}
+	}
+}
+
+// parseHALRMStatus takes the status text of a HA LRM entry, which looks like "node1 (active, Mon Jan  1 00:00:00 2024)", and returns the state from it
+func parseHALRMStatus(status string) string {
+	start := strings.Index(status, "(")
+	if start == -1 {
+		return "unknown"
+	}
+	state := status[start+1:]
+	if end := strings.IndexAny(state, ",)"); end != -1 {
+		state = state[:end]
+	}
+	state = strings.TrimSpace(state)
+
+	switch {
+	case strings.HasPrefix(state, "old timestamp"):
+		return "dead"
+	case strings.HasPrefix(state, "maintenance"):
+		return "maintenance"
+	case strings.HasPrefix(state, "unable to read"), state == "":
+		return "unknown"
+	}
+	return state
+}
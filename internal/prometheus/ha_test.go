@@ -0,0 +1,110 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestParseHALRMStatus(t *testing.T) {
+	tests := []struct {
+		status   string
+		expected string
+	}{
+		{"node1 (active, Mon Jan  1 00:00:00 2024)", "active"},
+		{"node1 (idle, Mon Jan  1 00:00:00 2024)", "idle"},
+		{"node1 (wait_for_agent_lock, Mon Jan  1 00:00:00 2024)", "wait_for_agent_lock"},
+		{"node1 (maintenance mode, Mon Jan  1 00:00:00 2024)", "maintenance"},
+		{"node1 (old timestamp - dead?, Mon Jan  1 00:00:00 2024)", "dead"},
+		{"node1 (unable to read lrm status)", "unknown"},
+		{"node1", "unknown"},
+		{"", "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := parseHALRMStatus(tt.status); got != tt.expected {
+				t.Errorf("parseHALRMStatus(%q) = %q, want %q", tt.status, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCollectHAMetrics(t *testing.T) {
+	strPtr := func(v string) *string { return &v }
+
+	status := &wrappedProxmox.GetClusterHAStatusResponse{
+		Data: []wrappedProxmox.GetClusterHAStatusData{
+			{Type: "quorum", Node: "node1", Status: "OK"},
+			{Type: "master", Node: "node1", Status: "node1 (active, Mon Jan  1 00:00:00 2024)"},
+			{Type: "lrm", Node: "node1", Status: "node1 (active, Mon Jan  1 00:00:00 2024)"},
+			{Type: "lrm", Node: "node2", Status: "node2 (wait_for_agent_lock, Mon Jan  1 00:00:00 2024)"},
+			{Type: "service", Node: "node1", SID: strPtr("vm:100"), CRMState: strPtr("error")},
+		},
+	}
+	resources := &wrappedProxmox.GetClusterHAResourcesResponse{
+		Data: []wrappedProxmox.GetClusterHAResourcesData{
+			{SID: "vm:100", Type: "vm", State: strPtr("started"), Group: strPtr("prod")},
+			{SID: "ct:200", Type: "ct"},
+		},
+	}
+
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 200)
+
+	c.collectHAMetrics(ch, status, resources)
+	metrics := drainMetrics(ch)
+
+	masters := findByDesc(metrics, c.haMasterInfo)
+	if len(masters) != 1 {
+		t.Fatalf("expected 1 master metric, got %d", len(masters))
+	}
+	if node := getMetricLabels(masters[0])["node"]; node != "node1" {
+		t.Errorf("expected master node1, got %s", node)
+	}
+
+	if n := countByDesc(metrics, c.haLRMState); n != 2*len(haLRMStates) {
+		t.Errorf("haLRMState: expected %d, got %d", 2*len(haLRMStates), n)
+	}
+	for _, m := range findByDesc(metrics, c.haLRMState) {
+		labels := getMetricLabels(m)
+		active := (labels["node"] == "node1" && labels["state"] == "active") ||
+			(labels["node"] == "node2" && labels["state"] == "wait_for_agent_lock")
+		if active != (getMetricValue(m) == 1) {
+			t.Errorf("unexpected LRM state value for node=%s state=%s: %f", labels["node"], labels["state"], getMetricValue(m))
+		}
+	}
+
+	for _, m := range findByDesc(metrics, c.haResourceState) {
+		labels := getMetricLabels(m)
+		if (labels["state"] == "error") != (getMetricValue(m) == 1) {
+			t.Errorf("unexpected resource state value for state=%s: %f", labels["state"], getMetricValue(m))
+		}
+		if labels["sid"] != "vm:100" || labels["node"] != "node1" {
+			t.Errorf("unexpected resource state labels: %v", labels)
+		}
+	}
+
+	if n := countByDesc(metrics, c.haResourceRequestedState); n != 2*len(haRequestedStates) {
+		t.Errorf("haResourceRequestedState: expected %d, got %d", 2*len(haRequestedStates), n)
+	}
+	for _, m := range findByDesc(metrics, c.haResourceRequestedState) {
+		labels := getMetricLabels(m)
+		// ct:200 has no configured state, which means started
+		if (labels["state"] == "started") != (getMetricValue(m) == 1) {
+			t.Errorf("unexpected requested state value for sid=%s state=%s: %f", labels["sid"], labels["state"], getMetricValue(m))
+		}
+	}
+}
+
+func TestCollectHAMetrics_NilResponses(t *testing.T) {
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 10)
+
+	c.collectHAMetrics(ch, nil, nil)
+
+	if metrics := drainMetrics(ch); len(metrics) != 0 {
+		t.Errorf("expected no metrics, got %d", len(metrics))
+	}
+}
@@ -42,3 +42,9 @@ func getMetricLabels(m prometheus.Metric) map[string]string {
 	}
 	return labels
 }
+
+func getCounterValue(m prometheus.Metric) float64 {
+	var d dto.Metric
+	_ = m.Write(&d)
+	return d.GetCounter().GetValue()
+}
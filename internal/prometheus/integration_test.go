@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
 	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
 )
 
@@ -24,13 +28,59 @@ const intClusterResourcesJSON = `{
 	"data": [
 		{"id": "node/node1", "node": "node1", "type": "node", "status": "online", "maxcpu": 8, "maxmem": 16384000000},
 		{"id": "node/node2", "node": "node2", "type": "node", "status": "online", "maxcpu": 16, "maxmem": 32768000000},
-		{"id": "qemu/100", "node": "node1", "type": "qemu", "status": "running", "name": "web-server", "vmid": 100, "maxcpu": 4, "maxmem": 8589934592, "template": 0, "tags": "prod;web"},
+		{"id": "qemu/100", "node": "node1", "type": "qemu", "status": "running", "name": "web-server", "vmid": 100, "maxcpu": 4, "maxmem": 8589934592, "template": 0, "tags": "prod;web", "pool": "prod", "hastate": "started"},
 		{"id": "qemu/101", "node": "node2", "type": "qemu", "status": "stopped", "name": "db-server", "vmid": 101, "maxcpu": 8, "maxmem": 17179869184, "template": 0},
 		{"id": "qemu/900", "node": "node1", "type": "qemu", "status": "stopped", "name": "template-vm", "vmid": 900, "maxcpu": 2, "maxmem": 2147483648, "template": 1},
-		{"id": "lxc/200", "node": "node1", "type": "lxc", "status": "running", "name": "dns-server", "vmid": 200, "maxcpu": 1, "maxmem": 536870912, "tags": "infra"},
-		{"id": "storage/node1/local", "node": "node1", "type": "storage", "status": "available", "storage": "local", "plugintype": "dir", "shared": 0, "maxdisk": 100000000000, "disk": 30000000000},
-		{"id": "storage/node1/ceph", "node": "node1", "type": "storage", "status": "available", "storage": "ceph", "plugintype": "rbd", "shared": 1, "maxdisk": 500000000000, "disk": 200000000000},
-		{"id": "storage/node2/local", "node": "node2", "type": "storage", "status": "available", "storage": "local", "plugintype": "dir", "shared": 0, "maxdisk": 200000000000, "disk": 60000000000}
+		{"id": "lxc/200", "node": "node1", "type": "lxc", "status": "running", "name": "dns-server", "vmid": 200, "maxcpu": 1, "maxmem": 536870912, "tags": "infra", "lock": "backup"},
+		{"id": "storage/node1/local", "node": "node1", "type": "storage", "status": "available", "storage": "local", "plugintype": "dir", "shared": 0, "content": "iso,vztmpl,backup", "maxdisk": 100000000000, "disk": 30000000000},
+		{"id": "storage/node1/ceph", "node": "node1", "type": "storage", "status": "available", "storage": "ceph", "plugintype": "rbd", "shared": 1, "content": "images,rootdir", "maxdisk": 500000000000, "disk": 200000000000},
+		{"id": "storage/node2/local", "node": "node2", "type": "storage", "status": "available", "storage": "local", "plugintype": "dir", "shared": 0, "content": "iso,vztmpl,backup", "maxdisk": 200000000000, "disk": 60000000000},
+		{"id": "sdn/node1/vlanzone", "node": "node1", "type": "sdn", "status": "available", "sdn": "vlanzone"},
+		{"id": "sdn/node2/vlanzone", "node": "node2", "type": "sdn", "status": "pending", "sdn": "vlanzone"}
+	]
+}`
+
+const intSDNZonesJSON = `{
+	"data": [
+		{"zone": "vlanzone", "type": "vlan", "ipam": "pve"}
+	]
+}`
+
+const intSDNVnetsJSON = `{
+	"data": [
+		{"vnet": "vnet10", "zone": "vlanzone", "tag": 10, "alias": "servers", "type": "vnet"},
+		{"vnet": "vnet20", "zone": "vlanzone", "tag": 20, "type": "vnet"}
+	]
+}`
+
+const intHAStatusJSON = `{
+	"data": [
+		{"id": "quorum", "type": "quorum", "node": "node1", "status": "OK", "quorate": 1},
+		{"id": "master", "type": "master", "node": "node1", "status": "node1 (active, Mon Jan  1 00:00:00 2024)", "timestamp": 1704067200},
+		{"id": "lrm:node1", "type": "lrm", "node": "node1", "status": "node1 (active, Mon Jan  1 00:00:00 2024)", "timestamp": 1704067200},
+		{"id": "lrm:node2", "type": "lrm", "node": "node2", "status": "node2 (idle, Mon Jan  1 00:00:00 2024)", "timestamp": 1704067200},
+		{"id": "service:vm:100", "type": "service", "node": "node1", "sid": "vm:100", "crm_state": "started", "request_state": "started", "status": "vm:100 (node1, started)"}
+	]
+}`
+
+const intHAResourcesJSON = `{
+	"data": [
+		{"sid": "vm:100", "type": "vm", "state": "started", "group": "prod", "digest": "abc"}
+	]
+}`
+
+const intBackupJobsJSON = `{
+	"data": [
+		{"id": "backup-1a2b3c4d-0001", "type": "vzdump", "enabled": 1, "schedule": "sat 02:00", "storage": "pbs", "mode": "snapshot", "all": 1, "exclude": "101"},
+		{"id": "backup-1a2b3c4d-0002", "type": "vzdump", "enabled": 0, "schedule": "daily", "storage": "local", "mode": "stop", "vmid": "200"}
+	]
+}`
+
+const intBackupStorageContentJSON = `{
+	"data": [
+		{"volid": "local:backup/vzdump-qemu-100-2024_01_01-01_00_00.vma.zst", "content": "backup", "format": "vma.zst", "size": 2147483648, "ctime": 1704070800, "vmid": 100, "subtype": "qemu"},
+		{"volid": "local:backup/vzdump-qemu-100-2024_01_02-01_00_00.vma.zst", "content": "backup", "format": "vma.zst", "size": 2147483648, "ctime": 1704157200, "vmid": 100, "subtype": "qemu"},
+		{"volid": "local:backup/vzdump-lxc-200-2024_01_02-01_05_00.tar.zst", "content": "backup", "format": "tar.zst", "size": 536870912, "ctime": 1704157500, "vmid": 200, "subtype": "lxc"}
 	]
 }`
 
@@ -58,6 +108,125 @@ const intNodeDisksJSON = `{
 	]
 }`
 
+const intNVMeSMARTJSON = `{
+	"data": {"health": "PASSED", "type": "text", "text": "SMART/Health Information (NVMe Log 0x02)\nCritical Warning:                   0x00\nTemperature:                        38 Celsius\nPercentage Used:                    2%\nPower On Hours:                     12,345\nMedia and Data Integrity Errors:    0\n"}
+}`
+
+const intATASMARTJSON = `{
+	"data": {"health": "UNKNOWN", "type": "ata", "attributes": [
+		{"id": "  5", "name": "Reallocated_Sector_Ct", "value": 100, "worst": 100, "threshold": 10, "raw": "0", "normalized": 100, "flags": "PO--CK", "fail": "-"},
+		{"id": "  9", "name": "Power_On_Hours", "value": 80, "worst": 80, "threshold": 0, "raw": "17520", "normalized": 80, "flags": "-O--CK", "fail": "-"},
+		{"id": "194", "name": "Temperature_Celsius", "value": 65, "worst": 50, "threshold": 0, "raw": "35 (Min/Max 20/50)", "normalized": 65, "flags": "-O---K", "fail": "-"},
+		{"id": "197", "name": "Current_Pending_Sector", "value": 100, "worst": 100, "threshold": 0, "raw": "0", "normalized": 100, "flags": "-O--CK", "fail": "-"}
+	]}
+}`
+
+// intSMARTHandler returns NVMe SMART text for the NVMe disk and ATA SMART attributes for any other disk
+func intSMARTHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasPrefix(r.URL.Query().Get("disk"), "/dev/nvme") {
+			_, _ = fmt.Fprint(w, intNVMeSMARTJSON)
+			return
+		}
+		_, _ = fmt.Fprint(w, intATASMARTJSON)
+	}
+}
+
+const intZFSPoolsJSON = `{
+	"data": [
+		{"name": "rpool", "health": "ONLINE", "size": 996432412672, "alloc": 107374182400, "free": 889058230272, "frag": 12, "dedup": 1.00}
+	]
+}`
+
+const intZFSPoolDetailJSON = `{
+	"data": {
+		"name": "rpool", "state": "ONLINE", "errors": "No known data errors",
+		"children": [{"name": "rpool", "state": "ONLINE", "read": 0, "write": 0, "cksum": 0, "children": [
+			{"name": "mirror-0", "state": "ONLINE", "read": 0, "write": 0, "cksum": 0, "children": [
+				{"name": "nvme0n1p3", "state": "ONLINE", "read": 0, "write": 0, "cksum": 0, "leaf": 1},
+				{"name": "nvme1n1p3", "state": "ONLINE", "read": 0, "write": 0, "cksum": 0, "leaf": 1}
+			]}
+		]}]
+	}
+}`
+
+const intLVMThinJSON = `{
+	"data": [
+		{"lv": "data", "vg": "pve", "lv_size": 1884119105536, "used": 195571563154, "metadata_size": 1073741824, "metadata_used": 53687091}
+	]
+}`
+
+const intLVMJSON = `{
+	"data": {"leaf": 0, "children": [
+		{"name": "pve", "size": 1999844147200, "free": 17175674880, "lvcount": 3, "leaf": 0, "children": [
+			{"name": "/dev/nvme0n1p3", "size": 1999844147200, "free": 17175674880, "leaf": 1}
+		]}
+	]}
+}`
+
+const intAptUpdateJSON = `{
+	"data": [
+		{"Package": "pve-manager", "Title": "Proxmox Virtual Environment Management Tools", "Version": "8.1.4", "OldVersion": "8.1.3", "Origin": "Proxmox", "Priority": "optional", "Section": "admin", "Arch": "amd64"},
+		{"Package": "proxmox-kernel-6.5", "Title": "Latest Proxmox Kernel Image", "Version": "6.5.13-1", "OldVersion": "6.5.11-8", "Origin": "Proxmox", "Priority": "optional", "Section": "admin", "Arch": "all"},
+		{"Package": "openssl", "Title": "Secure Sockets Layer toolkit", "Version": "3.0.11-1~deb12u2", "OldVersion": "3.0.11-1~deb12u1", "Origin": "Debian", "Priority": "important", "Section": "utils", "Arch": "amd64"}
+	]
+}`
+
+const intAptVersionsJSON = `{
+	"data": [
+		{"Package": "proxmox-ve", "Title": "Proxmox Virtual Environment", "Version": "8.1.0", "CurrentState": "Installed", "RunningKernel": "6.5.11-8-pve", "ManagerVersion": "8.1.3"},
+		{"Package": "pve-manager", "Title": "Proxmox Virtual Environment Management Tools", "Version": "8.1.3", "CurrentState": "Installed"},
+		{"Package": "proxmox-kernel-helper", "Title": "Function for various kernel maintenance tasks.", "Version": "8.1.0", "CurrentState": "Installed"},
+		{"Package": "qemu-server", "Title": "Qemu Server Tools", "Version": "8.0.10", "CurrentState": "Installed"},
+		{"Package": "corosync", "Title": "cluster engine daemon and utilities", "Version": "3.1.7-pve3", "CurrentState": "Installed"},
+		{"Package": "ceph", "Title": "distributed storage and file system", "Version": "", "CurrentState": "NotInstalled"},
+		{"Package": "proxmox-kernel-6.5.11-8-pve-signed", "Title": "Proxmox Kernel Image (signed)", "Version": "6.5.11-8", "CurrentState": "Installed"},
+		{"Package": "proxmox-kernel-6.5.13-1-pve-signed", "Title": "Proxmox Kernel Image (signed)", "Version": "6.5.13-1", "CurrentState": "Installed"}
+	]
+}`
+
+const intServicesJSON = `{
+	"data": [
+		{"service": "pveproxy", "name": "pveproxy", "desc": "PVE API Proxy Server", "state": "running", "active-state": "active", "unit-state": "enabled"},
+		{"service": "pvestatd", "name": "pvestatd", "desc": "PVE Status Daemon", "state": "dead", "active-state": "failed", "unit-state": "enabled"},
+		{"service": "corosync", "name": "corosync", "desc": "Corosync Cluster Engine", "state": "running", "active-state": "active", "unit-state": "enabled"}
+	]
+}`
+
+const intNetworkJSON = `{
+	"data": [
+		{"iface": "eno1", "type": "eth", "active": 1, "exists": 1, "families": ["inet"], "method": "manual"},
+		{"iface": "eno2", "type": "eth", "exists": 1, "families": ["inet"], "method": "manual"},
+		{"iface": "bond0", "type": "bond", "active": 1, "autostart": 1, "slaves": "eno1 eno2", "bond_mode": "802.3ad", "families": ["inet"], "method": "manual"},
+		{"iface": "vmbr0", "type": "bridge", "active": 1, "autostart": 1, "bridge_ports": "bond0", "cidr": "10.0.0.1/24", "families": ["inet"], "method": "static"}
+	]
+}`
+
+// intTimeHandler returns the current time, with node2's clock running 10 seconds ahead
+func intTimeHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		now := time.Now().Unix()
+		if r.PathValue("node") == "node2" {
+			now += 10
+		}
+		_, _ = fmt.Fprintf(w, `{"data": {"timezone": "UTC", "time": %d, "localtime": %d}}`, now, now)
+	}
+}
+
+// intSubscriptionHandler returns an active subscription for node1, and no subscription for any other node
+func intSubscriptionHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.PathValue("node") == "node1" {
+			_, _ = fmt.Fprint(w, `{"data": {"status": "active", "level": "c", "productname": "Proxmox VE Community Subscription 1 CPU/year", "key": "pve1c-0123456789", "nextduedate": "2025-03-01", "checktime": 1704067200}}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"data": {"status": "notfound", "message": "There is no subscription key"}}`)
+	}
+}
+
 const intQemuSnapshotsJSON = `{
 	"data": [
 		{"name": "snap1", "snaptime": 1700000000, "description": "First snapshot", "vmstate": 1},
@@ -73,6 +242,49 @@ const intLxcSnapshotsJSON = `{
 	]
 }`
 
+const intNode1VzdumpTasksJSON = `{
+	"data": [
+		{"upid": "UPID:node1:00001234:00ABCDEF:65A0D000:vzdump::root@pam:", "node": "node1", "type": "vzdump", "id": "", "user": "root@pam", "starttime": 1705000000},
+		{"upid": "UPID:node1:00001111:00AAAAAA:6597C500:vzdump::root@pam:", "node": "node1", "type": "vzdump", "id": "", "user": "root@pam", "starttime": 1704445200, "endtime": 1704445500, "status": "job errors"},
+		{"upid": "UPID:node1:00002222:00BBBBBB:65960000:vzdump:101:root@pam:", "node": "node1", "type": "vzdump", "id": "101", "user": "root@pam", "starttime": 1704329216, "endtime": 1704329336, "status": "OK"}
+	]
+}`
+
+const intVzdumpTaskLogJSON = `{
+	"data": [
+		{"n": 1, "t": "INFO: starting new backup job: vzdump --all 1 --mode snapshot --storage pbs"},
+		{"n": 2, "t": "INFO: Starting Backup of VM 100 (qemu)"},
+		{"n": 3, "t": "INFO: Finished Backup of VM 100 (00:04:05)"},
+		{"n": 4, "t": "INFO: Starting Backup of VM 200 (lxc)"},
+		{"n": 5, "t": "ERROR: Backup of VM 200 failed - unable to open file"},
+		{"n": 6, "t": "INFO: Backup job finished with errors"}
+	]
+}`
+
+// intVzdumpTasksHandler only returns vzdump tasks for node1, so task results aren't counted for every node
+func intVzdumpTasksHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.PathValue("node") != "node1" {
+			_, _ = fmt.Fprint(w, `{"data": []}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, intNode1VzdumpTasksJSON)
+	}
+}
+
+// intReplicationHandler only returns a replication job for node1, which replicates VM 100 to node2
+func intReplicationHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.PathValue("node") != "node1" {
+			_, _ = fmt.Fprint(w, `{"data": []}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"data": [{"id": "100-0", "guest": 100, "jobnum": 0, "target": "node2", "type": "local", "schedule": "*/15", "last_sync": 1704067200, "last_try": 1704067200, "next_sync": 1704068100, "duration": 3.52, "fail_count": 0, "vmtype": "qemu"}]}`)
+	}
+}
+
 func intCertHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		thirtyDays := time.Now().Add(30 * 24 * time.Hour).Unix()
@@ -94,15 +306,108 @@ func intJSONHandler(body string) http.HandlerFunc {
 	}
 }
 
+const intFirewallRulesJSON = `{
+	"data": [
+		{"pos": 0, "type": "in", "action": "ACCEPT", "enable": 1, "proto": "tcp", "dport": "22"},
+		{"pos": 1, "type": "group", "action": "webservers", "enable": 1},
+		{"pos": 2, "type": "in", "action": "ACCEPT", "proto": "tcp", "dport": "8006"}
+	]
+}`
+
+// intGuestFirewallOptionsHandler enables the firewall of every guest but the db-server VM (101)
+func intGuestFirewallOptionsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.PathValue("vmid") == "101" {
+			_, _ = fmt.Fprint(w, `{"data": {"policy_in": "ACCEPT"}}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"data": {"enable": 1, "policy_in": "DROP", "policy_out": "REJECT"}}`)
+	}
+}
+
+const intAgentFsInfoJSON = `{
+	"data": {
+		"result": [
+			{"name": "sda1", "mountpoint": "/", "type": "ext4", "total-bytes": 33501757440, "used-bytes": 8964567040},
+			{"name": "sda1", "mountpoint": "/", "type": "ext4", "total-bytes": 33501757440, "used-bytes": 8964567040},
+			{"name": "sda15", "mountpoint": "/boot/efi", "type": "vfat", "total-bytes": 124603392, "used-bytes": 6164480},
+			{"name": "sr0", "mountpoint": "/media/cdrom", "type": "iso9660"}
+		]
+	}
+}`
+
+const intAgentNetworkInterfacesJSON = `{
+	"data": {
+		"result": [
+			{"name": "lo", "hardware-address": "00:00:00:00:00:00", "ip-addresses": [
+				{"ip-address": "127.0.0.1", "ip-address-type": "ipv4", "prefix": 8},
+				{"ip-address": "::1", "ip-address-type": "ipv6", "prefix": 128}
+			]},
+			{"name": "ens18", "hardware-address": "bc:24:11:5e:8a:01", "ip-addresses": [
+				{"ip-address": "192.168.1.100", "ip-address-type": "ipv4", "prefix": 24},
+				{"ip-address": "2001:db8::100", "ip-address-type": "ipv6", "prefix": 64},
+				{"ip-address": "fe80::be24:11ff:fe5e:8a01", "ip-address-type": "ipv6", "prefix": 64}
+			]}
+		]
+	}
+}`
+
+// intQemuConfigHandler returns a VM configured to start on boot with the guest agent for web-server (100), and one that isn't for db-server (101)
+func intQemuConfigHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.PathValue("vmid") == "100" {
+			_, _ = fmt.Fprint(w, `{"data": {"name": "web-server", "onboot": 1, "agent": "1,fstrim_cloned_disks=1", "balloon": 2048, "memory": "8192", "sockets": 1, "cores": 4, "cpu": "x86-64-v2-AES", "machine": "q35", "ostype": "l26"}}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"data": {"name": "db-server", "agent": "enabled=0", "memory": "16384", "cores": 8, "cpu": "host,flags=+aes", "ostype": "win11"}}`)
+	}
+}
+
 func setupIntegrationMux(withSnapshots bool) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/api2/json/cluster/status", intJSONHandler(intClusterStatusJSON))
 	mux.HandleFunc("/api2/json/cluster/resources", intJSONHandler(intClusterResourcesJSON))
+	mux.HandleFunc("/api2/json/cluster/ha/status/current", intJSONHandler(intHAStatusJSON))
+	mux.HandleFunc("/api2/json/cluster/ha/resources", intJSONHandler(intHAResourcesJSON))
+	mux.HandleFunc("/api2/json/cluster/backup-info/not-backed-up", intJSONHandler(`{"data": [{"vmid": 101, "type": "qemu", "name": "db-server"}]}`))
+	mux.HandleFunc("/api2/json/cluster/backup", intJSONHandler(intBackupJobsJSON))
+	mux.HandleFunc("/api2/json/cluster/sdn/zones", intJSONHandler(intSDNZonesJSON))
+	mux.HandleFunc("/api2/json/cluster/sdn/vnets", intJSONHandler(intSDNVnetsJSON))
+	mux.HandleFunc("/api2/json/cluster/firewall/options", intJSONHandler(`{"data": {"enable": 1, "policy_in": "REJECT"}}`))
+	mux.HandleFunc("/api2/json/cluster/firewall/rules", intJSONHandler(intFirewallRulesJSON))
 	mux.HandleFunc("/api2/json/nodes", intJSONHandler(`{"data": [{"node": "node1", "status": "online"}, {"node": "node2", "status": "online"}]}`))
 	mux.HandleFunc("/api2/json/nodes/{node}/status", intJSONHandler(intNodeStatusJSON))
 	mux.HandleFunc("/api2/json/nodes/{node}/disks/list", intJSONHandler(intNodeDisksJSON))
 	mux.HandleFunc("/api2/json/nodes/{node}/certificates/info", intCertHandler())
+	mux.HandleFunc("/api2/json/nodes/{node}/disks/smart", intSMARTHandler())
+	mux.HandleFunc("/api2/json/nodes/{node}/disks/zfs", intJSONHandler(intZFSPoolsJSON))
+	mux.HandleFunc("/api2/json/nodes/{node}/disks/zfs/{pool}", intJSONHandler(intZFSPoolDetailJSON))
+	mux.HandleFunc("/api2/json/nodes/{node}/disks/lvmthin", intJSONHandler(intLVMThinJSON))
+	mux.HandleFunc("/api2/json/nodes/{node}/disks/lvm", intJSONHandler(intLVMJSON))
+	mux.HandleFunc("/api2/json/nodes/{node}/apt/update", intJSONHandler(intAptUpdateJSON))
+	mux.HandleFunc("/api2/json/nodes/{node}/apt/versions", intJSONHandler(intAptVersionsJSON))
+	mux.HandleFunc("/api2/json/nodes/{node}/time", intTimeHandler())
+	mux.HandleFunc("/api2/json/nodes/{node}/network", intJSONHandler(intNetworkJSON))
+	mux.HandleFunc("/api2/json/nodes/{node}/firewall/options", intJSONHandler(`{"data": {}}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/firewall/rules", intJSONHandler(intFirewallRulesJSON))
+	mux.HandleFunc("/api2/json/nodes/{node}/{type}/{vmid}/firewall/options", intGuestFirewallOptionsHandler())
+	mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/config", intQemuConfigHandler())
+	mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/agent/ping", intJSONHandler(`{"data": {"result": {}}}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/agent/get-fsinfo", intJSONHandler(intAgentFsInfoJSON))
+	mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/agent/get-osinfo", intJSONHandler(`{"data": {"result": {"id": "debian", "name": "Debian GNU/Linux", "pretty-name": "Debian GNU/Linux 12 (bookworm)", "version": "12 (bookworm)", "version-id": "12", "kernel-release": "6.1.0-18-amd64", "kernel-version": "#1 SMP PREEMPT_DYNAMIC Debian 6.1.76-1", "machine": "x86_64"}}}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/agent/network-get-interfaces", intJSONHandler(intAgentNetworkInterfacesJSON))
+	mux.HandleFunc("/api2/json/nodes/{node}/lxc/{vmid}/interfaces", intJSONHandler(`{"data": [{"name": "lo", "hwaddr": "00:00:00:00:00:00", "inet": "127.0.0.1/8", "inet6": "::1/128"}, {"name": "eth0", "hwaddr": "bc:24:11:2f:4c:02", "inet": "192.168.1.53/24", "inet6": "fe80::be24:11ff:fe2f:4c02/64"}]}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/lxc/{vmid}/config", intJSONHandler(`{"data": {"hostname": "dns-server", "onboot": 1, "protection": 1, "cores": 1, "memory": 512, "ostype": "debian", "arch": "amd64", "unprivileged": 1}}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/{type}/{vmid}/firewall/rules", intJSONHandler(intFirewallRulesJSON))
+	mux.HandleFunc("/api2/json/nodes/{node}/services", intJSONHandler(intServicesJSON))
+	mux.HandleFunc("/api2/json/nodes/{node}/subscription", intSubscriptionHandler())
+	mux.HandleFunc("/api2/json/nodes/{node}/replication", intReplicationHandler())
+	mux.HandleFunc("/api2/json/nodes/{node}/storage/{storage}/content", intJSONHandler(intBackupStorageContentJSON))
+	mux.HandleFunc("/api2/json/nodes/{node}/tasks", intVzdumpTasksHandler())
+	mux.HandleFunc("/api2/json/nodes/{node}/tasks/{upid}/log", intJSONHandler(intVzdumpTaskLogJSON))
 
 	if withSnapshots {
 		mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/snapshot", intJSONHandler(intQemuSnapshotsJSON))
@@ -177,6 +482,31 @@ func TestCollect_Integration(t *testing.T) {
 		t.Errorf("clientCount: expected 2, got %d", n)
 	}
 
+	// Cluster status: quorate, expected nodes and online nodes once each, node info per member
+	if n := countByDesc(metrics, c.clusterQuorate); n != 1 {
+		t.Errorf("clusterQuorate: expected 1, got %d", n)
+	}
+	if n := countByDesc(metrics, c.clusterNodesOnline); n != 1 {
+		t.Errorf("clusterNodesOnline: expected 1, got %d", n)
+	}
+	if n := countByDesc(metrics, c.clusterNodeInfo); n != 2 {
+		t.Errorf("clusterNodeInfo: expected 2, got %d", n)
+	}
+
+	// HA: 1 master, LRM stateset for 2 nodes, 1 resource with current and requested statesets
+	if n := countByDesc(metrics, c.haMasterInfo); n != 1 {
+		t.Errorf("haMasterInfo: expected 1, got %d", n)
+	}
+	if n := countByDesc(metrics, c.haLRMState); n != 2*len(haLRMStates) {
+		t.Errorf("haLRMState: expected %d, got %d", 2*len(haLRMStates), n)
+	}
+	if n := countByDesc(metrics, c.haResourceState); n != len(haServiceStates) {
+		t.Errorf("haResourceState: expected %d, got %d", len(haServiceStates), n)
+	}
+	if n := countByDesc(metrics, c.haResourceRequestedState); n != len(haRequestedStates) {
+		t.Errorf("haResourceRequestedState: expected %d, got %d", len(haRequestedStates), n)
+	}
+
 	// Node up: 2 (node1, node2)
 	if n := countByDesc(metrics, c.nodeUp); n != 2 {
 		t.Errorf("nodeUp: expected 2, got %d", n)
@@ -197,6 +527,16 @@ func TestCollect_Integration(t *testing.T) {
 		t.Errorf("guestUp: expected 3, got %d", n)
 	}
 
+	// Guest info: 3 (same guests as guest up)
+	if n := countByDesc(metrics, c.guestInfo); n != 3 {
+		t.Errorf("guestInfo: expected 3, got %d", n)
+	}
+
+	// Guest lock: 1 (only the LXC is locked)
+	if n := countByDesc(metrics, c.guestLock); n != 1 {
+		t.Errorf("guestLock: expected 1, got %d", n)
+	}
+
 	// Node CPUs allocated: 2 (node1 and node2 both have guests)
 	if n := countByDesc(metrics, c.nodeCPUsAlloc); n != 2 {
 		t.Errorf("nodeCPUsAlloc: expected 2, got %d", n)
@@ -207,6 +547,289 @@ func TestCollect_Integration(t *testing.T) {
 		t.Errorf("nodeMemAlloc: expected 2, got %d", n)
 	}
 
+	// Pool metrics: 5 (1 pool with a single VM)
+	poolCPUs := findByDesc(metrics, c.poolCPUsAlloc)
+	if len(poolCPUs) != 1 {
+		t.Errorf("poolCPUsAlloc: expected 1, got %d", len(poolCPUs))
+	} else if v := getMetricValue(poolCPUs[0]); v != 4.0 {
+		t.Errorf("pool CPUs allocated: expected 4, got %f", v)
+	}
+	if n := countByDesc(metrics, c.poolGuestsRunning); n != 1 {
+		t.Errorf("poolGuestsRunning: expected 1, got %d", n)
+	}
+
+	// Backup coverage: 4 guests (templates included by default), 1 not covered
+	if n := countByDesc(metrics, c.guestBackupCovered); n != 4 {
+		t.Errorf("guestBackupCovered: expected 4, got %d", n)
+	}
+	notBackedUp := findByDesc(metrics, c.clusterGuestsNotBackedUp)
+	if len(notBackedUp) != 1 {
+		t.Errorf("clusterGuestsNotBackedUp: expected 1, got %d", len(notBackedUp))
+	} else if v := getMetricValue(notBackedUp[0]); v != 1.0 {
+		t.Errorf("guests not backed up: expected 1, got %f", v)
+	}
+
+	// Backup history: VM 100 succeeded in a backup job and VM 101 in a single guest task, LXC 200 failed
+	if n := countByDesc(metrics, c.guestLastBackupSuccess); n != 2 {
+		t.Errorf("guestLastBackupSuccess: expected 2, got %d", n)
+	}
+	if n := countByDesc(metrics, c.guestLastBackupDuration); n != 2 {
+		t.Errorf("guestLastBackupDuration: expected 2, got %d", n)
+	}
+	if n := countByDesc(metrics, c.guestBackupFailures); n != 3 {
+		t.Errorf("guestBackupFailures: expected 3, got %d", n)
+	}
+
+	// Backup jobs: info and enabled state for both jobs, next run only for the enabled one
+	if n := countByDesc(metrics, c.backupJobInfo); n != 2 {
+		t.Errorf("backupJobInfo: expected 2, got %d", n)
+	}
+	if n := countByDesc(metrics, c.backupJobEnabled); n != 2 {
+		t.Errorf("backupJobEnabled: expected 2, got %d", n)
+	}
+	if n := countByDesc(metrics, c.backupJobNextRun); n != 1 {
+		t.Errorf("backupJobNextRun: expected 1, got %d", n)
+	}
+
+	// Backup storage content: 2 guests on each node's local storage, ceph can't hold backups
+	if n := countByDesc(metrics, c.guestBackups); n != 4 {
+		t.Errorf("guestBackups: expected 4, got %d", n)
+	}
+	if n := countByDesc(metrics, c.guestBackupNewest); n != 4 {
+		t.Errorf("guestBackupNewest: expected 4, got %d", n)
+	}
+	if n := countByDesc(metrics, c.guestBackupOldest); n != 4 {
+		t.Errorf("guestBackupOldest: expected 4, got %d", n)
+	}
+	if n := countByDesc(metrics, c.guestBackupSize); n != 4 {
+		t.Errorf("guestBackupSize: expected 4, got %d", n)
+	}
+
+	// Replication: 1 job on node1 with all 5 metrics
+	if n := countByDesc(metrics, c.replicationLastSync); n != 1 {
+		t.Errorf("replicationLastSync: expected 1, got %d", n)
+	}
+	if n := countByDesc(metrics, c.replicationError); n != 1 {
+		t.Errorf("replicationError: expected 1, got %d", n)
+	}
+
+	// ZFS: 1 pool per node with a health stateset and 5 usage metrics, 4 vdevs (pool root, mirror and 2 devices) with a state stateset and 3 error counts
+	if n := countByDesc(metrics, c.zfsPoolHealth); n != 2*len(zfsStates) {
+		t.Errorf("zfsPoolHealth: expected %d, got %d", 2*len(zfsStates), n)
+	}
+	if n := countByDesc(metrics, c.zfsPoolSize); n != 2 {
+		t.Errorf("zfsPoolSize: expected 2, got %d", n)
+	}
+	if n := countByDesc(metrics, c.zfsVdevState); n != 2*4*len(zfsStates) {
+		t.Errorf("zfsVdevState: expected %d, got %d", 2*4*len(zfsStates), n)
+	}
+	if n := countByDesc(metrics, c.zfsVdevChecksumErrors); n != 2*4 {
+		t.Errorf("zfsVdevChecksumErrors: expected 8, got %d", n)
+	}
+
+	// SMART: the NVMe disk reports temperature, power on hours, media errors and percentage used, the ATA disk reports temperature, power on hours, reallocated and pending sectors
+	if n := countByDesc(metrics, c.diskTemperature); n != 4 {
+		t.Errorf("diskTemperature: expected 4, got %d", n)
+	}
+	if n := countByDesc(metrics, c.diskMediaErrors); n != 2 {
+		t.Errorf("diskMediaErrors: expected 2, got %d", n)
+	}
+	if n := countByDesc(metrics, c.diskPendingSectors); n != 2 {
+		t.Errorf("diskPendingSectors: expected 2, got %d", n)
+	}
+
+	// Disk inventory: 2 disks per node, only the NVMe disk reports its wearout
+	if n := countByDesc(metrics, c.diskInfo); n != 4 {
+		t.Errorf("diskInfo: expected 4, got %d", n)
+	}
+	if n := countByDesc(metrics, c.diskSize); n != 4 {
+		t.Errorf("diskSize: expected 4, got %d", n)
+	}
+	if n := countByDesc(metrics, c.diskWearout); n != 2 {
+		t.Errorf("diskWearout: expected 2, got %d", n)
+	}
+
+	// Subscription: a stateset and info metric per node, and due and check times for node1's subscription
+	if n := countByDesc(metrics, c.subscriptionStatus); n != 2*len(subscriptionStates) {
+		t.Errorf("subscriptionStatus: expected %d, got %d", 2*len(subscriptionStates), n)
+	}
+	if n := countByDesc(metrics, c.subscriptionNextDue); n != 1 {
+		t.Errorf("subscriptionNextDue: expected 1, got %d", n)
+	}
+
+	// Packages: updates from 2 origins per node, and both nodes have a newer kernel installed than they're running
+	if n := countByDesc(metrics, c.updatesPending); n != 4 {
+		t.Errorf("updatesPending: expected 4, got %d", n)
+	}
+	for _, m := range findByDesc(metrics, c.rebootRequired) {
+		if v := getMetricValue(m); v != 1 {
+			t.Errorf("rebootRequired: expected 1, got %f", v)
+		}
+	}
+
+	// Package versions: qemu-server, corosync and the running kernel per node, which both nodes agree on
+	if n := countByDesc(metrics, c.packageVersionInfo); n != 6 {
+		t.Errorf("packageVersionInfo: expected 6, got %d", n)
+	}
+	skew := findByDesc(metrics, c.packageVersionSkew)
+	if len(skew) != 3 {
+		t.Errorf("packageVersionSkew: expected 3, got %d", len(skew))
+	}
+	for _, m := range skew {
+		if v := getMetricValue(m); v != 1 {
+			t.Errorf("packageVersionSkew %s: expected 1, got %f", getMetricLabels(m)["package"], v)
+		}
+	}
+
+	// Services: 3 services per node, each with a state and unit state stateset
+	if n := countByDesc(metrics, c.serviceState); n != 2*3*len(serviceStates) {
+		t.Errorf("serviceState: expected %d, got %d", 2*3*len(serviceStates), n)
+	}
+	if n := countByDesc(metrics, c.serviceUnitState); n != 2*3*len(serviceUnitStates) {
+		t.Errorf("serviceUnitState: expected %d, got %d", 2*3*len(serviceUnitStates), n)
+	}
+
+	// Time: an offset per node, and the skew between node1 and node2's clocks
+	if n := countByDesc(metrics, c.nodeTimeOffset); n != 2 {
+		t.Errorf("nodeTimeOffset: expected 2, got %d", n)
+	}
+	skews := findByDesc(metrics, c.clusterTimeSkew)
+	if len(skews) != 1 {
+		t.Fatalf("clusterTimeSkew: expected 1, got %d", len(skews))
+	}
+	if v := getMetricValue(skews[0]); v < 9 || v > 11 {
+		t.Errorf("clusterTimeSkew: expected about 10, got %f", v)
+	}
+
+	// Network: 4 interfaces per node, with one of bond0's 2 slaves inactive, and no pending changes
+	if n := countByDesc(metrics, c.networkInterfaceInfo); n != 8 {
+		t.Errorf("networkInterfaceInfo: expected 8, got %d", n)
+	}
+	if n := countByDesc(metrics, c.networkBondSlaveActive); n != 4 {
+		t.Errorf("networkBondSlaveActive: expected 4, got %d", n)
+	}
+	for _, m := range findByDesc(metrics, c.networkChangesPending) {
+		if v := getMetricValue(m); v != 0 {
+			t.Errorf("networkChangesPending: expected 0, got %f", v)
+		}
+	}
+
+	// SDN: 1 zone, available on node1 and pending on node2, with 2 vnets
+	for _, m := range findByDesc(metrics, c.sdnZoneStatus) {
+		labels := getMetricLabels(m)
+		expected := 0.0
+		if (labels["node"] == "node1" && labels["status"] == "available") || (labels["node"] == "node2" && labels["status"] == "pending") {
+			expected = 1.0
+		}
+		if v := getMetricValue(m); v != expected {
+			t.Errorf("sdnZoneStatus %v: expected %f, got %f", labels, expected, v)
+		}
+	}
+	if n := countByDesc(metrics, c.sdnZoneInfo); n != 1 {
+		t.Errorf("sdnZoneInfo: expected 1, got %d", n)
+	}
+	if n := countByDesc(metrics, c.sdnVnetInfo); n != 2 {
+		t.Errorf("sdnVnetInfo: expected 2, got %d", n)
+	}
+
+	// Firewall: enabled for the cluster, both nodes and every guest but VM 101, with 2 enabled and 1 disabled rule each
+	enabledFirewalls := findByDesc(metrics, c.clusterFirewallEnabled)
+	if len(enabledFirewalls) != 1 || getMetricValue(enabledFirewalls[0]) != 1 {
+		t.Errorf("clusterFirewallEnabled: expected a single enabled firewall, got %v", enabledFirewalls)
+	}
+	for _, m := range findByDesc(metrics, c.nodeFirewallEnabled) {
+		if v := getMetricValue(m); v != 1 {
+			t.Errorf("nodeFirewallEnabled %v: expected 1, got %f", getMetricLabels(m), v)
+		}
+	}
+	guestFirewalls := findByDesc(metrics, c.guestFirewallEnabled)
+	if len(guestFirewalls) != 3 {
+		t.Errorf("guestFirewallEnabled: expected 3, got %d", len(guestFirewalls))
+	}
+	for _, m := range guestFirewalls {
+		labels := getMetricLabels(m)
+		expected := 1.0
+		if labels["vmid"] == "101" {
+			expected = 0.0
+		}
+		if v := getMetricValue(m); v != expected {
+			t.Errorf("guestFirewallEnabled %v: expected %f, got %f", labels, expected, v)
+		}
+	}
+	for _, m := range findByDesc(metrics, c.guestFirewallPolicy) {
+		labels := getMetricLabels(m)
+		expected := map[string]string{"in": "DROP", "out": "REJECT"}
+		if labels["vmid"] == "101" {
+			expected = map[string]string{"in": "ACCEPT", "out": "ACCEPT"}
+		}
+		want := 0.0
+		if expected[labels["direction"]] == labels["policy"] {
+			want = 1.0
+		}
+		if v := getMetricValue(m); v != want {
+			t.Errorf("guestFirewallPolicy %v: expected %f, got %f", labels, want, v)
+		}
+	}
+	for _, m := range findByDesc(metrics, c.guestFirewallRules) {
+		labels := getMetricLabels(m)
+		expected := 2.0
+		if labels["enabled"] == "false" {
+			expected = 1.0
+		}
+		if v := getMetricValue(m); v != expected {
+			t.Errorf("guestFirewallRules %v: expected %f, got %f", labels, expected, v)
+		}
+	}
+	if n := countByDesc(metrics, c.nodeFirewallRules); n != 4 {
+		t.Errorf("nodeFirewallRules: expected 4, got %d", n)
+	}
+
+	// Guest configuration: web-server and dns-server start on boot, and only web-server has the guest agent enabled
+	for _, m := range findByDesc(metrics, c.guestOnBoot) {
+		labels := getMetricLabels(m)
+		expected := 1.0
+		if labels["vmid"] == "101" {
+			expected = 0.0
+		}
+		if v := getMetricValue(m); v != expected {
+			t.Errorf("guestOnBoot %v: expected %f, got %f", labels, expected, v)
+		}
+	}
+	agents := findByDesc(metrics, c.guestAgentEnabled)
+	if len(agents) != 2 {
+		t.Errorf("guestAgentEnabled: expected 2, got %d", len(agents))
+	}
+	for _, m := range agents {
+		labels := getMetricLabels(m)
+		expected := 0.0
+		if labels["vmid"] == "100" {
+			expected = 1.0
+		}
+		if v := getMetricValue(m); v != expected {
+			t.Errorf("guestAgentEnabled %v: expected %f, got %f", labels, expected, v)
+		}
+	}
+	if n := countByDesc(metrics, c.guestBalloonMin); n != 1 {
+		t.Errorf("guestBalloonMin: expected 1, got %d", n)
+	}
+	if n := countByDesc(metrics, c.guestCPUCores); n != 3 {
+		t.Errorf("guestCPUCores: expected 3, got %d", n)
+	}
+	for _, m := range findByDesc(metrics, c.guestConfigInfo) {
+		labels := getMetricLabels(m)
+		if labels["vmid"] == "101" && labels["cpu_type"] != "host" {
+			t.Errorf("guestConfigInfo: expected cpu_type host for VM 101, got %v", labels)
+		}
+	}
+
+	// LVM: 1 thin pool and 1 volume group per node
+	if n := countByDesc(metrics, c.lvmThinMetadataUsed); n != 2 {
+		t.Errorf("lvmThinMetadataUsed: expected 2, got %d", n)
+	}
+	if n := countByDesc(metrics, c.lvmVGFree); n != 2 {
+		t.Errorf("lvmVGFree: expected 2, got %d", n)
+	}
+
 	// Storage total: 3 entries
 	if n := countByDesc(metrics, c.storageTotal); n != 3 {
 		t.Errorf("storageTotal: expected 3, got %d", n)
@@ -362,8 +985,17 @@ func TestCollect_Integration(t *testing.T) {
 		}
 	}
 
+	// Guest IPs: only the running LXC's eth0 IPv4 address, since loopback and link-local addresses are left out
+	ips := findByDesc(metrics, c.guestIPInfo)
+	if len(ips) != 1 {
+		t.Fatalf("guestIPInfo: expected 1, got %d", len(ips))
+	}
+	if labels := getMetricLabels(ips[0]); labels["vmid"] != "200" || labels["interface"] != "eth0" || labels["address"] != "192.168.1.53" || labels["family"] != "ipv4" {
+		t.Errorf("guestIPInfo: unexpected labels %v", labels)
+	}
+
 	// Total metric count
-	expectedTotal := 35
+	expectedTotal := 248 + 2*3*(len(serviceStates)+len(serviceUnitStates)) + 2*len(subscriptionStates) + 2*(len(zfsStates)+5) + 2*4*(len(zfsStates)+3) + 2*len(sdnZoneStates) + 8*len(firewallPolicies)
 	if len(metrics) != expectedTotal {
 		t.Errorf("total metrics: expected %d, got %d", expectedTotal, len(metrics))
 	}
@@ -432,13 +1064,86 @@ func TestCollect_WithSnapshots_Integration(t *testing.T) {
 		}
 	}
 
-	// Total metric count with snapshots: 35 base + 3 snapshot counts + 5 snapshot ages = 43
-	expectedTotal := 43
+	// Total metric count with snapshots: base + 3 snapshot counts + 5 snapshot ages
+	expectedTotal := 256 + 2*3*(len(serviceStates)+len(serviceUnitStates)) + 2*len(subscriptionStates) + 2*(len(zfsStates)+5) + 2*4*(len(zfsStates)+3) + 2*len(sdnZoneStates) + 8*len(firewallPolicies)
 	if len(metrics) != expectedTotal {
 		t.Errorf("total metrics with snapshots: expected %d, got %d", expectedTotal, len(metrics))
 	}
 }
 
+func TestCollect_WithGuestAgent_Integration(t *testing.T) {
+	oldCfg := cfg
+	cfg = Config{EnableGuestAgentMetrics: true}
+	defer func() { cfg = oldCfg }()
+
+	mux := setupIntegrationMux(false)
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	http.DefaultTransport = &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	defer func() {
+		http.DefaultTransport = &http.Transport{}
+	}()
+
+	initProxmoxForIntegration(t, server.URL)
+
+	c := NewCollector()
+	ch := make(chan prometheus.Metric, 1000)
+	c.Collect(ch)
+	metrics := drainMetrics(ch)
+
+	// Only web-server (100) is running with the guest agent enabled
+	agents := findByDesc(metrics, c.guestAgentUp)
+	if len(agents) != 1 {
+		t.Fatalf("guestAgentUp: expected 1, got %d", len(agents))
+	}
+	if labels := getMetricLabels(agents[0]); labels["vmid"] != "100" || getMetricValue(agents[0]) != 1 {
+		t.Errorf("guestAgentUp: expected VM 100 to be up, got %v", labels)
+	}
+
+	// The root filesystem is listed twice and the cdrom has no usage, leaving 2 filesystems
+	if n := countByDesc(metrics, c.guestFilesystemSize); n != 2 {
+		t.Errorf("guestFilesystemSize: expected 2, got %d", n)
+	}
+	for _, m := range findByDesc(metrics, c.guestFilesystemUsed) {
+		labels := getMetricLabels(m)
+		if labels["mountpoint"] == "/" && getMetricValue(m) != 8964567040 {
+			t.Errorf("guestFilesystemUsed: expected 8964567040 for /, got %f", getMetricValue(m))
+		}
+	}
+
+	// The OS of web-server (100) is reported by its agent
+	osInfo := findByDesc(metrics, c.guestOSInfo)
+	if len(osInfo) != 1 {
+		t.Fatalf("guestOSInfo: expected 1, got %d", len(osInfo))
+	}
+	if labels := getMetricLabels(osInfo[0]); labels["distro"] != "debian" || labels["version"] != "12" || labels["kernel"] != "6.1.0-18-amd64" {
+		t.Errorf("guestOSInfo: unexpected labels %v", labels)
+	}
+
+	// web-server's ens18 has an IPv4 and a global IPv6 address, in addition to the LXC's address
+	vmIPs := 0
+	for _, m := range findByDesc(metrics, c.guestIPInfo) {
+		if labels := getMetricLabels(m); labels["vmid"] == "100" {
+			vmIPs++
+			if labels["interface"] != "ens18" {
+				t.Errorf("guestIPInfo: unexpected interface %s", labels["interface"])
+			}
+		}
+	}
+	if vmIPs != 2 {
+		t.Errorf("guestIPInfo: expected 2 addresses for VM 100, got %d", vmIPs)
+	}
+
+	// Total metric count with guest agent metrics: base + 1 agent up + 2 filesystem sizes + 2 filesystem usages + 1 OS + 2 VM addresses
+	expectedTotal := 256 + 2*3*(len(serviceStates)+len(serviceUnitStates)) + 2*len(subscriptionStates) + 2*(len(zfsStates)+5) + 2*4*(len(zfsStates)+3) + 2*len(sdnZoneStates) + 8*len(firewallPolicies)
+	if len(metrics) != expectedTotal {
+		t.Errorf("total metrics with guest agent: expected %d, got %d", expectedTotal, len(metrics))
+	}
+}
+
 func TestCollect_Integration_ClusterLabel(t *testing.T) {
 	oldCfg := cfg
 	cfg = Config{EnableSnapshotMetrics: false}
@@ -597,3 +1302,363 @@ func TestCollect_Integration_StorageLabels(t *testing.T) {
 		t.Error("local storage on node1 not found")
 	}
 }
+
+func TestCollectGuestAgentMetrics_HungAgents_Integration(t *testing.T) {
+	oldCfg := cfg
+	cfg = Config{EnableGuestAgentMetrics: true, GuestAgentDeadline: 150 * time.Millisecond}
+	defer func() { cfg = oldCfg }()
+
+	// VM 100's agent responds, while the agents of 10 more VMs hang, more than may be queried at once
+	var pings atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/cluster/status", intJSONHandler(`{"data": []}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/config", intJSONHandler(`{"data": {"agent": "1"}}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/agent/ping", func(w http.ResponseWriter, r *http.Request) {
+		pings.Add(1)
+		if r.PathValue("vmid") != "100" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"data": {"result": {}}}`)
+	})
+	mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/agent/get-fsinfo", intJSONHandler(`{"data": {"result": []}}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/agent/get-osinfo", intJSONHandler(`{"data": {"result": {}}}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/agent/network-get-interfaces", intJSONHandler(`{"data": {"result": []}}`))
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	http.DefaultTransport = &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	defer func() {
+		http.DefaultTransport = &http.Transport{}
+	}()
+
+	initProxmoxForIntegration(t, server.URL)
+	wrappedProxmox.SetGuestAgentLimits(100*time.Millisecond, 2)
+	t.Cleanup(func() { wrappedProxmox.SetGuestAgentLimits(5*time.Second, 4) })
+
+	var guests []proxmox.GetClusterResourcesData
+	for vmID := 100; vmID <= 110; vmID++ {
+		vmid := proxmox.IntOrString(strconv.Itoa(vmID))
+		guests = append(guests, proxmox.GetClusterResourcesData{Node: "node1", Type: "qemu", Status: "running", VMID: &vmid})
+	}
+
+	c := testCollector()
+	collect := func() []prometheus.Metric {
+		ch := make(chan prometheus.Metric, 1000)
+		c.collectGuestAgentMetrics(ch, "node1", guests)
+		return drainMetrics(ch)
+	}
+
+	// Querying every agent takes about 600ms, but the scrape only waits for the deadline
+	start := time.Now()
+	collect()
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("expected the scrape to stop waiting after the 150ms deadline, took %s", elapsed)
+	}
+
+	// A scrape while the earlier queries are still in flight doesn't query the same agents again
+	collect()
+
+	waitStart := time.Now()
+	for {
+		inFlight := 0
+		c.guestAgentsInFlight.Range(func(_, _ any) bool {
+			inFlight++
+			return true
+		})
+		if inFlight == 0 {
+			break
+		}
+		if time.Since(waitStart) > 5*time.Second {
+			t.Fatalf("guest agent queries still in flight: %d", inFlight)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Once the hung agents have failed, they're reported as down without waiting for them again
+	start = time.Now()
+	metrics := collect()
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("expected hung agents to be skipped, took %s", elapsed)
+	}
+	agents := findByDesc(metrics, c.guestAgentUp)
+	if len(agents) != 11 {
+		t.Fatalf("guestAgentUp: expected 11, got %d", len(agents))
+	}
+	for _, m := range agents {
+		expected := 0.0
+		if getMetricLabels(m)["vmid"] == "100" {
+			expected = 1
+		}
+		if v := getMetricValue(m); v != expected {
+			t.Errorf("guestAgentUp of VM %s: expected %f, got %f", getMetricLabels(m)["vmid"], expected, v)
+		}
+	}
+	if n := pings.Load(); n != 11 {
+		t.Errorf("expected each agent to be pinged once, got %d pings", n)
+	}
+}
+
+func TestCollectPerGuestMetrics_Bounded_Integration(t *testing.T) {
+	// Guest configs and firewalls aren't cached on the first scrape, so they're requested by a bounded number of workers
+	var inFlight, maxInFlight, requests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/cluster/status", intJSONHandler(`{"data": []}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/firewall/options", intJSONHandler(`{"data": {"enable": 1}}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/firewall/rules", intJSONHandler(`{"data": []}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/config", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"data": {"agent": "1"}}`)
+	})
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	http.DefaultTransport = &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	defer func() {
+		http.DefaultTransport = &http.Transport{}
+	}()
+
+	initProxmoxForIntegration(t, server.URL)
+
+	var guests []proxmox.GetClusterResourcesData
+	for vmID := 100; vmID < 140; vmID++ {
+		vmid := proxmox.IntOrString(strconv.Itoa(vmID))
+		guests = append(guests, proxmox.GetClusterResourcesData{Node: "node1", Type: "qemu", Status: "running", VMID: &vmid})
+	}
+
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 1000)
+	c.collectPerGuestMetrics(ch, "node1", guests)
+	metrics := drainMetrics(ch)
+
+	if got := len(findByDesc(metrics, c.guestAgentEnabled)); got != 40 {
+		t.Errorf("expected the config metrics of 40 guests, got %d", got)
+	}
+	if got := len(findByDesc(metrics, c.guestFirewallEnabled)); got != 40 {
+		t.Errorf("expected the firewall metrics of 40 guests, got %d", got)
+	}
+	if got := requests.Load(); got != 40 {
+		t.Errorf("expected 40 config requests, got %d", got)
+	}
+	if got := maxInFlight.Load(); got < 2 || got > guestRequestWorkers {
+		t.Errorf("expected between 2 and %d config requests in flight at once, got %d", guestRequestWorkers, got)
+	}
+}
+
+func TestDiscoverGuests_BoundedLookups_Integration(t *testing.T) {
+	// 20 running LXCs, more than the number of guests whose addresses are requested at once
+	var resources []string
+	for vmID := 300; vmID < 320; vmID++ {
+		resources = append(resources, fmt.Sprintf(`{"type": "lxc", "node": "node1", "vmid": %d, "name": "ct%d", "status": "running"}`, vmID, vmID))
+	}
+
+	var inFlight, maxInFlight, requests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/cluster/status", intJSONHandler(`{"data": []}`))
+	mux.HandleFunc("/api2/json/cluster/resources", intJSONHandler(`{"data": [`+strings.Join(resources, ",")+`]}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/lxc/{vmid}/interfaces", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"data": [{"name": "eth0", "inet": "10.0.0.5/24"}]}`)
+	})
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	http.DefaultTransport = &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	defer func() {
+		http.DefaultTransport = &http.Transport{}
+	}()
+
+	initProxmoxForIntegration(t, server.URL)
+
+	guests, err := DiscoverGuests(nil, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(guests) != 20 {
+		t.Fatalf("expected 20 guests, got %d", len(guests))
+	}
+	for _, guest := range guests {
+		if len(guest.IPs) != 1 {
+			t.Errorf("guest %s: expected 1 address, got %v", guest.VMID, guest.IPs)
+		}
+	}
+	if got := requests.Load(); got != 20 {
+		t.Errorf("expected 20 interface requests, got %d", got)
+	}
+	if got := maxInFlight.Load(); got > discoveryWorkers {
+		t.Errorf("expected at most %d interface requests in flight at once, got %d", discoveryWorkers, got)
+	}
+}
+
+func TestDiscoverGuests_Deadline_Integration(t *testing.T) {
+	oldCfg := cfg
+	cfg = Config{EnableSDGuestAgent: true, SDDeadline: 150 * time.Millisecond}
+	defer func() { cfg = oldCfg }()
+
+	// LXC 300 responds, while the agents of 8 VMs hang, more than the discovery workers
+	resources := []string{`{"type": "lxc", "node": "node1", "vmid": 300, "name": "ct300", "status": "running"}`}
+	for vmID := 100; vmID < 108; vmID++ {
+		resources = append(resources, fmt.Sprintf(`{"type": "qemu", "node": "node1", "vmid": %d, "name": "vm%d", "status": "running"}`, vmID, vmID))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/cluster/status", intJSONHandler(`{"data": []}`))
+	mux.HandleFunc("/api2/json/cluster/resources", intJSONHandler(`{"data": [`+strings.Join(resources, ",")+`]}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/lxc/{vmid}/interfaces", intJSONHandler(`{"data": [{"name": "eth0", "inet": "10.0.0.5/24"}]}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/lxc/{vmid}/config", intJSONHandler(`{"data": {}}`))
+	mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/config", intJSONHandler(`{"data": {"agent": "1"}}`))
+	var agentRequests atomic.Int32
+	mux.HandleFunc("/api2/json/nodes/{node}/qemu/{vmid}/agent/network-get-interfaces", func(w http.ResponseWriter, r *http.Request) {
+		agentRequests.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	http.DefaultTransport = &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	defer func() {
+		http.DefaultTransport = &http.Transport{}
+	}()
+
+	initProxmoxForIntegration(t, server.URL)
+	wrappedProxmox.SetGuestAgentLimits(100*time.Millisecond, 4)
+	t.Cleanup(func() { wrappedProxmox.SetGuestAgentLimits(5*time.Second, 4) })
+
+	// Waiting for every agent would take 200ms, but discovery only waits for the deadline
+	start := time.Now()
+	guests, err := DiscoverGuests(nil, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("expected discovery to stop waiting after the 150ms deadline, took %s", elapsed)
+	}
+
+	if len(guests) != 9 {
+		t.Fatalf("expected 9 guests, got %d", len(guests))
+	}
+	for _, guest := range guests {
+		expected := 0
+		if guest.VMID == "300" {
+			expected = 1
+		}
+		if len(guest.IPs) != expected {
+			t.Errorf("guest %s: expected %d addresses, got %v", guest.VMID, expected, guest.IPs)
+		}
+	}
+
+	// A request while the earlier lookups are still in flight doesn't request the same guests again
+	if _, err := DiscoverGuests(nil, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	waitStart := time.Now()
+	for {
+		inFlight := 0
+		sdLookupsInFlight.Range(func(_, _ any) bool {
+			inFlight++
+			return true
+		})
+		if inFlight == 0 {
+			break
+		}
+		if time.Since(waitStart) > 5*time.Second {
+			t.Fatalf("guest address lookups still in flight: %d", inFlight)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Each hung agent was requested once, and backed off afterwards
+	if got := agentRequests.Load(); got != 8 {
+		t.Errorf("expected 8 guest agent requests, got %d", got)
+	}
+}
+
+func TestDiscoverGuests_Integration(t *testing.T) {
+	mux := setupIntegrationMux(false)
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	http.DefaultTransport = &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	defer func() {
+		http.DefaultTransport = &http.Transport{}
+	}()
+
+	tests := []struct {
+		name          string
+		cfg           Config
+		tags          []string
+		pool          string
+		expectedVMIDs []string
+		expectedIPs   map[string]int
+	}{
+		{"all running guests", Config{}, nil, "", []string{"100", "200"}, map[string]int{"100": 0, "200": 1}},
+		{"guest agent metrics only", Config{EnableGuestAgentMetrics: true}, nil, "", []string{"100", "200"}, map[string]int{"100": 0, "200": 1}},
+		{"with guest agent", Config{EnableSDGuestAgent: true}, nil, "", []string{"100", "200"}, map[string]int{"100": 2, "200": 1}},
+		{"any of the tags", Config{EnableSDGuestAgent: true}, []string{"WEB", "missing"}, "", []string{"100"}, map[string]int{"100": 2}},
+		{"pool", Config{EnableSDGuestAgent: true}, nil, "prod", []string{"100"}, map[string]int{"100": 2}},
+		{"no match", Config{EnableSDGuestAgent: true}, []string{"infra"}, "prod", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldCfg := cfg
+			cfg = tt.cfg
+			defer func() { cfg = oldCfg }()
+			initProxmoxForIntegration(t, server.URL)
+
+			guests, err := DiscoverGuests(tt.tags, tt.pool)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(guests) != len(tt.expectedVMIDs) {
+				t.Fatalf("expected %d guests, got %d: %+v", len(tt.expectedVMIDs), len(guests), guests)
+			}
+			for i, guest := range guests {
+				if guest.VMID != tt.expectedVMIDs[i] {
+					t.Errorf("guest %d: expected vmid %s, got %s", i, tt.expectedVMIDs[i], guest.VMID)
+				}
+				if len(guest.IPs) != tt.expectedIPs[guest.VMID] {
+					t.Errorf("guest %s: expected %d addresses, got %v", guest.VMID, tt.expectedIPs[guest.VMID], guest.IPs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// collectLVMThinMetrics exports the data and metadata usage of a node's LVM thin pools
+func (c *Collector) collectLVMThinMetrics(ch chan<- prometheus.Metric, nodeName string, thinPools *wrappedProxmox.GetNodeDisksLVMThinResponse) {
+	for _, pool := range thinPools.Data {
+		ch <- prometheus.MustNewConstMetric(c.lvmThinSize, prometheus.GaugeValue, float64(pool.LVSize), nodeName, pool.VG, pool.LV)
+		ch <- prometheus.MustNewConstMetric(c.lvmThinUsed, prometheus.GaugeValue, float64(pool.Used), nodeName, pool.VG, pool.LV)
+		ch <- prometheus.MustNewConstMetric(c.lvmThinMetadataSize, prometheus.GaugeValue, float64(pool.MetadataSize), nodeName, pool.VG, pool.LV)
+		ch <- prometheus.MustNewConstMetric(c.lvmThinMetadataUsed, prometheus.GaugeValue, float64(pool.MetadataUsed), nodeName, pool.VG, pool.LV)
+	}
+}
+
+// collectLVMMetrics exports the size and free space of a node's LVM volume groups
+func (c *Collector) collectLVMMetrics(ch chan<- prometheus.Metric, nodeName string, lvm *wrappedProxmox.GetNodeDisksLVMResponse) {
+	for _, vg := range lvm.Data.Children {
+		ch <- prometheus.MustNewConstMetric(c.lvmVGSize, prometheus.GaugeValue, float64(vg.Size), nodeName, vg.Name)
+		ch <- prometheus.MustNewConstMetric(c.lvmVGFree, prometheus.GaugeValue, float64(vg.Free), nodeName, vg.Name)
+	}
+}
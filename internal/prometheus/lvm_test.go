@@ -0,0 +1,77 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestCollectLVMThinMetrics(t *testing.T) {
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 100)
+
+	c.collectLVMThinMetrics(ch, "node1", &wrappedProxmox.GetNodeDisksLVMThinResponse{
+		Data: []wrappedProxmox.GetNodeDisksLVMThinData{
+			{LV: "data", VG: "pve", LVSize: 1000, Used: 400, MetadataSize: 100, MetadataUsed: 90},
+			{LV: "thin2", VG: "ssd", LVSize: 2000, Used: 0, MetadataSize: 200, MetadataUsed: 1},
+		},
+	})
+	metrics := drainMetrics(ch)
+
+	if len(metrics) != 8 {
+		t.Fatalf("expected 8 metrics, got %d", len(metrics))
+	}
+
+	expected := map[*prometheus.Desc]map[string]float64{
+		c.lvmThinSize:         {"data": 1000, "thin2": 2000},
+		c.lvmThinUsed:         {"data": 400, "thin2": 0},
+		c.lvmThinMetadataSize: {"data": 100, "thin2": 200},
+		c.lvmThinMetadataUsed: {"data": 90, "thin2": 1},
+	}
+	expectedVG := map[string]string{"data": "pve", "thin2": "ssd"}
+	for desc, values := range expected {
+		for _, m := range findByDesc(metrics, desc) {
+			labels := getMetricLabels(m)
+			if labels["node"] != "node1" || labels["vg"] != expectedVG[labels["lv"]] {
+				t.Errorf("unexpected labels: %v", labels)
+			}
+			if v := getMetricValue(m); v != values[labels["lv"]] {
+				t.Errorf("%s lv %s: expected %f, got %f", desc, labels["lv"], values[labels["lv"]], v)
+			}
+		}
+	}
+}
+
+func TestCollectLVMMetrics(t *testing.T) {
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 100)
+
+	c.collectLVMMetrics(ch, "node1", &wrappedProxmox.GetNodeDisksLVMResponse{
+		Data: wrappedProxmox.GetNodeDisksLVMData{
+			Children: []wrappedProxmox.GetNodeDisksLVMChildData{
+				{Name: "pve", Size: 1000, Free: 100, Children: []wrappedProxmox.GetNodeDisksLVMChildData{
+					{Name: "/dev/sda3", Size: 1000, Free: 100, Leaf: 1},
+				}},
+			},
+		},
+	})
+	metrics := drainMetrics(ch)
+
+	// Physical volumes aren't exported, only their volume group
+	if len(metrics) != 2 {
+		t.Fatalf("expected 2 metrics, got %d", len(metrics))
+	}
+	for desc, value := range map[*prometheus.Desc]float64{c.lvmVGSize: 1000, c.lvmVGFree: 100} {
+		found := findByDesc(metrics, desc)
+		if len(found) != 1 {
+			t.Fatalf("%s: expected 1 metric, got %d", desc, len(found))
+		}
+		if labels := getMetricLabels(found[0]); labels["node"] != "node1" || labels["vg"] != "pve" {
+			t.Errorf("%s: unexpected labels %v", desc, labels)
+		}
+		if v := getMetricValue(found[0]); v != value {
+			t.Errorf("%s: expected %f, got %f", desc, value, v)
+		}
+	}
+}
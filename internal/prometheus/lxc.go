@@ -13,10 +13,7 @@ import (
 
 // collectLxcMetrics processes lxc entries from cluster resources
 func (c *Collector) collectLxcMetrics(ch chan<- prometheus.Metric, lxcs []proxmox.GetClusterResourcesData) *collectGuestMetricsResponse {
-	res := &collectGuestMetricsResponse{
-		cpusPerNode: make(map[string]int),
-		memPerNode:  make(map[string]int),
-	}
+	res := newCollectGuestMetricsResponse()
 	for _, lxc := range lxcs {
 		name := ""
 		if lxc.Name != nil {
@@ -43,6 +40,7 @@ func (c *Collector) collectLxcMetrics(ch chan<- prometheus.Metric, lxcs []proxmo
 		if lxc.MaxMem != nil {
 			res.memPerNode[lxc.Node] += *lxc.MaxMem
 		}
+		res.addPoolAllocation(lxc)
 
 		if cfg.EnableSnapshotMetrics {
 			c.collectLxcSnapshotMetrics(ch, lxc.Node, name, vmid, tags)
@@ -0,0 +1,61 @@
+package prometheus
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// collectNetworkMetrics exports the type and state of a node's network interfaces, the state of its bonds' slaves, and whether it has network configuration changes that haven't been applied
+func (c *Collector) collectNetworkMetrics(ch chan<- prometheus.Metric, nodeName string, network *wrappedProxmox.GetNodeNetworkResponse) {
+	active := make(map[string]bool)
+	for _, iface := range network.Data {
+		active[iface.Iface] = iface.Active != nil && *iface.Active == 1
+	}
+
+	for _, iface := range network.Data {
+		bondMode := ""
+		if iface.BondMode != nil {
+			bondMode = *iface.BondMode
+		}
+		ch <- prometheus.MustNewConstMetric(c.networkInterfaceInfo, prometheus.GaugeValue, 1, nodeName, iface.Iface, iface.Type, bondMode)
+
+		isActive := 0.0
+		if active[iface.Iface] {
+			isActive = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.networkInterfaceActive, prometheus.GaugeValue, isActive, nodeName, iface.Iface)
+
+		autostart := 0.0
+		if iface.Autostart != nil && *iface.Autostart == 1 {
+			autostart = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.networkInterfaceAutostart, prometheus.GaugeValue, autostart, nodeName, iface.Iface)
+
+		for _, slave := range bondSlaves(iface) {
+			slaveActive := 0.0
+			if active[slave] {
+				slaveActive = 1.0
+			}
+			ch <- prometheus.MustNewConstMetric(c.networkBondSlaveActive, prometheus.GaugeValue, slaveActive, nodeName, iface.Iface, slave)
+		}
+	}
+
+	pending := 0.0
+	if network.Changes != nil && strings.TrimSpace(*network.Changes) != "" {
+		pending = 1.0
+	}
+	ch <- prometheus.MustNewConstMetric(c.networkChangesPending, prometheus.GaugeValue, pending, nodeName)
+}
+
+// bondSlaves returns the interfaces that make up a Linux or OVS bond
+func bondSlaves(iface wrappedProxmox.GetNodeNetworkData) []string {
+	switch {
+	case iface.Type == "bond" && iface.Slaves != nil:
+		return strings.Fields(*iface.Slaves)
+	case iface.Type == "OVSBond" && iface.OVSBonds != nil:
+		return strings.Fields(*iface.OVSBonds)
+	}
+	return nil
+}
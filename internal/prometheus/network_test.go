@@ -0,0 +1,88 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestCollectNetworkMetrics(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+
+	network := &wrappedProxmox.GetNodeNetworkResponse{
+		Data: []wrappedProxmox.GetNodeNetworkData{
+			{Iface: "eno1", Type: "eth", Active: intPtr(1), Exists: intPtr(1)},
+			{Iface: "eno2", Type: "eth", Exists: intPtr(1)},
+			{Iface: "bond0", Type: "bond", Active: intPtr(1), Autostart: intPtr(1), Slaves: strPtr("eno1 eno2"), BondMode: strPtr("802.3ad")},
+			{Iface: "vmbr0", Type: "bridge", Active: intPtr(1), Autostart: intPtr(1), BridgePorts: strPtr("bond0")},
+			// Created in the pending configuration, but not applied yet
+			{Iface: "vmbr1", Type: "bridge", Autostart: intPtr(1)},
+			{Iface: "ovsbond0", Type: "OVSBond", Active: intPtr(1), OVSBonds: strPtr("eno3 eno4")},
+		},
+		Changes: strPtr("--- /etc/network/interfaces\n+++ /etc/network/interfaces.new\n+auto vmbr1\n"),
+	}
+
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 100)
+	c.collectNetworkMetrics(ch, "node1", network)
+	metrics := drainMetrics(ch)
+
+	// 3 metrics per interface, 4 bond slaves and the pending changes flag
+	if len(metrics) != 6*3+4+1 {
+		t.Fatalf("expected %d metrics, got %d", 6*3+4+1, len(metrics))
+	}
+
+	expectedInfo := map[string][2]string{
+		"eno1":     {"eth", ""},
+		"eno2":     {"eth", ""},
+		"bond0":    {"bond", "802.3ad"},
+		"vmbr0":    {"bridge", ""},
+		"vmbr1":    {"bridge", ""},
+		"ovsbond0": {"OVSBond", ""},
+	}
+	for _, m := range findByDesc(metrics, c.networkInterfaceInfo) {
+		labels := getMetricLabels(m)
+		want := expectedInfo[labels["iface"]]
+		if labels["type"] != want[0] || labels["bond_mode"] != want[1] {
+			t.Errorf("iface %s: unexpected labels %v", labels["iface"], labels)
+		}
+	}
+
+	expectedActive := map[string]float64{"eno1": 1, "eno2": 0, "bond0": 1, "vmbr0": 1, "vmbr1": 0, "ovsbond0": 1}
+	for _, m := range findByDesc(metrics, c.networkInterfaceActive) {
+		iface := getMetricLabels(m)["iface"]
+		if v := getMetricValue(m); v != expectedActive[iface] {
+			t.Errorf("iface %s: expected active %f, got %f", iface, expectedActive[iface], v)
+		}
+	}
+
+	expectedAutostart := map[string]float64{"eno1": 0, "eno2": 0, "bond0": 1, "vmbr0": 1, "vmbr1": 1, "ovsbond0": 0}
+	for _, m := range findByDesc(metrics, c.networkInterfaceAutostart) {
+		iface := getMetricLabels(m)["iface"]
+		if v := getMetricValue(m); v != expectedAutostart[iface] {
+			t.Errorf("iface %s: expected autostart %f, got %f", iface, expectedAutostart[iface], v)
+		}
+	}
+
+	// Slaves that aren't listed as interfaces aren't active
+	expectedSlaves := map[string]float64{"bond0/eno1": 1, "bond0/eno2": 0, "ovsbond0/eno3": 0, "ovsbond0/eno4": 0}
+	for _, m := range findByDesc(metrics, c.networkBondSlaveActive) {
+		labels := getMetricLabels(m)
+		key := labels["iface"] + "/" + labels["slave"]
+		want, ok := expectedSlaves[key]
+		if !ok {
+			t.Errorf("unexpected bond slave %s", key)
+			continue
+		}
+		if v := getMetricValue(m); v != want {
+			t.Errorf("bond slave %s: expected active %f, got %f", key, want, v)
+		}
+	}
+
+	pending := findByDesc(metrics, c.networkChangesPending)
+	if len(pending) != 1 || getMetricValue(pending[0]) != 1 {
+		t.Errorf("expected pending changes, got %v", pending)
+	}
+}
@@ -1,6 +1,7 @@
 package prometheus
 
 import (
+	"strconv"
 	"strings"
 	"sync"
 
@@ -18,8 +19,9 @@ func (c *Collector) collectNodeUpMetric(ch chan<- prometheus.Metric, node proxmo
 	ch <- prometheus.MustNewConstMetric(c.nodeUp, prometheus.GaugeValue, status, node.Node)
 }
 
-// collectNodeSpecificMetrics fetches per-node data that isn't available in cluster resources
-func (c *Collector) collectNodeSpecificMetrics(ch chan<- prometheus.Metric, nodeName string, wg *sync.WaitGroup) {
+// collectNodeSpecificMetrics fetches per-node data that isn't available in cluster resources, and lists the content of the backup storages assigned to the node.
+// The node's key package versions and clock offset are recorded in packages and timeOffsets to compare them between nodes once every node is collected
+func (c *Collector) collectNodeSpecificMetrics(ch chan<- prometheus.Metric, nodeName string, guests []proxmox.GetClusterResourcesData, backupStorages []backupStorage, packages *packageVersions, timeOffsets *nodeTimeOffsets, wg *sync.WaitGroup) {
 	defer wg.Done()
 	defer logger.Logger.Debug("finished requests for node data", "node", nodeName)
 
@@ -28,7 +30,78 @@ func (c *Collector) collectNodeSpecificMetrics(ch chan<- prometheus.Metric, node
 		logger.Logger.Error("failed making request to get node disks", "node", nodeName, "error", err.Error())
 	} else {
 		c.collectDiskMetrics(ch, nodeName, disks)
+		c.collectDiskInventoryMetrics(ch, nodeName, disks)
+
+		for _, disk := range disks.Data {
+			smart, err := wrappedProxmox.GetNodeDisksSMART(nodeName, disk.DevPath)
+			if err != nil {
+				logger.Logger.Error("failed making request to get disk SMART data", "node", nodeName, "disk", disk.DevPath, "error", err.Error())
+				continue
+			}
+			c.collectDiskSMARTMetrics(ch, nodeName, disk, smart)
+		}
+	}
+
+	zfsPools, err := wrappedProxmox.GetNodeDisksZFS(nodeName)
+	if err != nil {
+		logger.Logger.Error("failed making request to get node ZFS pools", "node", nodeName, "error", err.Error())
+	} else {
+		for _, pool := range zfsPools.Data {
+			c.collectZFSPoolMetrics(ch, nodeName, pool)
+
+			detail, err := wrappedProxmox.GetNodeDisksZFSDetail(nodeName, pool.Name)
+			if err != nil {
+				logger.Logger.Error("failed making request to get node ZFS pool status", "node", nodeName, "pool", pool.Name, "error", err.Error())
+				continue
+			}
+			c.collectZFSVdevMetrics(ch, nodeName, detail)
+		}
+	}
+
+	thinPools, err := wrappedProxmox.GetNodeDisksLVMThin(nodeName)
+	if err != nil {
+		logger.Logger.Error("failed making request to get node LVM thin pools", "node", nodeName, "error", err.Error())
+	} else {
+		c.collectLVMThinMetrics(ch, nodeName, thinPools)
+	}
+
+	lvm, err := wrappedProxmox.GetNodeDisksLVM(nodeName)
+	if err != nil {
+		logger.Logger.Error("failed making request to get node LVM volume groups", "node", nodeName, "error", err.Error())
+	} else {
+		c.collectLVMMetrics(ch, nodeName, lvm)
+	}
+
+	sub, err := wrappedProxmox.GetNodeSubscription(nodeName)
+	if err != nil {
+		logger.Logger.Error("failed making request to get node subscription", "node", nodeName, "error", err.Error())
+	} else {
+		c.collectSubscriptionMetrics(ch, nodeName, sub)
+	}
+
+	services, err := wrappedProxmox.GetNodeServices(nodeName)
+	if err != nil {
+		logger.Logger.Error("failed making request to get node services", "node", nodeName, "error", err.Error())
+	} else {
+		c.collectServiceMetrics(ch, nodeName, services)
+	}
+
+	network, err := wrappedProxmox.GetNodeNetwork(nodeName)
+	if err != nil {
+		logger.Logger.Error("failed making request to get node network interfaces", "node", nodeName, "error", err.Error())
+	} else {
+		c.collectNetworkMetrics(ch, nodeName, network)
+	}
+
+	firewallOptions, err := wrappedProxmox.GetNodeFirewallOptions(nodeName)
+	if err != nil {
+		logger.Logger.Error("failed making request to get node firewall options", "node", nodeName, "error", err.Error())
+	}
+	firewallRules, err := wrappedProxmox.GetNodeFirewallRules(nodeName)
+	if err != nil {
+		logger.Logger.Error("failed making request to get node firewall rules", "node", nodeName, "error", err.Error())
 	}
+	c.collectNodeFirewallMetrics(ch, nodeName, firewallOptions, firewallRules)
 
 	certs, err := wrappedProxmox.GetNodeCertificatesInfo(nodeName)
 	if err != nil {
@@ -43,6 +116,60 @@ func (c *Collector) collectNodeSpecificMetrics(ch chan<- prometheus.Metric, node
 	} else {
 		ch <- prometheus.MustNewConstMetric(c.nodeVersion, prometheus.GaugeValue, float64(1), nodeName, nodeStatus.Data.PveVersion)
 	}
+
+	nodeTime, err := wrappedProxmox.GetNodeTime(nodeName)
+	if err != nil {
+		logger.Logger.Error("failed making request to get node time", "node", nodeName, "error", err.Error())
+	} else {
+		c.collectNodeTimeMetrics(ch, nodeName, nodeTime, timeOffsets)
+	}
+
+	updates, err := wrappedProxmox.GetNodeAptUpdate(nodeName)
+	if err != nil {
+		logger.Logger.Error("failed making request to get node package updates", "node", nodeName, "error", err.Error())
+	} else {
+		c.collectUpdatesPendingMetrics(ch, nodeName, updates)
+	}
+
+	aptVersions, err := wrappedProxmox.GetNodeAptVersions(nodeName)
+	if err != nil {
+		logger.Logger.Error("failed making request to get node package versions", "node", nodeName, "error", err.Error())
+	} else {
+		kversion := ""
+		if nodeStatus != nil {
+			kversion = nodeStatus.Data.Kversion
+			c.collectRebootRequiredMetric(ch, nodeName, kversion, aptVersions)
+		}
+		c.collectPackageVersionMetrics(ch, nodeName, kversion, aptVersions, packages)
+	}
+
+	err = c.backupHistory.update(nodeName)
+	if err != nil {
+		logger.Logger.Error("failed making request to get node vzdump tasks", "node", nodeName, "error", err.Error())
+	}
+	ch <- prometheus.MustNewConstMetric(c.backupTasksUnreadable, prometheus.CounterValue, float64(c.backupHistory.unreadableTasks(nodeName)), nodeName)
+
+	replication, err := wrappedProxmox.GetNodeReplication(nodeName)
+	if err != nil {
+		logger.Logger.Error("failed making request to get node replication jobs", "node", nodeName, "error", err.Error())
+	} else {
+		c.collectReplicationMetrics(ch, nodeName, replication)
+	}
+
+	c.collectPerGuestMetrics(ch, nodeName, guests)
+	c.collectLxcNetworkMetrics(ch, nodeName, guests)
+	if cfg.EnableGuestAgentMetrics {
+		c.collectGuestAgentMetrics(ch, nodeName, guests)
+	}
+
+	for _, storage := range backupStorages {
+		content, err := wrappedProxmox.GetNodeStorageContent(nodeName, storage.name, wrappedProxmox.GetNodeStorageContentOptions{Content: "backup"})
+		if err != nil {
+			logger.Logger.Error("failed making request to get backup storage content", "node", nodeName, "storage", storage.name, "error", err.Error())
+			continue
+		}
+		c.collectStorageBackupMetrics(ch, nodeName, storage, content)
+	}
 }
 
 func (c *Collector) collectDiskMetrics(ch chan<- prometheus.Metric, nodeName string, disks *proxmox.GetNodeDisksListResponse) {
@@ -58,6 +185,24 @@ func (c *Collector) collectDiskMetrics(ch chan<- prometheus.Metric, nodeName str
 	}
 }
 
+// collectDiskInventoryMetrics exports the size, remaining life and identifying information of a node's disks
+func (c *Collector) collectDiskInventoryMetrics(ch chan<- prometheus.Metric, nodeName string, disks *proxmox.GetNodeDisksListResponse) {
+	for _, disk := range disks.Data {
+		// Disks that don't report a rotation rate (ex: SSDs) have an rpm of 0, or -1 if unknown
+		rpm := ""
+		if v, ok := parseIntOrString(disk.RPM); ok && v > 0 {
+			rpm = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		ch <- prometheus.MustNewConstMetric(c.diskInfo, prometheus.GaugeValue, 1, nodeName, disk.DevPath, disk.Model, disk.Serial, disk.Vendor, disk.Type, rpm, disk.Used)
+		ch <- prometheus.MustNewConstMetric(c.diskSize, prometheus.GaugeValue, float64(disk.Size), nodeName, disk.DevPath)
+
+		// Wearout is the percentage of a disk's rated life remaining, and is N/A for disks that don't report it (ex: HDDs)
+		if wearout, ok := parseIntOrString(disk.Wearout); ok {
+			ch <- prometheus.MustNewConstMetric(c.diskWearout, prometheus.GaugeValue, wearout/100, nodeName, disk.DevPath)
+		}
+	}
+}
+
 func (c *Collector) collectCertificateMetrics(ch chan<- prometheus.Metric, nodeName string, certs *proxmox.GetNodeCertificatesInfoResponse) {
 	for _, cert := range certs.Data {
 		expDays := daysUntilUnixTime(cert.NotAfter)
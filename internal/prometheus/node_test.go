@@ -251,6 +251,60 @@ func TestCollectDiskMetrics_Labels(t *testing.T) {
 	}
 }
 
+func TestCollectDiskInventoryMetrics(t *testing.T) {
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 20)
+
+	disks := &proxmox.GetNodeDisksListResponse{
+		Data: []proxmox.GetNodeDisksListData{
+			{DevPath: "/dev/nvme0n1", Model: "Samsung SSD", Serial: "S123", Vendor: "Samsung", Type: "nvme", Size: 512110190592, RPM: "0", Wearout: "95", Used: "ZFS"},
+			{DevPath: "/dev/sda", Model: "WD HDD", Serial: "W456", Vendor: "WDC", Type: "hdd", Size: 2000398934016, RPM: "7200", Wearout: "N/A"},
+			{DevPath: "/dev/sdb", Type: "unknown", RPM: "-1"},
+		},
+	}
+
+	c.collectDiskInventoryMetrics(ch, "node1", disks)
+	metrics := drainMetrics(ch)
+
+	// 3 info and 3 size metrics, and a wearout metric for the NVMe disk
+	if len(metrics) != 7 {
+		t.Fatalf("expected 7 metrics, got %d", len(metrics))
+	}
+
+	expectedInfo := map[string]map[string]string{
+		"/dev/nvme0n1": {"model": "Samsung SSD", "serial": "S123", "vendor": "Samsung", "type": "nvme", "rpm": "", "usage": "ZFS"},
+		"/dev/sda":     {"model": "WD HDD", "serial": "W456", "vendor": "WDC", "type": "hdd", "rpm": "7200", "usage": ""},
+		"/dev/sdb":     {"model": "", "serial": "", "vendor": "", "type": "unknown", "rpm": "", "usage": ""},
+	}
+	for _, m := range findByDesc(metrics, c.diskInfo) {
+		labels := getMetricLabels(m)
+		for k, v := range expectedInfo[labels["devpath"]] {
+			if labels[k] != v {
+				t.Errorf("disk %s: expected %s=%q, got %q", labels["devpath"], k, v, labels[k])
+			}
+		}
+	}
+
+	expectedSize := map[string]float64{"/dev/nvme0n1": 512110190592, "/dev/sda": 2000398934016, "/dev/sdb": 0}
+	for _, m := range findByDesc(metrics, c.diskSize) {
+		devpath := getMetricLabels(m)["devpath"]
+		if v := getMetricValue(m); v != expectedSize[devpath] {
+			t.Errorf("disk %s: expected size %f, got %f", devpath, expectedSize[devpath], v)
+		}
+	}
+
+	wearout := findByDesc(metrics, c.diskWearout)
+	if len(wearout) != 1 {
+		t.Fatalf("expected 1 wearout metric, got %d", len(wearout))
+	}
+	if devpath := getMetricLabels(wearout[0])["devpath"]; devpath != "/dev/nvme0n1" {
+		t.Errorf("expected wearout for /dev/nvme0n1, got %s", devpath)
+	}
+	if v := getMetricValue(wearout[0]); v != 0.95 {
+		t.Errorf("expected wearout 0.95, got %f", v)
+	}
+}
+
 func TestCollectCertificateMetrics(t *testing.T) {
 	futureTime := int(time.Now().Add(30 * 24 * time.Hour).Unix())
 	pastTime := int(time.Now().Add(-1 * 24 * time.Hour).Unix())
@@ -0,0 +1,75 @@
+package prometheus
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// keyPackages are the packages whose version is exported per node, since nodes running different versions of them can cause problems during rolling upgrades
+var keyPackages = []string{"qemu-server", "pve-qemu-kvm", "corosync", "ceph", "lxc-pve"}
+
+// kernelPackage is the package name the running kernel's version is exported under
+const kernelPackage = "proxmox-kernel"
+
+// packageVersions contains the versions of the key packages on each node, gathered during a scrape to compare them between nodes
+type packageVersions struct {
+	mu       sync.Mutex
+	versions map[string]map[string]string // package -> node -> version
+}
+
+func newPackageVersions() *packageVersions {
+	return &packageVersions{versions: make(map[string]map[string]string)}
+}
+
+// add records the version of a package on a node
+func (p *packageVersions) add(nodeName, pkg, version string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.versions[pkg] == nil {
+		p.versions[pkg] = make(map[string]string)
+	}
+	p.versions[pkg][nodeName] = version
+}
+
+// collectPackageVersionMetrics exports the versions of a node's key packages and running kernel, and records them to compare between nodes
+func (c *Collector) collectPackageVersionMetrics(ch chan<- prometheus.Metric, nodeName, kversion string, versions *wrappedProxmox.GetNodeAptVersionsResponse, packages *packageVersions) {
+	for _, pkg := range versions.Data {
+		if !isKeyPackage(pkg.Package) || !strings.EqualFold(pkg.CurrentState, "Installed") {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.packageVersionInfo, prometheus.GaugeValue, 1, nodeName, pkg.Package, pkg.Version)
+		packages.add(nodeName, pkg.Package, pkg.Version)
+	}
+
+	if kernel := runningKernelVersion(kversion); kernel != "" {
+		ch <- prometheus.MustNewConstMetric(c.packageVersionInfo, prometheus.GaugeValue, 1, nodeName, kernelPackage, kernel)
+		packages.add(nodeName, kernelPackage, kernel)
+	}
+}
+
+// collectPackageVersionSkewMetrics exports the number of different versions of each key package that the cluster's nodes are running
+func (c *Collector) collectPackageVersionSkewMetrics(ch chan<- prometheus.Metric, packages *packageVersions) {
+	packages.mu.Lock()
+	defer packages.mu.Unlock()
+
+	for pkg, nodes := range packages.versions {
+		distinct := make(map[string]bool)
+		for _, version := range nodes {
+			distinct[version] = true
+		}
+		ch <- prometheus.MustNewConstMetric(c.packageVersionSkew, prometheus.GaugeValue, float64(len(distinct)), pkg)
+	}
+}
+
+// isKeyPackage returns true if a package's version should be exported
+func isKeyPackage(name string) bool {
+	for _, pkg := range keyPackages {
+		if name == pkg {
+			return true
+		}
+	}
+	return false
+}
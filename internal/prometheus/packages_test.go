@@ -0,0 +1,77 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestCollectPackageVersionMetrics(t *testing.T) {
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 20)
+	packages := newPackageVersions()
+
+	versions := &wrappedProxmox.GetNodeAptVersionsResponse{
+		Data: []wrappedProxmox.GetNodeAptVersionsData{
+			{Package: "proxmox-ve", Version: "8.1.0", CurrentState: "Installed"},
+			{Package: "qemu-server", Version: "8.0.10", CurrentState: "Installed"},
+			{Package: "pve-qemu-kvm", Version: "8.1.5-2", CurrentState: "Installed"},
+			{Package: "lxc-pve", Version: "5.0.2-4", CurrentState: "Installed"},
+			{Package: "ceph", Version: "", CurrentState: "NotInstalled"},
+			{Package: "proxmox-kernel-6.5.13-1-pve-signed", Version: "6.5.13-1", CurrentState: "Installed"},
+		},
+	}
+	c.collectPackageVersionMetrics(ch, "node1", "Linux 6.5.11-8-pve #1 SMP", versions, packages)
+	metrics := drainMetrics(ch)
+
+	expected := map[string]string{
+		"qemu-server":    "8.0.10",
+		"pve-qemu-kvm":   "8.1.5-2",
+		"lxc-pve":        "5.0.2-4",
+		"proxmox-kernel": "6.5.11-8",
+	}
+	if len(metrics) != len(expected) {
+		t.Fatalf("expected %d metrics, got %d", len(expected), len(metrics))
+	}
+	for _, m := range metrics {
+		labels := getMetricLabels(m)
+		if labels["node"] != "node1" || labels["version"] != expected[labels["package"]] {
+			t.Errorf("unexpected labels: %v", labels)
+		}
+	}
+	for pkg, version := range expected {
+		if got := packages.versions[pkg]["node1"]; got != version {
+			t.Errorf("%s: expected recorded version %s, got %s", pkg, version, got)
+		}
+	}
+}
+
+func TestCollectPackageVersionSkewMetrics(t *testing.T) {
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 20)
+	packages := newPackageVersions()
+
+	packages.add("node1", "qemu-server", "8.0.10")
+	packages.add("node2", "qemu-server", "8.0.10")
+	packages.add("node3", "qemu-server", "8.1.4")
+	packages.add("node1", "corosync", "3.1.7-pve3")
+	packages.add("node2", "corosync", "3.1.7-pve3")
+	packages.add("node1", "proxmox-kernel", "6.5.11-8")
+	packages.add("node2", "proxmox-kernel", "6.5.13-1")
+	packages.add("node3", "proxmox-kernel", "6.8.4-2")
+
+	c.collectPackageVersionSkewMetrics(ch, packages)
+	metrics := drainMetrics(ch)
+
+	expected := map[string]float64{"qemu-server": 2, "corosync": 1, "proxmox-kernel": 3}
+	if len(metrics) != len(expected) {
+		t.Fatalf("expected %d metrics, got %d", len(expected), len(metrics))
+	}
+	for _, m := range metrics {
+		pkg := getMetricLabels(m)["package"]
+		if v := getMetricValue(m); v != expected[pkg] {
+			t.Errorf("%s: expected %f, got %f", pkg, expected[pkg], v)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collectPoolMetrics combines VM and LXC allocations per resource pool
+func (c *Collector) collectPoolMetrics(ch chan<- prometheus.Metric, vmMetrics, lxcMetrics *collectGuestMetricsResponse) {
+	pools := make(map[string]bool)
+	for pool := range vmMetrics.guestsPerPool {
+		pools[pool] = true
+	}
+	for pool := range lxcMetrics.guestsPerPool {
+		pools[pool] = true
+	}
+
+	for pool := range pools {
+		guests := vmMetrics.guestsPerPool[pool] + lxcMetrics.guestsPerPool[pool]
+		running := vmMetrics.runningPerPool[pool] + lxcMetrics.runningPerPool[pool]
+		cpus := vmMetrics.cpusPerPool[pool] + lxcMetrics.cpusPerPool[pool]
+		mem := vmMetrics.memPerPool[pool] + lxcMetrics.memPerPool[pool]
+		disk := vmMetrics.diskPerPool[pool] + lxcMetrics.diskPerPool[pool]
+
+		ch <- prometheus.MustNewConstMetric(c.poolGuests, prometheus.GaugeValue, float64(guests), pool)
+		ch <- prometheus.MustNewConstMetric(c.poolGuestsRunning, prometheus.GaugeValue, float64(running), pool)
+		ch <- prometheus.MustNewConstMetric(c.poolCPUsAlloc, prometheus.GaugeValue, float64(cpus), pool)
+		ch <- prometheus.MustNewConstMetric(c.poolMemAlloc, prometheus.GaugeValue, float64(mem), pool)
+		ch <- prometheus.MustNewConstMetric(c.poolDiskAlloc, prometheus.GaugeValue, float64(disk), pool)
+	}
+}
@@ -0,0 +1,93 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+)
+
+func TestAddPoolAllocation(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+
+	res := newCollectGuestMetricsResponse()
+	res.addPoolAllocation(proxmox.GetClusterResourcesData{Status: "running", Pool: strPtr("tenant-a"), MaxCPU: intPtr(4), MaxMem: intPtr(4096), MaxDisk: intPtr(10000)})
+	res.addPoolAllocation(proxmox.GetClusterResourcesData{Status: "stopped", Pool: strPtr("tenant-a"), MaxCPU: intPtr(2), MaxMem: intPtr(2048), MaxDisk: intPtr(5000)})
+	res.addPoolAllocation(proxmox.GetClusterResourcesData{Status: "running", Pool: strPtr("tenant-b")})
+	res.addPoolAllocation(proxmox.GetClusterResourcesData{Status: "running", Pool: strPtr(""), MaxCPU: intPtr(8)})
+	res.addPoolAllocation(proxmox.GetClusterResourcesData{Status: "running", MaxCPU: intPtr(8)})
+
+	if len(res.guestsPerPool) != 2 {
+		t.Fatalf("expected 2 pools, got %d", len(res.guestsPerPool))
+	}
+	if res.guestsPerPool["tenant-a"] != 2 {
+		t.Errorf("tenant-a: expected 2 guests, got %d", res.guestsPerPool["tenant-a"])
+	}
+	if res.runningPerPool["tenant-a"] != 1 {
+		t.Errorf("tenant-a: expected 1 running guest, got %d", res.runningPerPool["tenant-a"])
+	}
+	if res.cpusPerPool["tenant-a"] != 6 {
+		t.Errorf("tenant-a: expected 6 CPUs, got %d", res.cpusPerPool["tenant-a"])
+	}
+	if res.memPerPool["tenant-a"] != 6144 {
+		t.Errorf("tenant-a: expected 6144 mem, got %d", res.memPerPool["tenant-a"])
+	}
+	if res.diskPerPool["tenant-a"] != 15000 {
+		t.Errorf("tenant-a: expected 15000 disk, got %d", res.diskPerPool["tenant-a"])
+	}
+	if res.guestsPerPool["tenant-b"] != 1 || res.cpusPerPool["tenant-b"] != 0 {
+		t.Errorf("tenant-b: expected 1 guest with 0 CPUs, got %d guests with %d CPUs", res.guestsPerPool["tenant-b"], res.cpusPerPool["tenant-b"])
+	}
+}
+
+func TestCollectPoolMetrics(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+
+	vmMetrics := newCollectGuestMetricsResponse()
+	vmMetrics.addPoolAllocation(proxmox.GetClusterResourcesData{Status: "running", Pool: strPtr("tenant-a"), MaxCPU: intPtr(4), MaxMem: intPtr(4096)})
+	lxcMetrics := newCollectGuestMetricsResponse()
+	lxcMetrics.addPoolAllocation(proxmox.GetClusterResourcesData{Status: "running", Pool: strPtr("tenant-a"), MaxCPU: intPtr(1), MaxMem: intPtr(512)})
+	lxcMetrics.addPoolAllocation(proxmox.GetClusterResourcesData{Status: "stopped", Pool: strPtr("tenant-b"), MaxCPU: intPtr(2)})
+
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 100)
+
+	c.collectPoolMetrics(ch, vmMetrics, lxcMetrics)
+	metrics := drainMetrics(ch)
+
+	if len(metrics) != 10 {
+		t.Fatalf("expected 10 metrics (5 per pool), got %d", len(metrics))
+	}
+
+	expected := map[*prometheus.Desc]map[string]float64{
+		c.poolGuests:        {"tenant-a": 2, "tenant-b": 1},
+		c.poolGuestsRunning: {"tenant-a": 2, "tenant-b": 0},
+		c.poolCPUsAlloc:     {"tenant-a": 5, "tenant-b": 2},
+		c.poolMemAlloc:      {"tenant-a": 4608, "tenant-b": 0},
+		c.poolDiskAlloc:     {"tenant-a": 0, "tenant-b": 0},
+	}
+	for _, m := range metrics {
+		pool := getMetricLabels(m)["pool"]
+		want, ok := expected[m.Desc()][pool]
+		if !ok {
+			t.Errorf("unexpected metric for pool %s: %s", pool, m.Desc())
+			continue
+		}
+		if v := getMetricValue(m); v != want {
+			t.Errorf("%s pool %s: expected %f, got %f", m.Desc(), pool, want, v)
+		}
+	}
+}
+
+func TestCollectPoolMetrics_NoPools(t *testing.T) {
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 10)
+
+	c.collectPoolMetrics(ch, newCollectGuestMetricsResponse(), newCollectGuestMetricsResponse())
+
+	if metrics := drainMetrics(ch); len(metrics) != 0 {
+		t.Errorf("expected no metrics without pools, got %d", len(metrics))
+	}
+}
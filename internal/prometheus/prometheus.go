@@ -1,8 +1,10 @@
 package prometheus
 
 import (
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/starttoaster/go-proxmox"
@@ -14,7 +16,24 @@ var cfg Config
 
 // Config is the configuration to pass to the init function
 type Config struct {
-	EnableSnapshotMetrics bool
+	EnableSnapshotMetrics   bool
+	EnableGuestAgentMetrics bool
+
+	// Whether service discovery reads the addresses of VMs from their guest agent
+	EnableSDGuestAgent bool
+
+	// Maximum time a service discovery request waits for the addresses of guests, defaultSDDeadline if 0
+	SDDeadline time.Duration
+
+	// Maximum time a scrape waits for the guest agents of a node, defaultGuestAgentDeadline if 0
+	GuestAgentDeadline time.Duration
+
+	// Backup coverage filters
+	BackupCoverageIgnoreTemplates bool
+	BackupCoverageIgnoreTags      []string
+
+	// Time zone that job schedules are evaluated in, the local time zone if nil
+	ScheduleLocation *time.Location
 }
 
 // Init is a helper to configure the metrics collector
@@ -27,11 +46,27 @@ type Collector struct {
 	// Exporter
 	clientCount *prometheus.Desc
 
+	// Cluster
+	clusterQuorate       *prometheus.Desc
+	clusterNodesExpected *prometheus.Desc
+	clusterNodesOnline   *prometheus.Desc
+	clusterNodeInfo      *prometheus.Desc
+
+	// HA
+	haMasterInfo             *prometheus.Desc
+	haLRMState               *prometheus.Desc
+	haResourceState          *prometheus.Desc
+	haResourceRequestedState *prometheus.Desc
+
 	// Statuses
 	nodeUp      *prometheus.Desc
 	guestUp     *prometheus.Desc
 	nodeVersion *prometheus.Desc
 
+	// Guests
+	guestInfo *prometheus.Desc
+	guestLock *prometheus.Desc
+
 	// CPU
 	clusterCPUsTotal *prometheus.Desc
 	clusterCPUsAlloc *prometheus.Desc
@@ -44,19 +79,143 @@ type Collector struct {
 	nodeMemTotal    *prometheus.Desc
 	nodeMemAlloc    *prometheus.Desc
 
+	// Pools
+	poolGuests        *prometheus.Desc
+	poolGuestsRunning *prometheus.Desc
+	poolCPUsAlloc     *prometheus.Desc
+	poolMemAlloc      *prometheus.Desc
+	poolDiskAlloc     *prometheus.Desc
+
 	// Storage
 	storageTotal *prometheus.Desc
 	storageUsed  *prometheus.Desc
 
+	// Backups
+	guestBackupCovered       *prometheus.Desc
+	clusterGuestsNotBackedUp *prometheus.Desc
+	guestLastBackupSuccess   *prometheus.Desc
+	guestLastBackupDuration  *prometheus.Desc
+	guestBackupFailures      *prometheus.Desc
+	backupTasksUnreadable    *prometheus.Desc
+	backupJobInfo            *prometheus.Desc
+	backupJobEnabled         *prometheus.Desc
+	backupJobNextRun         *prometheus.Desc
+	guestBackups             *prometheus.Desc
+	guestBackupNewest        *prometheus.Desc
+	guestBackupOldest        *prometheus.Desc
+	guestBackupSize          *prometheus.Desc
+
+	// Replication
+	replicationLastSync  *prometheus.Desc
+	replicationDuration  *prometheus.Desc
+	replicationNextSync  *prometheus.Desc
+	replicationFailCount *prometheus.Desc
+	replicationError     *prometheus.Desc
+
 	// Snapshots
 	guestSnapshotsCount     *prometheus.Desc
 	guestSnapshotAgeSeconds *prometheus.Desc
 
+	// QEMU guest agent
+	guestAgentUp        *prometheus.Desc
+	guestFilesystemSize *prometheus.Desc
+	guestFilesystemUsed *prometheus.Desc
+	guestOSInfo         *prometheus.Desc
+
 	// Disk
-	diskSmartHealth *prometheus.Desc
+	diskSmartHealth        *prometheus.Desc
+	diskTemperature        *prometheus.Desc
+	diskPowerOnHours       *prometheus.Desc
+	diskReallocatedSectors *prometheus.Desc
+	diskPendingSectors     *prometheus.Desc
+	diskMediaErrors        *prometheus.Desc
+	diskPercentageUsed     *prometheus.Desc
+	diskInfo               *prometheus.Desc
+	diskSize               *prometheus.Desc
+	diskWearout            *prometheus.Desc
+
+	// ZFS
+	zfsPoolHealth         *prometheus.Desc
+	zfsPoolSize           *prometheus.Desc
+	zfsPoolAlloc          *prometheus.Desc
+	zfsPoolFree           *prometheus.Desc
+	zfsPoolFrag           *prometheus.Desc
+	zfsPoolDedup          *prometheus.Desc
+	zfsVdevState          *prometheus.Desc
+	zfsVdevReadErrors     *prometheus.Desc
+	zfsVdevWriteErrors    *prometheus.Desc
+	zfsVdevChecksumErrors *prometheus.Desc
+
+	// LVM
+	lvmThinSize         *prometheus.Desc
+	lvmThinUsed         *prometheus.Desc
+	lvmThinMetadataSize *prometheus.Desc
+	lvmThinMetadataUsed *prometheus.Desc
+	lvmVGSize           *prometheus.Desc
+	lvmVGFree           *prometheus.Desc
+
+	// Subscriptions
+	subscriptionStatus    *prometheus.Desc
+	subscriptionInfo      *prometheus.Desc
+	subscriptionNextDue   *prometheus.Desc
+	subscriptionLastCheck *prometheus.Desc
+
+	// Packages
+	updatesPending     *prometheus.Desc
+	rebootRequired     *prometheus.Desc
+	packageVersionInfo *prometheus.Desc
+	packageVersionSkew *prometheus.Desc
+
+	// Services
+	serviceState     *prometheus.Desc
+	serviceUnitState *prometheus.Desc
+
+	// Time
+	nodeTimeOffset  *prometheus.Desc
+	clusterTimeSkew *prometheus.Desc
+
+	// Network
+	networkInterfaceInfo      *prometheus.Desc
+	networkInterfaceActive    *prometheus.Desc
+	networkInterfaceAutostart *prometheus.Desc
+	networkBondSlaveActive    *prometheus.Desc
+	networkChangesPending     *prometheus.Desc
+
+	// SDN
+	sdnZoneStatus *prometheus.Desc
+	sdnZoneInfo   *prometheus.Desc
+	sdnVnetInfo   *prometheus.Desc
+
+	// Firewall
+	clusterFirewallEnabled *prometheus.Desc
+	clusterFirewallPolicy  *prometheus.Desc
+	clusterFirewallRules   *prometheus.Desc
+	nodeFirewallEnabled    *prometheus.Desc
+	nodeFirewallRules      *prometheus.Desc
+	guestFirewallEnabled   *prometheus.Desc
+	guestFirewallPolicy    *prometheus.Desc
+	guestFirewallRules     *prometheus.Desc
+
+	// Guest configuration
+	guestOnBoot       *prometheus.Desc
+	guestProtection   *prometheus.Desc
+	guestAgentEnabled *prometheus.Desc
+	guestBalloonMin   *prometheus.Desc
+	guestCPUSockets   *prometheus.Desc
+	guestCPUCores     *prometheus.Desc
+	guestConfigInfo   *prometheus.Desc
+
+	// Guest network
+	guestIPInfo *prometheus.Desc
 
 	// Certificates
 	daysUntilCertExpiry *prometheus.Desc
+
+	// vzdump task history, read incrementally across scrapes
+	backupHistory *backupHistory
+
+	// VMs, by VMID, whose guest agent is still being queried, possibly from an earlier scrape that stopped waiting for it
+	guestAgentsInFlight sync.Map
 }
 
 // NewCollector constructor function for Collector
@@ -77,6 +236,50 @@ func NewCollector() *Collector {
 			constLabels,
 		),
 
+		// Cluster metrics
+		clusterQuorate: prometheus.NewDesc(fqAddPrefix("cluster_quorate"),
+			"Shows whether the cluster is quorate. (0=not quorate,1=quorate)",
+			nil,
+			constLabels,
+		),
+		clusterNodesExpected: prometheus.NewDesc(fqAddPrefix("cluster_nodes_expected"),
+			"Number of nodes the cluster is configured with.",
+			nil,
+			constLabels,
+		),
+		clusterNodesOnline: prometheus.NewDesc(fqAddPrefix("cluster_nodes_online"),
+			"Number of cluster member nodes that are online.",
+			nil,
+			constLabels,
+		),
+		clusterNodeInfo: prometheus.NewDesc(fqAddPrefix("cluster_node_info"),
+			"Cluster membership information for a node, including its corosync node ID and ring IP.",
+			[]string{"node", "nodeid", "ip"},
+			constLabels,
+		),
+
+		// HA metrics
+		haMasterInfo: prometheus.NewDesc(fqAddPrefix("ha_master_info"),
+			"Shows the node that is the current HA cluster resource manager master.",
+			[]string{"node"},
+			constLabels,
+		),
+		haLRMState: prometheus.NewDesc(fqAddPrefix("ha_lrm_state"),
+			"HA local resource manager state of a node. (0=not in state,1=in state)",
+			[]string{"node", "state"},
+			constLabels,
+		),
+		haResourceState: prometheus.NewDesc(fqAddPrefix("ha_resource_state"),
+			"Current state of a HA managed resource, as seen by the HA cluster resource manager. (0=not in state,1=in state)",
+			[]string{"sid", "node", "state"},
+			constLabels,
+		),
+		haResourceRequestedState: prometheus.NewDesc(fqAddPrefix("ha_resource_requested_state"),
+			"Requested state of a HA managed resource from its HA configuration. (0=not in state,1=in state)",
+			[]string{"sid", "type", "group", "state"},
+			constLabels,
+		),
+
 		// Status metrics
 		nodeUp: prometheus.NewDesc(fqAddPrefix("node_up"),
 			"Shows whether host nodes in a proxmox cluster are up. (0=down,1=up)",
@@ -94,6 +297,18 @@ func NewCollector() *Collector {
 			constLabels,
 		),
 
+		// Guest metrics
+		guestInfo: prometheus.NewDesc(fqAddPrefix("guest_info"),
+			"Guest information including the resource pool and HA state the guest belongs to.",
+			[]string{"node", "type", "name", "vmid", "tags", "pool", "hastate"},
+			constLabels,
+		),
+		guestLock: prometheus.NewDesc(fqAddPrefix("guest_lock"),
+			"Shows guests that are currently locked, and the lock type held (backup, migrate, snapshot, rollback, etc.) (1=locked)",
+			[]string{"node", "type", "name", "vmid", "tags", "lock"},
+			constLabels,
+		),
+
 		// CPU metrics
 		clusterCPUsTotal: prometheus.NewDesc(fqAddPrefix("cluster_cpus_total"),
 			"Total number of vCPU (cores/threads) for a cluster.",
@@ -138,6 +353,33 @@ func NewCollector() *Collector {
 			constLabels,
 		),
 
+		// Pool metrics
+		poolGuests: prometheus.NewDesc(fqAddPrefix("pool_guests"),
+			"Total number of VMs and LXCs that are members of a resource pool.",
+			[]string{"pool"},
+			constLabels,
+		),
+		poolGuestsRunning: prometheus.NewDesc(fqAddPrefix("pool_guests_running"),
+			"Number of running VMs and LXCs that are members of a resource pool.",
+			[]string{"pool"},
+			constLabels,
+		),
+		poolCPUsAlloc: prometheus.NewDesc(fqAddPrefix("pool_cpus_allocated"),
+			"Total number of vCPU (cores/threads) allocated to guests in a resource pool.",
+			[]string{"pool"},
+			constLabels,
+		),
+		poolMemAlloc: prometheus.NewDesc(fqAddPrefix("pool_memory_allocated_bytes"),
+			"Total amount of memory allocated in bytes to guests in a resource pool.",
+			[]string{"pool"},
+			constLabels,
+		),
+		poolDiskAlloc: prometheus.NewDesc(fqAddPrefix("pool_disk_allocated_bytes"),
+			"Total amount of disk space allocated in bytes to guests in a resource pool.",
+			[]string{"pool"},
+			constLabels,
+		),
+
 		// Disk metrics
 		storageTotal: prometheus.NewDesc(fqAddPrefix("node_storage_total_bytes"),
 			"Total amount of storage available in a volume on a node by storage type.",
@@ -150,12 +392,433 @@ func NewCollector() *Collector {
 			constLabels,
 		),
 
+		// Backup metrics
+		guestBackupCovered: prometheus.NewDesc(fqAddPrefix("guest_backup_covered"),
+			"Shows whether a guest is included in any backup job. (0=not covered,1=covered)",
+			[]string{"node", "type", "name", "vmid", "tags"},
+			constLabels,
+		),
+		clusterGuestsNotBackedUp: prometheus.NewDesc(fqAddPrefix("cluster_guests_not_backed_up"),
+			"Number of guests that aren't included in any backup job.",
+			nil,
+			constLabels,
+		),
+		guestLastBackupSuccess: prometheus.NewDesc(fqAddPrefix("guest_last_backup_success_timestamp_seconds"),
+			"Unix time that the vzdump task of a guest's last successful backup finished, from node task history.",
+			[]string{"node", "type", "name", "vmid", "tags"},
+			constLabels,
+		),
+		guestLastBackupDuration: prometheus.NewDesc(fqAddPrefix("guest_last_backup_duration_seconds"),
+			"Duration in seconds of a guest's last successful vzdump backup, from node task history.",
+			[]string{"node", "type", "name", "vmid", "tags"},
+			constLabels,
+		),
+		guestBackupFailures: prometheus.NewDesc(fqAddPrefix("guest_backup_failures_total"),
+			"Number of failed vzdump backups of a guest found in node task history, including those still retained in it when the exporter started.",
+			[]string{"node", "type", "name", "vmid", "tags"},
+			constLabels,
+		),
+		backupTasksUnreadable: prometheus.NewDesc(fqAddPrefix("node_backup_tasks_unreadable_total"),
+			"Number of finished vzdump tasks in a node's task history, including those still retained in it when the exporter started, that were given up on after repeatedly failing to be read. Their guests' backup results are missing.",
+			[]string{"node"},
+			constLabels,
+		),
+		backupJobInfo: prometheus.NewDesc(fqAddPrefix("backup_job_info"),
+			"Backup job information including its schedule, target storage, backup mode, guest selection mode (all, exclude, pool or include) and the node it's restricted to.",
+			[]string{"id", "schedule", "storage", "mode", "selection", "node"},
+			constLabels,
+		),
+		backupJobEnabled: prometheus.NewDesc(fqAddPrefix("backup_job_enabled"),
+			"Shows whether a backup job is enabled. (0=disabled,1=enabled)",
+			[]string{"id"},
+			constLabels,
+		),
+		backupJobNextRun: prometheus.NewDesc(fqAddPrefix("backup_job_next_run_timestamp_seconds"),
+			"Unix time of the next scheduled run of an enabled backup job.",
+			[]string{"id"},
+			constLabels,
+		),
+		guestBackups: prometheus.NewDesc(fqAddPrefix("guest_backups"),
+			"Number of backups of a guest on a backup storage. The node label is empty for shared storages.",
+			[]string{"node", "storage", "vmid"},
+			constLabels,
+		),
+		guestBackupNewest: prometheus.NewDesc(fqAddPrefix("guest_backup_newest_timestamp_seconds"),
+			"Unix time the newest backup of a guest on a backup storage was created. The node label is empty for shared storages.",
+			[]string{"node", "storage", "vmid"},
+			constLabels,
+		),
+		guestBackupOldest: prometheus.NewDesc(fqAddPrefix("guest_backup_oldest_timestamp_seconds"),
+			"Unix time the oldest backup of a guest on a backup storage was created. The node label is empty for shared storages.",
+			[]string{"node", "storage", "vmid"},
+			constLabels,
+		),
+		guestBackupSize: prometheus.NewDesc(fqAddPrefix("guest_backup_size_bytes"),
+			"Total size in bytes of all backups of a guest on a backup storage. The node label is empty for shared storages.",
+			[]string{"node", "storage", "vmid"},
+			constLabels,
+		),
+
+		// Replication metrics
+		replicationLastSync: prometheus.NewDesc(fqAddPrefix("replication_last_sync_timestamp_seconds"),
+			"Unix time of the last successful sync of a storage replication job.",
+			[]string{"node", "id", "guest", "target"},
+			constLabels,
+		),
+		replicationDuration: prometheus.NewDesc(fqAddPrefix("replication_last_sync_duration_seconds"),
+			"Duration in seconds of the last sync of a storage replication job.",
+			[]string{"node", "id", "guest", "target"},
+			constLabels,
+		),
+		replicationNextSync: prometheus.NewDesc(fqAddPrefix("replication_next_sync_timestamp_seconds"),
+			"Unix time of the next scheduled sync of a storage replication job.",
+			[]string{"node", "id", "guest", "target"},
+			constLabels,
+		),
+		replicationFailCount: prometheus.NewDesc(fqAddPrefix("replication_fail_count"),
+			"Number of consecutive failed syncs of a storage replication job, reset by a successful sync.",
+			[]string{"node", "id", "guest", "target"},
+			constLabels,
+		),
+		replicationError: prometheus.NewDesc(fqAddPrefix("replication_error"),
+			"Shows whether the last sync of a storage replication job failed. (0=ok,1=error)",
+			[]string{"node", "id", "guest", "target"},
+			constLabels,
+		),
+
 		// Disk metrics
 		diskSmartHealth: prometheus.NewDesc(fqAddPrefix("node_disk_smart_status"),
 			"Disk SMART health status. (-1=UNKNOWN,0=FAIL,1=PASSED/OK)",
 			[]string{"node", "devpath"},
 			constLabels,
 		),
+		diskTemperature: prometheus.NewDesc(fqAddPrefix("node_disk_temperature_celsius"),
+			"Temperature of a disk in degrees Celsius, as reported by SMART.",
+			[]string{"node", "devpath", "model", "serial"},
+			constLabels,
+		),
+		diskPowerOnHours: prometheus.NewDesc(fqAddPrefix("node_disk_power_on_hours"),
+			"Number of hours a disk has been powered on, as reported by SMART.",
+			[]string{"node", "devpath", "model", "serial"},
+			constLabels,
+		),
+		diskReallocatedSectors: prometheus.NewDesc(fqAddPrefix("node_disk_reallocated_sectors"),
+			"Number of sectors an ATA disk has reallocated after read, write or verification errors.",
+			[]string{"node", "devpath", "model", "serial"},
+			constLabels,
+		),
+		diskPendingSectors: prometheus.NewDesc(fqAddPrefix("node_disk_pending_sectors"),
+			"Number of unstable sectors an ATA disk is waiting to reallocate.",
+			[]string{"node", "devpath", "model", "serial"},
+			constLabels,
+		),
+		diskMediaErrors: prometheus.NewDesc(fqAddPrefix("node_disk_nvme_media_errors"),
+			"Number of unrecovered data integrity errors an NVMe disk has detected.",
+			[]string{"node", "devpath", "model", "serial"},
+			constLabels,
+		),
+		diskPercentageUsed: prometheus.NewDesc(fqAddPrefix("node_disk_nvme_percentage_used"),
+			"Vendor estimate of the percentage of an NVMe disk's life that has been used. May exceed 100.",
+			[]string{"node", "devpath", "model", "serial"},
+			constLabels,
+		),
+		diskInfo: prometheus.NewDesc(fqAddPrefix("node_disk_info"),
+			"Identifying information of a disk. The usage label is what the disk is used for (ex: LVM, ZFS, partitions), or empty if it's unused.",
+			[]string{"node", "devpath", "model", "serial", "vendor", "type", "rpm", "usage"},
+			constLabels,
+		),
+		diskSize: prometheus.NewDesc(fqAddPrefix("node_disk_size_bytes"),
+			"Size of a disk in bytes.",
+			[]string{"node", "devpath"},
+			constLabels,
+		),
+		diskWearout: prometheus.NewDesc(fqAddPrefix("node_disk_wearout_ratio"),
+			"Ratio of a disk's rated life remaining, where 1 is a new disk. Only exported for disks that report it, like SSDs.",
+			[]string{"node", "devpath"},
+			constLabels,
+		),
+
+		// ZFS metrics
+		zfsPoolHealth: prometheus.NewDesc(fqAddPrefix("node_zfs_pool_health"),
+			"Health of a ZFS pool. (0=not in state,1=in state)",
+			[]string{"node", "pool", "state"},
+			constLabels,
+		),
+		zfsPoolSize: prometheus.NewDesc(fqAddPrefix("node_zfs_pool_size_bytes"),
+			"Total size in bytes of a ZFS pool.",
+			[]string{"node", "pool"},
+			constLabels,
+		),
+		zfsPoolAlloc: prometheus.NewDesc(fqAddPrefix("node_zfs_pool_allocated_bytes"),
+			"Amount of space in bytes allocated in a ZFS pool.",
+			[]string{"node", "pool"},
+			constLabels,
+		),
+		zfsPoolFree: prometheus.NewDesc(fqAddPrefix("node_zfs_pool_free_bytes"),
+			"Amount of free space in bytes in a ZFS pool.",
+			[]string{"node", "pool"},
+			constLabels,
+		),
+		zfsPoolFrag: prometheus.NewDesc(fqAddPrefix("node_zfs_pool_fragmentation_percent"),
+			"Fragmentation of the free space in a ZFS pool, in percent.",
+			[]string{"node", "pool"},
+			constLabels,
+		),
+		zfsPoolDedup: prometheus.NewDesc(fqAddPrefix("node_zfs_pool_dedup_ratio"),
+			"Deduplication ratio of a ZFS pool.",
+			[]string{"node", "pool"},
+			constLabels,
+		),
+		zfsVdevState: prometheus.NewDesc(fqAddPrefix("node_zfs_vdev_state"),
+			"State of a vdev or device in a ZFS pool. (0=not in state,1=in state)",
+			[]string{"node", "pool", "vdev", "state"},
+			constLabels,
+		),
+		zfsVdevReadErrors: prometheus.NewDesc(fqAddPrefix("node_zfs_vdev_read_errors"),
+			"Number of read errors of a vdev or device in a ZFS pool since the pool's errors were last cleared.",
+			[]string{"node", "pool", "vdev"},
+			constLabels,
+		),
+		zfsVdevWriteErrors: prometheus.NewDesc(fqAddPrefix("node_zfs_vdev_write_errors"),
+			"Number of write errors of a vdev or device in a ZFS pool since the pool's errors were last cleared.",
+			[]string{"node", "pool", "vdev"},
+			constLabels,
+		),
+		zfsVdevChecksumErrors: prometheus.NewDesc(fqAddPrefix("node_zfs_vdev_checksum_errors"),
+			"Number of checksum errors of a vdev or device in a ZFS pool since the pool's errors were last cleared.",
+			[]string{"node", "pool", "vdev"},
+			constLabels,
+		),
+
+		// LVM metrics
+		lvmThinSize: prometheus.NewDesc(fqAddPrefix("node_lvmthin_pool_size_bytes"),
+			"Size in bytes of the data volume of an LVM thin pool.",
+			[]string{"node", "vg", "lv"},
+			constLabels,
+		),
+		lvmThinUsed: prometheus.NewDesc(fqAddPrefix("node_lvmthin_pool_used_bytes"),
+			"Amount of the data volume of an LVM thin pool used in bytes.",
+			[]string{"node", "vg", "lv"},
+			constLabels,
+		),
+		lvmThinMetadataSize: prometheus.NewDesc(fqAddPrefix("node_lvmthin_pool_metadata_size_bytes"),
+			"Size in bytes of the metadata volume of an LVM thin pool.",
+			[]string{"node", "vg", "lv"},
+			constLabels,
+		),
+		lvmThinMetadataUsed: prometheus.NewDesc(fqAddPrefix("node_lvmthin_pool_metadata_used_bytes"),
+			"Amount of the metadata volume of an LVM thin pool used in bytes.",
+			[]string{"node", "vg", "lv"},
+			constLabels,
+		),
+		lvmVGSize: prometheus.NewDesc(fqAddPrefix("node_lvm_vg_size_bytes"),
+			"Total size in bytes of an LVM volume group.",
+			[]string{"node", "vg"},
+			constLabels,
+		),
+		lvmVGFree: prometheus.NewDesc(fqAddPrefix("node_lvm_vg_free_bytes"),
+			"Amount of unallocated space in bytes in an LVM volume group.",
+			[]string{"node", "vg"},
+			constLabels,
+		),
+
+		// Subscription metrics
+		subscriptionStatus: prometheus.NewDesc(fqAddPrefix("node_subscription_status"),
+			"Status of a node's subscription. (0=not in state,1=in state)",
+			[]string{"node", "status"},
+			constLabels,
+		),
+		subscriptionInfo: prometheus.NewDesc(fqAddPrefix("node_subscription_info"),
+			"Level and product name of a node's subscription. Both are empty for nodes without a subscription.",
+			[]string{"node", "level", "product"},
+			constLabels,
+		),
+		subscriptionNextDue: prometheus.NewDesc(fqAddPrefix("node_subscription_next_due_timestamp_seconds"),
+			"Unix time a node's subscription is next due for renewal, at midnight UTC on its due date.",
+			[]string{"node"},
+			constLabels,
+		),
+		subscriptionLastCheck: prometheus.NewDesc(fqAddPrefix("node_subscription_last_check_timestamp_seconds"),
+			"Unix time a node's subscription was last checked against the subscription server.",
+			[]string{"node"},
+			constLabels,
+		),
+
+		// Package metrics
+		updatesPending: prometheus.NewDesc(fqAddPrefix("node_updates_pending"),
+			"Number of packages on a node with an update available, as of the node's last package database update.",
+			[]string{"node", "origin", "priority"},
+			constLabels,
+		),
+		rebootRequired: prometheus.NewDesc(fqAddPrefix("node_reboot_required"),
+			"Shows whether a node has a newer kernel installed than the one it's running. (0=no,1=reboot required)",
+			[]string{"node"},
+			constLabels,
+		),
+		packageVersionInfo: prometheus.NewDesc(fqAddPrefix("node_package_version_info"),
+			"Installed version of a key package on a node. The proxmox-kernel package is the version of the running kernel.",
+			[]string{"node", "package", "version"},
+			constLabels,
+		),
+		packageVersionSkew: prometheus.NewDesc(fqAddPrefix("cluster_package_version_skew"),
+			"Number of different versions of a key package that the cluster's online nodes are running. Greater than 1 when nodes disagree.",
+			[]string{"package"},
+			constLabels,
+		),
+
+		// Service metrics
+		serviceState: prometheus.NewDesc(fqAddPrefix("node_service_state"),
+			"State of a PVE related service on a node. (0=not in state,1=in state)",
+			[]string{"node", "service", "state"},
+			constLabels,
+		),
+		serviceUnitState: prometheus.NewDesc(fqAddPrefix("node_service_unit_state"),
+			"State of the systemd unit file of a PVE related service on a node. (0=not in state,1=in state)",
+			[]string{"node", "service", "state"},
+			constLabels,
+		),
+
+		// Time metrics
+		nodeTimeOffset: prometheus.NewDesc(fqAddPrefix("node_time_offset_seconds"),
+			"Difference between a node's clock and the exporter's clock in seconds, compensated for the request's round trip time. Accurate to about half a second, since nodes report their time in whole seconds.",
+			[]string{"node"},
+			constLabels,
+		),
+		clusterTimeSkew: prometheus.NewDesc(fqAddPrefix("cluster_time_max_skew_seconds"),
+			"Largest difference in seconds between the clocks of the cluster's online nodes.",
+			nil,
+			constLabels,
+		),
+
+		// Network metrics
+		networkInterfaceInfo: prometheus.NewDesc(fqAddPrefix("node_network_interface_info"),
+			"Type of a node's network interface (ex: eth, bridge, bond, vlan, OVSBridge), and the mode of bonds.",
+			[]string{"node", "iface", "type", "bond_mode"},
+			constLabels,
+		),
+		networkInterfaceActive: prometheus.NewDesc(fqAddPrefix("node_network_interface_active"),
+			"Shows whether a node's network interface is active. (0=inactive,1=active)",
+			[]string{"node", "iface"},
+			constLabels,
+		),
+		networkInterfaceAutostart: prometheus.NewDesc(fqAddPrefix("node_network_interface_autostart"),
+			"Shows whether a node's network interface is configured to start on boot. (0=no,1=autostart)",
+			[]string{"node", "iface"},
+			constLabels,
+		),
+		networkBondSlaveActive: prometheus.NewDesc(fqAddPrefix("node_network_bond_slave_active"),
+			"Shows whether an interface configured as a slave of a node's bond is active. (0=inactive,1=active)",
+			[]string{"node", "iface", "slave"},
+			constLabels,
+		),
+		networkChangesPending: prometheus.NewDesc(fqAddPrefix("node_network_changes_pending"),
+			"Shows whether a node has network configuration changes that haven't been applied. (0=no,1=pending changes)",
+			[]string{"node"},
+			constLabels,
+		),
+
+		// SDN metrics
+		sdnZoneStatus: prometheus.NewDesc(fqAddPrefix("node_sdn_zone_status"),
+			"Status of an SDN zone on a node. (0=not in state,1=in state)",
+			[]string{"node", "zone", "status"},
+			constLabels,
+		),
+		sdnZoneInfo: prometheus.NewDesc(fqAddPrefix("sdn_zone_info"),
+			"Type of a configured SDN zone (ex: simple, vlan, qinq, vxlan, evpn).",
+			[]string{"zone", "type"},
+			constLabels,
+		),
+		sdnVnetInfo: prometheus.NewDesc(fqAddPrefix("sdn_vnet_info"),
+			"Zone, tag and alias of a configured SDN vnet.",
+			[]string{"vnet", "zone", "tag", "alias"},
+			constLabels,
+		),
+
+		// Firewall metrics
+		clusterFirewallEnabled: prometheus.NewDesc(fqAddPrefix("cluster_firewall_enabled"),
+			"Shows whether the cluster wide firewall is enabled. Node and guest firewalls only filter traffic while it's enabled. (0=disabled,1=enabled)",
+			nil,
+			constLabels,
+		),
+		clusterFirewallPolicy: prometheus.NewDesc(fqAddPrefix("cluster_firewall_policy"),
+			"Default policy of the cluster wide firewall for traffic in a direction. (0=not in state,1=in state)",
+			[]string{"direction", "policy"},
+			constLabels,
+		),
+		clusterFirewallRules: prometheus.NewDesc(fqAddPrefix("cluster_firewall_rules"),
+			"Number of enabled or disabled cluster wide firewall rules.",
+			[]string{"enabled"},
+			constLabels,
+		),
+		nodeFirewallEnabled: prometheus.NewDesc(fqAddPrefix("node_firewall_enabled"),
+			"Shows whether a node's firewall is enabled. (0=disabled,1=enabled)",
+			[]string{"node"},
+			constLabels,
+		),
+		nodeFirewallRules: prometheus.NewDesc(fqAddPrefix("node_firewall_rules"),
+			"Number of enabled or disabled firewall rules of a node.",
+			[]string{"node", "enabled"},
+			constLabels,
+		),
+		guestFirewallEnabled: prometheus.NewDesc(fqAddPrefix("guest_firewall_enabled"),
+			"Shows whether a guest's firewall is enabled. (0=disabled,1=enabled)",
+			[]string{"node", "type", "name", "vmid", "tags"},
+			constLabels,
+		),
+		guestFirewallPolicy: prometheus.NewDesc(fqAddPrefix("guest_firewall_policy"),
+			"Default policy of a guest's firewall for traffic in a direction. (0=not in state,1=in state)",
+			[]string{"node", "type", "name", "vmid", "tags", "direction", "policy"},
+			constLabels,
+		),
+		guestFirewallRules: prometheus.NewDesc(fqAddPrefix("guest_firewall_rules"),
+			"Number of enabled or disabled firewall rules of a guest.",
+			[]string{"node", "type", "name", "vmid", "tags", "enabled"},
+			constLabels,
+		),
+
+		// Guest configuration metrics
+		guestOnBoot: prometheus.NewDesc(fqAddPrefix("guest_onboot"),
+			"Shows whether a guest is configured to start when its node boots. (0=no,1=start on boot)",
+			[]string{"node", "type", "name", "vmid", "tags"},
+			constLabels,
+		),
+		guestProtection: prometheus.NewDesc(fqAddPrefix("guest_protection"),
+			"Shows whether a guest is protected from being removed, along with its disks. (0=unprotected,1=protected)",
+			[]string{"node", "type", "name", "vmid", "tags"},
+			constLabels,
+		),
+		guestAgentEnabled: prometheus.NewDesc(fqAddPrefix("guest_agent_enabled"),
+			"Shows whether the QEMU guest agent is enabled in a VM's configuration. (0=disabled,1=enabled)",
+			[]string{"node", "type", "name", "vmid", "tags"},
+			constLabels,
+		),
+		guestBalloonMin: prometheus.NewDesc(fqAddPrefix("guest_balloon_min_bytes"),
+			"Minimum amount of memory in bytes the balloon device may reclaim a VM's memory down to. 0 when ballooning is disabled. Only exported for VMs with the balloon option.",
+			[]string{"node", "type", "name", "vmid", "tags"},
+			constLabels,
+		),
+		guestCPUSockets: prometheus.NewDesc(fqAddPrefix("guest_cpu_sockets"),
+			"Number of CPU sockets configured for a VM.",
+			[]string{"node", "type", "name", "vmid", "tags"},
+			constLabels,
+		),
+		guestCPUCores: prometheus.NewDesc(fqAddPrefix("guest_cpu_cores"),
+			"Number of CPU cores configured for a guest, per socket for VMs. Not exported for LXCs that may use all of their node's cores.",
+			[]string{"node", "type", "name", "vmid", "tags"},
+			constLabels,
+		),
+		guestConfigInfo: prometheus.NewDesc(fqAddPrefix("guest_config_info"),
+			"CPU type, machine type and OS type configured for a guest. Options that aren't configured, and options LXCs don't have, are empty.",
+			[]string{"node", "type", "name", "vmid", "tags", "cpu_type", "machine", "ostype"},
+			constLabels,
+		),
+
+		// Guest network metrics
+		guestIPInfo: prometheus.NewDesc(fqAddPrefix("guest_ip_info"),
+			"IP addresses of a guest's network interfaces. LXCs are always reported, VMs only when guest agent metrics are enabled. Loopback and link-local addresses are left out.",
+			[]string{"node", "type", "name", "vmid", "tags", "interface", "address", "family"},
+			constLabels,
+		),
 
 		// Cert metrics
 		daysUntilCertExpiry: prometheus.NewDesc(fqAddPrefix("node_days_until_cert_expiration"),
@@ -163,6 +826,8 @@ func NewCollector() *Collector {
 			[]string{"node", "subject"},
 			constLabels,
 		),
+
+		backupHistory: newBackupHistory(),
 	}
 
 	// Enable snapshot metrics
@@ -179,6 +844,30 @@ func NewCollector() *Collector {
 		)
 	}
 
+	// Enable guest agent metrics
+	if cfg.EnableGuestAgentMetrics {
+		collector.guestAgentUp = prometheus.NewDesc(fqAddPrefix("guest_agent_up"),
+			"Shows whether the QEMU guest agent of a running VM with the agent enabled responds. (0=not responding,1=up)",
+			[]string{"node", "type", "name", "vmid", "tags"},
+			constLabels,
+		)
+		collector.guestFilesystemSize = prometheus.NewDesc(fqAddPrefix("guest_filesystem_size_bytes"),
+			"Total size in bytes of a filesystem mounted inside a VM, as reported by its QEMU guest agent.",
+			[]string{"node", "type", "name", "vmid", "tags", "mountpoint", "fstype"},
+			constLabels,
+		)
+		collector.guestFilesystemUsed = prometheus.NewDesc(fqAddPrefix("guest_filesystem_used_bytes"),
+			"Amount of space in bytes used in a filesystem mounted inside a VM, as reported by its QEMU guest agent.",
+			[]string{"node", "type", "name", "vmid", "tags", "mountpoint", "fstype"},
+			constLabels,
+		)
+		collector.guestOSInfo = prometheus.NewDesc(fqAddPrefix("guest_os_info"),
+			"Operating system running inside a VM, as reported by its QEMU guest agent. Values the guest doesn't report are empty.",
+			[]string{"node", "type", "name", "vmid", "tags", "distro", "version", "kernel", "pretty_name"},
+			constLabels,
+		)
+	}
+
 	return &collector
 }
 
@@ -187,10 +876,26 @@ func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 	// Exporter metrics
 	ch <- c.clientCount
 
+	// Cluster metrics
+	ch <- c.clusterQuorate
+	ch <- c.clusterNodesExpected
+	ch <- c.clusterNodesOnline
+	ch <- c.clusterNodeInfo
+
+	// HA metrics
+	ch <- c.haMasterInfo
+	ch <- c.haLRMState
+	ch <- c.haResourceState
+	ch <- c.haResourceRequestedState
+
 	// Status metrics
 	ch <- c.nodeUp
 	ch <- c.guestUp
 
+	// Guest metrics
+	ch <- c.guestInfo
+	ch <- c.guestLock
+
 	// CPU metrics
 	ch <- c.clusterCPUsTotal
 	ch <- c.clusterCPUsAlloc
@@ -203,18 +908,138 @@ func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.nodeMemTotal
 	ch <- c.nodeMemAlloc
 
+	// Pool metrics
+	ch <- c.poolGuests
+	ch <- c.poolGuestsRunning
+	ch <- c.poolCPUsAlloc
+	ch <- c.poolMemAlloc
+	ch <- c.poolDiskAlloc
+
 	// Storage metrics
 	ch <- c.storageTotal
 	ch <- c.storageUsed
 
+	// Backup metrics
+	ch <- c.guestBackupCovered
+	ch <- c.clusterGuestsNotBackedUp
+	ch <- c.guestLastBackupSuccess
+	ch <- c.guestLastBackupDuration
+	ch <- c.guestBackupFailures
+	ch <- c.backupTasksUnreadable
+	ch <- c.backupJobInfo
+	ch <- c.backupJobEnabled
+	ch <- c.backupJobNextRun
+	ch <- c.guestBackups
+	ch <- c.guestBackupNewest
+	ch <- c.guestBackupOldest
+	ch <- c.guestBackupSize
+
+	// Replication metrics
+	ch <- c.replicationLastSync
+	ch <- c.replicationDuration
+	ch <- c.replicationNextSync
+	ch <- c.replicationFailCount
+	ch <- c.replicationError
+
 	// Snapshot metrics
 	if cfg.EnableSnapshotMetrics {
 		ch <- c.guestSnapshotsCount
 		ch <- c.guestSnapshotAgeSeconds
 	}
 
+	// Guest agent metrics
+	if cfg.EnableGuestAgentMetrics {
+		ch <- c.guestAgentUp
+		ch <- c.guestFilesystemSize
+		ch <- c.guestFilesystemUsed
+		ch <- c.guestOSInfo
+	}
+
 	// Disk metrics
 	ch <- c.diskSmartHealth
+	ch <- c.diskTemperature
+	ch <- c.diskPowerOnHours
+	ch <- c.diskReallocatedSectors
+	ch <- c.diskPendingSectors
+	ch <- c.diskMediaErrors
+	ch <- c.diskPercentageUsed
+	ch <- c.diskInfo
+	ch <- c.diskSize
+	ch <- c.diskWearout
+
+	// ZFS metrics
+	ch <- c.zfsPoolHealth
+	ch <- c.zfsPoolSize
+	ch <- c.zfsPoolAlloc
+	ch <- c.zfsPoolFree
+	ch <- c.zfsPoolFrag
+	ch <- c.zfsPoolDedup
+	ch <- c.zfsVdevState
+	ch <- c.zfsVdevReadErrors
+	ch <- c.zfsVdevWriteErrors
+	ch <- c.zfsVdevChecksumErrors
+
+	// LVM metrics
+	ch <- c.lvmThinSize
+	ch <- c.lvmThinUsed
+	ch <- c.lvmThinMetadataSize
+	ch <- c.lvmThinMetadataUsed
+	ch <- c.lvmVGSize
+	ch <- c.lvmVGFree
+
+	// Subscription metrics
+	ch <- c.subscriptionStatus
+	ch <- c.subscriptionInfo
+	ch <- c.subscriptionNextDue
+	ch <- c.subscriptionLastCheck
+
+	// Package metrics
+	ch <- c.updatesPending
+	ch <- c.rebootRequired
+	ch <- c.packageVersionInfo
+	ch <- c.packageVersionSkew
+
+	// Service metrics
+	ch <- c.serviceState
+	ch <- c.serviceUnitState
+
+	// Time metrics
+	ch <- c.nodeTimeOffset
+	ch <- c.clusterTimeSkew
+
+	// Network metrics
+	ch <- c.networkInterfaceInfo
+	ch <- c.networkInterfaceActive
+	ch <- c.networkInterfaceAutostart
+	ch <- c.networkBondSlaveActive
+	ch <- c.networkChangesPending
+
+	// SDN metrics
+	ch <- c.sdnZoneStatus
+	ch <- c.sdnZoneInfo
+	ch <- c.sdnVnetInfo
+
+	// Firewall metrics
+	ch <- c.clusterFirewallEnabled
+	ch <- c.clusterFirewallPolicy
+	ch <- c.clusterFirewallRules
+	ch <- c.nodeFirewallEnabled
+	ch <- c.nodeFirewallRules
+	ch <- c.guestFirewallEnabled
+	ch <- c.guestFirewallPolicy
+	ch <- c.guestFirewallRules
+
+	// Guest configuration metrics
+	ch <- c.guestOnBoot
+	ch <- c.guestProtection
+	ch <- c.guestAgentEnabled
+	ch <- c.guestBalloonMin
+	ch <- c.guestCPUSockets
+	ch <- c.guestCPUCores
+	ch <- c.guestConfigInfo
+
+	// Guest network metrics
+	ch <- c.guestIPInfo
 
 	// Cert metrics
 	ch <- c.daysUntilCertExpiry
@@ -232,8 +1057,27 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
+	// Cluster quorum and membership -- only exported for clustered PVE setups
+	clusterStatus, err := wrappedProxmox.GetClusterStatus()
+	if err != nil {
+		logger.Logger.Error("failed making request to get cluster status", "error", err.Error())
+	} else {
+		c.collectClusterStatusMetrics(ch, clusterStatus)
+	}
+
+	// HA manager status and HA resource configuration
+	haStatus, err := wrappedProxmox.GetClusterHAStatus()
+	if err != nil {
+		logger.Logger.Error("failed making request to get HA status", "error", err.Error())
+	}
+	haResources, err := wrappedProxmox.GetClusterHAResources()
+	if err != nil {
+		logger.Logger.Error("failed making request to get HA resources", "error", err.Error())
+	}
+	c.collectHAMetrics(ch, haStatus, haResources)
+
 	// Categorize resources by type in a single pass
-	var nodeResources, qemuResources, lxcResources, storageResources []proxmox.GetClusterResourcesData
+	var nodeResources, qemuResources, lxcResources, storageResources, sdnResources []proxmox.GetClusterResourcesData
 	for _, r := range clusterResources.Data {
 		switch r.Type {
 		case "node":
@@ -244,8 +1088,12 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 			lxcResources = append(lxcResources, r)
 		case "storage":
 			storageResources = append(storageResources, r)
+		case "sdn":
+			sdnResources = append(sdnResources, r)
 		}
 	}
+	// VMs and LXCs together, clipped so appending the LXCs can't write into qemuResources' spare capacity
+	guestResources := append(slices.Clip(qemuResources), lxcResources...)
 
 	// Process node metrics from cluster resources
 	clusterCPUs := 0
@@ -269,6 +1117,8 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 	// Process guest metrics from cluster resources
 	vmMetrics := c.collectVirtualMachineMetrics(ch, qemuResources)
 	lxcMetrics := c.collectLxcMetrics(ch, lxcResources)
+	c.collectGuestInfoMetrics(ch, qemuResources)
+	c.collectGuestInfoMetrics(ch, lxcResources)
 
 	// Combine VM + LXC allocations per node
 	clusterCPUsAlloc := 0
@@ -289,20 +1139,76 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 		clusterMemAlloc += mem
 	}
 
+	// Backup job coverage for all guests
+	notBackedUp, err := wrappedProxmox.GetClusterBackupInfoNotBackedUp()
+	if err != nil {
+		logger.Logger.Error("failed making request to get guests not backed up", "error", err.Error())
+	} else {
+		c.collectBackupCoverageMetrics(ch, guestResources, notBackedUp)
+	}
+
+	// Backup job configuration and schedules
+	backupJobs, err := wrappedProxmox.GetClusterBackup()
+	if err != nil {
+		logger.Logger.Error("failed making request to get backup jobs", "error", err.Error())
+	} else {
+		c.collectBackupJobMetrics(ch, backupJobs)
+	}
+
+	// Combine VM + LXC allocations per resource pool
+	c.collectPoolMetrics(ch, vmMetrics, lxcMetrics)
+
 	// Process storage metrics from cluster resources
 	c.collectStorageMetrics(ch, storageResources)
 
+	// SDN zone status from cluster resources, and the zone and vnet configuration
+	c.collectSDNZoneStatusMetrics(ch, sdnResources)
+	sdnZones, err := wrappedProxmox.GetClusterSDNZones()
+	if err != nil {
+		logger.Logger.Error("failed making request to get SDN zones", "error", err.Error())
+	} else {
+		c.collectSDNZoneInfoMetrics(ch, sdnZones)
+	}
+	sdnVnets, err := wrappedProxmox.GetClusterSDNVnets()
+	if err != nil {
+		logger.Logger.Error("failed making request to get SDN vnets", "error", err.Error())
+	} else {
+		c.collectSDNVnetInfoMetrics(ch, sdnVnets)
+	}
+
+	// Cluster wide firewall options and rules
+	firewallOptions, err := wrappedProxmox.GetClusterFirewallOptions()
+	if err != nil {
+		logger.Logger.Error("failed making request to get cluster firewall options", "error", err.Error())
+	}
+	firewallRules, err := wrappedProxmox.GetClusterFirewallRules()
+	if err != nil {
+		logger.Logger.Error("failed making request to get cluster firewall rules", "error", err.Error())
+	}
+	c.collectClusterFirewallMetrics(ch, firewallOptions, firewallRules)
+
 	// Emit cluster-level metrics
 	ch <- prometheus.MustNewConstMetric(c.clusterCPUsTotal, prometheus.GaugeValue, float64(clusterCPUs))
 	ch <- prometheus.MustNewConstMetric(c.clusterCPUsAlloc, prometheus.GaugeValue, float64(clusterCPUsAlloc))
 	ch <- prometheus.MustNewConstMetric(c.clusterMemTotal, prometheus.GaugeValue, float64(clusterMem))
 	ch <- prometheus.MustNewConstMetric(c.clusterMemAlloc, prometheus.GaugeValue, float64(clusterMemAlloc))
 
-	// Per-node API calls for data not available in cluster resources (disk SMART, certs, PVE version)
+	// Per-node API calls for data not available in cluster resources (disk SMART, ZFS pools, LVM, subscription, services, network, firewall, guest configs and agents, certs, PVE version, time, package updates and versions, vzdump tasks, replication, backup storage content)
+	backupStorages := backupStoragesByNode(storageResources, onlineNodes)
+	guests := guestsByNode(guestResources)
+	packages := newPackageVersions()
+	timeOffsets := newNodeTimeOffsets()
 	var wg sync.WaitGroup
 	for _, nodeName := range onlineNodes {
 		wg.Add(1)
-		go c.collectNodeSpecificMetrics(ch, nodeName, &wg)
+		go c.collectNodeSpecificMetrics(ch, nodeName, guests[nodeName], backupStorages[nodeName], packages, timeOffsets, &wg)
 	}
 	wg.Wait()
+
+	// Package versions and clocks compared between nodes
+	c.collectPackageVersionSkewMetrics(ch, packages)
+	c.collectTimeSkewMetrics(ch, timeOffsets)
+
+	// Backup results read from each node's vzdump task history
+	c.collectBackupHistoryMetrics(ch, guestResources)
 }
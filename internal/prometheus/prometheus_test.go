@@ -16,6 +16,30 @@ func TestNewCollector(t *testing.T) {
 	if c.clientCount == nil {
 		t.Error("clientCount desc should not be nil")
 	}
+	if c.clusterQuorate == nil {
+		t.Error("clusterQuorate desc should not be nil")
+	}
+	if c.clusterNodesExpected == nil {
+		t.Error("clusterNodesExpected desc should not be nil")
+	}
+	if c.clusterNodesOnline == nil {
+		t.Error("clusterNodesOnline desc should not be nil")
+	}
+	if c.clusterNodeInfo == nil {
+		t.Error("clusterNodeInfo desc should not be nil")
+	}
+	if c.haMasterInfo == nil {
+		t.Error("haMasterInfo desc should not be nil")
+	}
+	if c.haLRMState == nil {
+		t.Error("haLRMState desc should not be nil")
+	}
+	if c.haResourceState == nil {
+		t.Error("haResourceState desc should not be nil")
+	}
+	if c.haResourceRequestedState == nil {
+		t.Error("haResourceRequestedState desc should not be nil")
+	}
 	if c.nodeUp == nil {
 		t.Error("nodeUp desc should not be nil")
 	}
@@ -25,6 +49,12 @@ func TestNewCollector(t *testing.T) {
 	if c.nodeVersion == nil {
 		t.Error("nodeVersion desc should not be nil")
 	}
+	if c.guestInfo == nil {
+		t.Error("guestInfo desc should not be nil")
+	}
+	if c.guestLock == nil {
+		t.Error("guestLock desc should not be nil")
+	}
 	if c.clusterCPUsTotal == nil {
 		t.Error("clusterCPUsTotal desc should not be nil")
 	}
@@ -49,12 +79,264 @@ func TestNewCollector(t *testing.T) {
 	if c.nodeMemAlloc == nil {
 		t.Error("nodeMemAlloc desc should not be nil")
 	}
+	if c.poolGuests == nil {
+		t.Error("poolGuests desc should not be nil")
+	}
+	if c.poolGuestsRunning == nil {
+		t.Error("poolGuestsRunning desc should not be nil")
+	}
+	if c.poolCPUsAlloc == nil {
+		t.Error("poolCPUsAlloc desc should not be nil")
+	}
+	if c.poolMemAlloc == nil {
+		t.Error("poolMemAlloc desc should not be nil")
+	}
+	if c.poolDiskAlloc == nil {
+		t.Error("poolDiskAlloc desc should not be nil")
+	}
 	if c.storageTotal == nil {
 		t.Error("storageTotal desc should not be nil")
 	}
 	if c.storageUsed == nil {
 		t.Error("storageUsed desc should not be nil")
 	}
+	if c.guestBackupCovered == nil {
+		t.Error("guestBackupCovered desc should not be nil")
+	}
+	if c.clusterGuestsNotBackedUp == nil {
+		t.Error("clusterGuestsNotBackedUp desc should not be nil")
+	}
+	if c.guestLastBackupSuccess == nil {
+		t.Error("guestLastBackupSuccess desc should not be nil")
+	}
+	if c.guestLastBackupDuration == nil {
+		t.Error("guestLastBackupDuration desc should not be nil")
+	}
+	if c.guestBackupFailures == nil {
+		t.Error("guestBackupFailures desc should not be nil")
+	}
+	if c.backupTasksUnreadable == nil {
+		t.Error("backupTasksUnreadable desc should not be nil")
+	}
+	if c.backupJobInfo == nil {
+		t.Error("backupJobInfo desc should not be nil")
+	}
+	if c.backupJobEnabled == nil {
+		t.Error("backupJobEnabled desc should not be nil")
+	}
+	if c.backupJobNextRun == nil {
+		t.Error("backupJobNextRun desc should not be nil")
+	}
+	if c.guestBackups == nil {
+		t.Error("guestBackups desc should not be nil")
+	}
+	if c.guestBackupNewest == nil {
+		t.Error("guestBackupNewest desc should not be nil")
+	}
+	if c.guestBackupOldest == nil {
+		t.Error("guestBackupOldest desc should not be nil")
+	}
+	if c.guestBackupSize == nil {
+		t.Error("guestBackupSize desc should not be nil")
+	}
+	if c.replicationLastSync == nil {
+		t.Error("replicationLastSync desc should not be nil")
+	}
+	if c.replicationDuration == nil {
+		t.Error("replicationDuration desc should not be nil")
+	}
+	if c.replicationNextSync == nil {
+		t.Error("replicationNextSync desc should not be nil")
+	}
+	if c.replicationFailCount == nil {
+		t.Error("replicationFailCount desc should not be nil")
+	}
+	if c.replicationError == nil {
+		t.Error("replicationError desc should not be nil")
+	}
+	if c.diskTemperature == nil {
+		t.Error("diskTemperature desc should not be nil")
+	}
+	if c.diskPowerOnHours == nil {
+		t.Error("diskPowerOnHours desc should not be nil")
+	}
+	if c.diskReallocatedSectors == nil {
+		t.Error("diskReallocatedSectors desc should not be nil")
+	}
+	if c.diskPendingSectors == nil {
+		t.Error("diskPendingSectors desc should not be nil")
+	}
+	if c.diskMediaErrors == nil {
+		t.Error("diskMediaErrors desc should not be nil")
+	}
+	if c.diskPercentageUsed == nil {
+		t.Error("diskPercentageUsed desc should not be nil")
+	}
+	if c.diskInfo == nil {
+		t.Error("diskInfo desc should not be nil")
+	}
+	if c.diskSize == nil {
+		t.Error("diskSize desc should not be nil")
+	}
+	if c.diskWearout == nil {
+		t.Error("diskWearout desc should not be nil")
+	}
+	if c.subscriptionStatus == nil {
+		t.Error("subscriptionStatus desc should not be nil")
+	}
+	if c.subscriptionInfo == nil {
+		t.Error("subscriptionInfo desc should not be nil")
+	}
+	if c.subscriptionNextDue == nil {
+		t.Error("subscriptionNextDue desc should not be nil")
+	}
+	if c.subscriptionLastCheck == nil {
+		t.Error("subscriptionLastCheck desc should not be nil")
+	}
+	if c.updatesPending == nil {
+		t.Error("updatesPending desc should not be nil")
+	}
+	if c.rebootRequired == nil {
+		t.Error("rebootRequired desc should not be nil")
+	}
+	if c.packageVersionInfo == nil {
+		t.Error("packageVersionInfo desc should not be nil")
+	}
+	if c.packageVersionSkew == nil {
+		t.Error("packageVersionSkew desc should not be nil")
+	}
+	if c.serviceState == nil {
+		t.Error("serviceState desc should not be nil")
+	}
+	if c.serviceUnitState == nil {
+		t.Error("serviceUnitState desc should not be nil")
+	}
+	if c.nodeTimeOffset == nil {
+		t.Error("nodeTimeOffset desc should not be nil")
+	}
+	if c.clusterTimeSkew == nil {
+		t.Error("clusterTimeSkew desc should not be nil")
+	}
+	if c.networkInterfaceInfo == nil {
+		t.Error("networkInterfaceInfo desc should not be nil")
+	}
+	if c.networkInterfaceActive == nil {
+		t.Error("networkInterfaceActive desc should not be nil")
+	}
+	if c.networkInterfaceAutostart == nil {
+		t.Error("networkInterfaceAutostart desc should not be nil")
+	}
+	if c.networkBondSlaveActive == nil {
+		t.Error("networkBondSlaveActive desc should not be nil")
+	}
+	if c.networkChangesPending == nil {
+		t.Error("networkChangesPending desc should not be nil")
+	}
+	if c.sdnZoneStatus == nil {
+		t.Error("sdnZoneStatus desc should not be nil")
+	}
+	if c.sdnZoneInfo == nil {
+		t.Error("sdnZoneInfo desc should not be nil")
+	}
+	if c.sdnVnetInfo == nil {
+		t.Error("sdnVnetInfo desc should not be nil")
+	}
+	if c.clusterFirewallEnabled == nil {
+		t.Error("clusterFirewallEnabled desc should not be nil")
+	}
+	if c.clusterFirewallPolicy == nil {
+		t.Error("clusterFirewallPolicy desc should not be nil")
+	}
+	if c.clusterFirewallRules == nil {
+		t.Error("clusterFirewallRules desc should not be nil")
+	}
+	if c.nodeFirewallEnabled == nil {
+		t.Error("nodeFirewallEnabled desc should not be nil")
+	}
+	if c.nodeFirewallRules == nil {
+		t.Error("nodeFirewallRules desc should not be nil")
+	}
+	if c.guestFirewallEnabled == nil {
+		t.Error("guestFirewallEnabled desc should not be nil")
+	}
+	if c.guestFirewallPolicy == nil {
+		t.Error("guestFirewallPolicy desc should not be nil")
+	}
+	if c.guestFirewallRules == nil {
+		t.Error("guestFirewallRules desc should not be nil")
+	}
+	if c.guestOnBoot == nil {
+		t.Error("guestOnBoot desc should not be nil")
+	}
+	if c.guestProtection == nil {
+		t.Error("guestProtection desc should not be nil")
+	}
+	if c.guestAgentEnabled == nil {
+		t.Error("guestAgentEnabled desc should not be nil")
+	}
+	if c.guestBalloonMin == nil {
+		t.Error("guestBalloonMin desc should not be nil")
+	}
+	if c.guestCPUSockets == nil {
+		t.Error("guestCPUSockets desc should not be nil")
+	}
+	if c.guestCPUCores == nil {
+		t.Error("guestCPUCores desc should not be nil")
+	}
+	if c.guestConfigInfo == nil {
+		t.Error("guestConfigInfo desc should not be nil")
+	}
+	if c.guestIPInfo == nil {
+		t.Error("guestIPInfo desc should not be nil")
+	}
+	if c.zfsPoolHealth == nil {
+		t.Error("zfsPoolHealth desc should not be nil")
+	}
+	if c.zfsPoolSize == nil {
+		t.Error("zfsPoolSize desc should not be nil")
+	}
+	if c.zfsPoolAlloc == nil {
+		t.Error("zfsPoolAlloc desc should not be nil")
+	}
+	if c.zfsPoolFree == nil {
+		t.Error("zfsPoolFree desc should not be nil")
+	}
+	if c.zfsPoolFrag == nil {
+		t.Error("zfsPoolFrag desc should not be nil")
+	}
+	if c.zfsPoolDedup == nil {
+		t.Error("zfsPoolDedup desc should not be nil")
+	}
+	if c.zfsVdevState == nil {
+		t.Error("zfsVdevState desc should not be nil")
+	}
+	if c.zfsVdevReadErrors == nil {
+		t.Error("zfsVdevReadErrors desc should not be nil")
+	}
+	if c.zfsVdevWriteErrors == nil {
+		t.Error("zfsVdevWriteErrors desc should not be nil")
+	}
+	if c.zfsVdevChecksumErrors == nil {
+		t.Error("zfsVdevChecksumErrors desc should not be nil")
+	}
+	if c.lvmThinSize == nil {
+		t.Error("lvmThinSize desc should not be nil")
+	}
+	if c.lvmThinUsed == nil {
+		t.Error("lvmThinUsed desc should not be nil")
+	}
+	if c.lvmThinMetadataSize == nil {
+		t.Error("lvmThinMetadataSize desc should not be nil")
+	}
+	if c.lvmThinMetadataUsed == nil {
+		t.Error("lvmThinMetadataUsed desc should not be nil")
+	}
+	if c.lvmVGSize == nil {
+		t.Error("lvmVGSize desc should not be nil")
+	}
+	if c.lvmVGFree == nil {
+		t.Error("lvmVGFree desc should not be nil")
+	}
 	if c.diskSmartHealth == nil {
 		t.Error("diskSmartHealth desc should not be nil")
 	}
@@ -91,6 +373,37 @@ func TestNewCollector_SnapshotsEnabled(t *testing.T) {
 	}
 }
 
+func TestNewCollector_GuestAgentDisabled(t *testing.T) {
+	oldCfg := cfg
+	cfg = Config{EnableGuestAgentMetrics: false}
+	defer func() { cfg = oldCfg }()
+
+	c := NewCollector()
+	if c.guestAgentUp != nil || c.guestFilesystemSize != nil || c.guestFilesystemUsed != nil || c.guestOSInfo != nil {
+		t.Error("guest agent descs should be nil when guest agent metrics disabled")
+	}
+}
+
+func TestNewCollector_GuestAgentEnabled(t *testing.T) {
+	oldCfg := cfg
+	cfg = Config{EnableGuestAgentMetrics: true}
+	defer func() { cfg = oldCfg }()
+
+	c := NewCollector()
+	if c.guestAgentUp == nil {
+		t.Error("guestAgentUp should not be nil when guest agent metrics enabled")
+	}
+	if c.guestFilesystemSize == nil {
+		t.Error("guestFilesystemSize should not be nil when guest agent metrics enabled")
+	}
+	if c.guestFilesystemUsed == nil {
+		t.Error("guestFilesystemUsed should not be nil when guest agent metrics enabled")
+	}
+	if c.guestOSInfo == nil {
+		t.Error("guestOSInfo should not be nil when guest agent metrics enabled")
+	}
+}
+
 func TestNewCollector_WithClusterName(t *testing.T) {
 	oldName := wrappedProxmox.ClusterName
 	wrappedProxmox.ClusterName = "test-cluster"
@@ -125,7 +438,7 @@ func TestDescribe(t *testing.T) {
 	defer func() { cfg = oldCfg }()
 
 	c := NewCollector()
-	ch := make(chan *prometheus.Desc, 100)
+	ch := make(chan *prometheus.Desc, 200)
 
 	c.Describe(ch)
 
@@ -140,7 +453,7 @@ func TestDescribe(t *testing.T) {
 		}
 	}
 
-	expectedCount := 15
+	expectedCount := 109
 	if len(descs) != expectedCount {
 		t.Errorf("expected %d descriptors, got %d", expectedCount, len(descs))
 	}
@@ -158,7 +471,7 @@ func TestDescribe_WithSnapshots(t *testing.T) {
 	defer func() { cfg = oldCfg }()
 
 	c := NewCollector()
-	ch := make(chan *prometheus.Desc, 100)
+	ch := make(chan *prometheus.Desc, 200)
 
 	c.Describe(ch)
 
@@ -173,12 +486,39 @@ func TestDescribe_WithSnapshots(t *testing.T) {
 		}
 	}
 
-	expectedCount := 17
+	expectedCount := 111
 	if len(descs) != expectedCount {
 		t.Errorf("expected %d descriptors (with snapshots), got %d", expectedCount, len(descs))
 	}
 }
 
+func TestDescribe_WithGuestAgent(t *testing.T) {
+	oldCfg := cfg
+	cfg = Config{EnableGuestAgentMetrics: true}
+	defer func() { cfg = oldCfg }()
+
+	c := NewCollector()
+	ch := make(chan *prometheus.Desc, 200)
+
+	c.Describe(ch)
+
+	var descs []*prometheus.Desc
+	draining := true
+	for draining {
+		select {
+		case d := <-ch:
+			descs = append(descs, d)
+		default:
+			draining = false
+		}
+	}
+
+	expectedCount := 113
+	if len(descs) != expectedCount {
+		t.Errorf("expected %d descriptors (with guest agent), got %d", expectedCount, len(descs))
+	}
+}
+
 func TestInit_Config(t *testing.T) {
 	tests := []struct {
 		name   string
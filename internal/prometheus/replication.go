@@ -0,0 +1,37 @@
+package prometheus
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// collectReplicationMetrics exports the status of the storage replication jobs that run on a node
+func (c *Collector) collectReplicationMetrics(ch chan<- prometheus.Metric, nodeName string, jobs *wrappedProxmox.GetNodeReplicationResponse) {
+	for _, job := range jobs.Data {
+		guest := strconv.Itoa(job.Guest)
+
+		if job.LastSync != nil && *job.LastSync > 0 {
+			ch <- prometheus.MustNewConstMetric(c.replicationLastSync, prometheus.GaugeValue, float64(*job.LastSync), nodeName, job.ID, guest, job.Target)
+		}
+		if job.Duration != nil {
+			ch <- prometheus.MustNewConstMetric(c.replicationDuration, prometheus.GaugeValue, *job.Duration, nodeName, job.ID, guest, job.Target)
+		}
+		if job.NextSync != nil && *job.NextSync > 0 {
+			ch <- prometheus.MustNewConstMetric(c.replicationNextSync, prometheus.GaugeValue, float64(*job.NextSync), nodeName, job.ID, guest, job.Target)
+		}
+
+		failCount := 0
+		if job.FailCount != nil {
+			failCount = *job.FailCount
+		}
+		ch <- prometheus.MustNewConstMetric(c.replicationFailCount, prometheus.GaugeValue, float64(failCount), nodeName, job.ID, guest, job.Target)
+
+		failed := 0.0
+		if job.Error != nil && *job.Error != "" {
+			failed = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.replicationError, prometheus.GaugeValue, failed, nodeName, job.ID, guest, job.Target)
+	}
+}
@@ -0,0 +1,66 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestCollectReplicationMetrics(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+	floatPtr := func(v float64) *float64 { return &v }
+
+	jobs := &wrappedProxmox.GetNodeReplicationResponse{
+		Data: []wrappedProxmox.GetNodeReplicationData{
+			{ID: "100-0", Guest: 100, Target: "node2", LastSync: intPtr(1704067200), NextSync: intPtr(1704068100), Duration: floatPtr(3.52), FailCount: intPtr(0)},
+			{ID: "200-0", Guest: 200, Target: "node3", LastSync: intPtr(1704060000), NextSync: intPtr(1704067500), Duration: floatPtr(1.1), FailCount: intPtr(3), Error: strPtr("no tunnel IP received")},
+			// A job that never synced successfully has a last sync of 0
+			{ID: "300-0", Guest: 300, Target: "node2", LastSync: intPtr(0), FailCount: intPtr(1), Error: strPtr("storage 'zfs' does not exist")},
+		},
+	}
+
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 100)
+	c.collectReplicationMetrics(ch, "node1", jobs)
+	metrics := drainMetrics(ch)
+
+	tests := []struct {
+		name     string
+		desc     *prometheus.Desc
+		expected map[string]float64
+	}{
+		{"last sync", c.replicationLastSync, map[string]float64{"100-0": 1704067200, "200-0": 1704060000}},
+		{"duration", c.replicationDuration, map[string]float64{"100-0": 3.52, "200-0": 1.1}},
+		{"next sync", c.replicationNextSync, map[string]float64{"100-0": 1704068100, "200-0": 1704067500}},
+		{"fail count", c.replicationFailCount, map[string]float64{"100-0": 0, "200-0": 3, "300-0": 1}},
+		{"error", c.replicationError, map[string]float64{"100-0": 0, "200-0": 1, "300-0": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := findByDesc(metrics, tt.desc)
+			if len(found) != len(tt.expected) {
+				t.Fatalf("expected %d metrics, got %d", len(tt.expected), len(found))
+			}
+			for _, m := range found {
+				labels := getMetricLabels(m)
+				if labels["node"] != "node1" {
+					t.Errorf("expected node=node1, got %s", labels["node"])
+				}
+				if v := getMetricValue(m); v != tt.expected[labels["id"]] {
+					t.Errorf("job %s: expected %f, got %f", labels["id"], tt.expected[labels["id"]], v)
+				}
+			}
+		})
+	}
+
+	// Guest and target labels identify the replicated guest and where it's replicated to
+	for _, m := range findByDesc(metrics, c.replicationError) {
+		labels := getMetricLabels(m)
+		if labels["id"] == "200-0" && (labels["guest"] != "200" || labels["target"] != "node3") {
+			t.Errorf("unexpected labels: %v", labels)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package prometheus
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// sdnZoneStates are the states of an SDN zone on a node reported in cluster resources
+var sdnZoneStates = []string{"available", "pending", "error", "unknown"}
+
+// collectSDNZoneStatusMetrics exports the status of each SDN zone on each node from cluster resources
+func (c *Collector) collectSDNZoneStatusMetrics(ch chan<- prometheus.Metric, sdnResources []proxmox.GetClusterResourcesData) {
+	for _, zone := range sdnResources {
+		if zone.SDN == nil {
+			continue
+		}
+		collectStateSet(ch, c.sdnZoneStatus, sdnZoneStates, zone.Status, zone.Node, *zone.SDN)
+	}
+}
+
+// collectSDNZoneInfoMetrics exports the type of each configured SDN zone
+func (c *Collector) collectSDNZoneInfoMetrics(ch chan<- prometheus.Metric, zones *wrappedProxmox.GetClusterSDNZonesResponse) {
+	for _, zone := range zones.Data {
+		ch <- prometheus.MustNewConstMetric(c.sdnZoneInfo, prometheus.GaugeValue, 1, zone.Zone, zone.Type)
+	}
+}
+
+// collectSDNVnetInfoMetrics exports the zone and tag of each configured SDN vnet
+func (c *Collector) collectSDNVnetInfoMetrics(ch chan<- prometheus.Metric, vnets *wrappedProxmox.GetClusterSDNVnetsResponse) {
+	for _, vnet := range vnets.Data {
+		tag := ""
+		if vnet.Tag != nil {
+			tag = strconv.Itoa(*vnet.Tag)
+		}
+		alias := ""
+		if vnet.Alias != nil {
+			alias = *vnet.Alias
+		}
+		ch <- prometheus.MustNewConstMetric(c.sdnVnetInfo, prometheus.GaugeValue, 1, vnet.Vnet, vnet.Zone, tag, alias)
+	}
+}
@@ -0,0 +1,94 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestCollectSDNZoneStatusMetrics(t *testing.T) {
+	strPtr := func(v string) *string { return &v }
+
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 100)
+
+	c.collectSDNZoneStatusMetrics(ch, []proxmox.GetClusterResourcesData{
+		{ID: "sdn/node1/zone1", Type: "sdn", Node: "node1", Status: "available", SDN: strPtr("zone1")},
+		{ID: "sdn/node2/zone1", Type: "sdn", Node: "node2", Status: "error", SDN: strPtr("zone1")},
+		{ID: "sdn/node1/zone2", Type: "sdn", Node: "node1", Status: "Pending", SDN: strPtr("zone2")},
+		{ID: "sdn/node1/unnamed", Type: "sdn", Node: "node1", Status: "available"},
+	})
+	metrics := drainMetrics(ch)
+
+	if len(metrics) != 3*len(sdnZoneStates) {
+		t.Fatalf("expected %d metrics, got %d", 3*len(sdnZoneStates), len(metrics))
+	}
+
+	expected := map[string]string{
+		"node1/zone1": "available",
+		"node2/zone1": "error",
+		"node1/zone2": "pending",
+	}
+	for _, m := range metrics {
+		labels := getMetricLabels(m)
+		want := 0.0
+		if expected[labels["node"]+"/"+labels["zone"]] == labels["status"] {
+			want = 1.0
+		}
+		if v := getMetricValue(m); v != want {
+			t.Errorf("%v: expected %f, got %f", labels, want, v)
+		}
+	}
+}
+
+func TestCollectSDNInfoMetrics(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 100)
+
+	c.collectSDNZoneInfoMetrics(ch, &wrappedProxmox.GetClusterSDNZonesResponse{
+		Data: []wrappedProxmox.GetClusterSDNZonesData{
+			{Zone: "zone1", Type: "vlan"},
+			{Zone: "zone2", Type: "evpn"},
+		},
+	})
+	c.collectSDNVnetInfoMetrics(ch, &wrappedProxmox.GetClusterSDNVnetsResponse{
+		Data: []wrappedProxmox.GetClusterSDNVnetsData{
+			{Vnet: "vnet10", Zone: "zone1", Tag: intPtr(10), Alias: strPtr("servers")},
+			{Vnet: "vnet20", Zone: "zone2"},
+		},
+	})
+	metrics := drainMetrics(ch)
+
+	zones := findByDesc(metrics, c.sdnZoneInfo)
+	if len(zones) != 2 {
+		t.Fatalf("expected 2 zone info metrics, got %d", len(zones))
+	}
+	for _, m := range zones {
+		labels := getMetricLabels(m)
+		if (labels["zone"] == "zone1" && labels["type"] != "vlan") || (labels["zone"] == "zone2" && labels["type"] != "evpn") {
+			t.Errorf("unexpected zone labels: %v", labels)
+		}
+	}
+
+	vnets := findByDesc(metrics, c.sdnVnetInfo)
+	if len(vnets) != 2 {
+		t.Fatalf("expected 2 vnet info metrics, got %d", len(vnets))
+	}
+	expected := map[string]map[string]string{
+		"vnet10": {"zone": "zone1", "tag": "10", "alias": "servers"},
+		"vnet20": {"zone": "zone2", "tag": "", "alias": ""},
+	}
+	for _, m := range vnets {
+		labels := getMetricLabels(m)
+		for k, want := range expected[labels["vnet"]] {
+			if labels[k] != want {
+				t.Errorf("vnet %s label %s: expected %q, got %q", labels["vnet"], k, want, labels[k])
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package prometheus
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// serviceStates are the states a node's service is reduced to
+var serviceStates = []string{"running", "stopped", "failed"}
+
+// serviceUnitStates are the states of a service's systemd unit file
+var serviceUnitStates = []string{"enabled", "disabled", "static", "masked", "not-found"}
+
+// collectServiceMetrics exports the state of the PVE related services on a node
+func (c *Collector) collectServiceMetrics(ch chan<- prometheus.Metric, nodeName string, services *wrappedProxmox.GetNodeServicesResponse) {
+	for _, svc := range services.Data {
+		collectStateSet(ch, c.serviceState, serviceStates, serviceState(svc), nodeName, svc.Service)
+
+		if svc.UnitState != nil {
+			collectStateSet(ch, c.serviceUnitState, serviceUnitStates, *svc.UnitState, nodeName, svc.Service)
+		}
+	}
+}
+
+// serviceState reduces a service's systemd active and sub states to running, stopped or failed
+func serviceState(svc wrappedProxmox.GetNodeServicesData) string {
+	if svc.ActiveState != nil && strings.EqualFold(*svc.ActiveState, "failed") {
+		return "failed"
+	}
+	if strings.EqualFold(svc.State, "running") {
+		return "running"
+	}
+	return "stopped"
+}
@@ -0,0 +1,75 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestCollectServiceMetrics(t *testing.T) {
+	strPtr := func(v string) *string { return &v }
+
+	services := &wrappedProxmox.GetNodeServicesResponse{
+		Data: []wrappedProxmox.GetNodeServicesData{
+			{Service: "pveproxy", State: "running", ActiveState: strPtr("active"), UnitState: strPtr("enabled")},
+			{Service: "pvestatd", State: "dead", ActiveState: strPtr("failed"), UnitState: strPtr("enabled")},
+			{Service: "pve-ha-crm", State: "dead", ActiveState: strPtr("inactive"), UnitState: strPtr("disabled")},
+			{Service: "chrony", State: "dead", ActiveState: strPtr("inactive"), UnitState: strPtr("not-found")},
+			{Service: "postfix", State: "exited", ActiveState: strPtr("active"), UnitState: strPtr("generated")},
+			// Older PVE versions only report the state
+			{Service: "pvedaemon", State: "running"},
+		},
+	}
+
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 200)
+	c.collectServiceMetrics(ch, "node1", services)
+	metrics := drainMetrics(ch)
+
+	expectedStates := map[string]string{
+		"pveproxy":   "running",
+		"pvestatd":   "failed",
+		"pve-ha-crm": "stopped",
+		"chrony":     "stopped",
+		"postfix":    "stopped",
+		"pvedaemon":  "running",
+	}
+	states := findByDesc(metrics, c.serviceState)
+	if len(states) != len(expectedStates)*len(serviceStates) {
+		t.Fatalf("serviceState: expected %d, got %d", len(expectedStates)*len(serviceStates), len(states))
+	}
+	for _, m := range states {
+		labels := getMetricLabels(m)
+		expected := 0.0
+		if labels["state"] == expectedStates[labels["service"]] {
+			expected = 1.0
+		}
+		if v := getMetricValue(m); v != expected {
+			t.Errorf("service %s state %s: expected %f, got %f", labels["service"], labels["state"], expected, v)
+		}
+	}
+
+	// The generated unit state isn't a known state, so it's exported as an extra state for postfix, and pvedaemon has no unit state
+	expectedUnitStates := map[string]string{
+		"pveproxy":   "enabled",
+		"pvestatd":   "enabled",
+		"pve-ha-crm": "disabled",
+		"chrony":     "not-found",
+		"postfix":    "generated",
+	}
+	unitStates := findByDesc(metrics, c.serviceUnitState)
+	if len(unitStates) != len(expectedUnitStates)*len(serviceUnitStates)+1 {
+		t.Fatalf("serviceUnitState: expected %d, got %d", len(expectedUnitStates)*len(serviceUnitStates)+1, len(unitStates))
+	}
+	for _, m := range unitStates {
+		labels := getMetricLabels(m)
+		expected := 0.0
+		if labels["state"] == expectedUnitStates[labels["service"]] {
+			expected = 1.0
+		}
+		if v := getMetricValue(m); v != expected {
+			t.Errorf("service %s unit state %s: expected %f, got %f", labels["service"], labels["state"], expected, v)
+		}
+	}
+}
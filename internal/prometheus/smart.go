@@ -0,0 +1,135 @@
+package prometheus
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// ATA SMART attribute IDs
+const (
+	smartAttrReallocatedSectors = 5
+	smartAttrPowerOnHours       = 9
+	smartAttrAirflowTemperature = 190
+	smartAttrTemperature        = 194
+	smartAttrPendingSectors     = 197
+)
+
+// diskSMARTValues contains the values parsed from a disk's SMART data. Values a disk doesn't report are nil
+type diskSMARTValues struct {
+	temperature        *float64
+	powerOnHours       *float64
+	reallocatedSectors *float64
+	pendingSectors     *float64
+	mediaErrors        *float64
+	percentageUsed     *float64
+}
+
+// collectDiskSMARTMetrics exports the SMART attributes of a disk that are useful for predicting its failure
+func (c *Collector) collectDiskSMARTMetrics(ch chan<- prometheus.Metric, nodeName string, disk proxmox.GetNodeDisksListData, smart *wrappedProxmox.GetNodeDisksSMARTResponse) {
+	var values diskSMARTValues
+	if strings.EqualFold(smart.Data.Type, "ata") {
+		values = parseATASMARTAttributes(smart.Data.Attributes)
+	} else if smart.Data.Text != nil {
+		values = parseSMARTText(*smart.Data.Text)
+	}
+
+	labelValues := []string{nodeName, disk.DevPath, disk.Model, disk.Serial}
+	for _, metric := range []struct {
+		desc  *prometheus.Desc
+		value *float64
+	}{
+		{c.diskTemperature, values.temperature},
+		{c.diskPowerOnHours, values.powerOnHours},
+		{c.diskReallocatedSectors, values.reallocatedSectors},
+		{c.diskPendingSectors, values.pendingSectors},
+		{c.diskMediaErrors, values.mediaErrors},
+		{c.diskPercentageUsed, values.percentageUsed},
+	} {
+		if metric.value != nil {
+			ch <- prometheus.MustNewConstMetric(metric.desc, prometheus.GaugeValue, *metric.value, labelValues...)
+		}
+	}
+}
+
+// parseATASMARTAttributes reads the attributes of an ATA disk's SMART data by their ID
+func parseATASMARTAttributes(attributes []wrappedProxmox.GetNodeDisksSMARTAttribute) diskSMARTValues {
+	var values diskSMARTValues
+	var airflowTemperature *float64
+	for _, attr := range attributes {
+		id, err := strconv.Atoi(strings.TrimSpace(string(attr.ID)))
+		if err != nil {
+			continue
+		}
+		raw, ok := parseLeadingNumber(attr.Raw)
+		if !ok {
+			continue
+		}
+
+		switch id {
+		case smartAttrReallocatedSectors:
+			values.reallocatedSectors = &raw
+		case smartAttrPowerOnHours:
+			values.powerOnHours = &raw
+		case smartAttrTemperature:
+			values.temperature = &raw
+		case smartAttrAirflowTemperature:
+			airflowTemperature = &raw
+		case smartAttrPendingSectors:
+			values.pendingSectors = &raw
+		}
+	}
+
+	// Some disks only report their temperature as the airflow temperature
+	if values.temperature == nil {
+		values.temperature = airflowTemperature
+	}
+	return values
+}
+
+// parseSMARTText reads the "key: value" lines of smartctl's text output for NVMe and SAS disks
+func parseSMARTText(text string) diskSMARTValues {
+	var values diskSMARTValues
+	for _, line := range strings.Split(text, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		v, ok := parseLeadingNumber(value)
+		if !ok {
+			continue
+		}
+
+		switch strings.TrimSpace(key) {
+		case "Temperature", "Current Drive Temperature":
+			values.temperature = &v
+		case "Power On Hours":
+			values.powerOnHours = &v
+		case "Media and Data Integrity Errors":
+			values.mediaErrors = &v
+		case "Percentage Used":
+			values.percentageUsed = &v
+		}
+	}
+	return values
+}
+
+// parseLeadingNumber parses the integer a SMART value starts with, ignoring thousands separators and anything after it (ex: "35 (Min/Max 20/50)", "1,234", "2%")
+func parseLeadingNumber(s string) (float64, bool) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s[:end], 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
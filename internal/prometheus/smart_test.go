@@ -0,0 +1,134 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestCollectDiskSMARTMetrics(t *testing.T) {
+	strPtr := func(v string) *string { return &v }
+
+	tests := []struct {
+		name     string
+		disk     proxmox.GetNodeDisksListData
+		smart    wrappedProxmox.GetNodeDisksSMARTData
+		expected map[string]float64
+	}{
+		{
+			name: "ATA disk",
+			disk: proxmox.GetNodeDisksListData{DevPath: "/dev/sda", Model: "WD HDD", Serial: "W456"},
+			smart: wrappedProxmox.GetNodeDisksSMARTData{Type: "ata", Attributes: []wrappedProxmox.GetNodeDisksSMARTAttribute{
+				{ID: "  5", Name: "Reallocated_Sector_Ct", Raw: "8"},
+				{ID: "  9", Name: "Power_On_Hours", Raw: "17520h+12m+01.500s"},
+				{ID: "190", Name: "Airflow_Temperature_Cel", Raw: "33"},
+				{ID: "194", Name: "Temperature_Celsius", Raw: "35 (Min/Max 20/50)"},
+				{ID: "197", Name: "Current_Pending_Sector", Raw: "2"},
+				{ID: "199", Name: "UDMA_CRC_Error_Count", Raw: "0"},
+			}},
+			expected: map[string]float64{
+				"temperature":        35,
+				"powerOnHours":       17520,
+				"reallocatedSectors": 8,
+				"pendingSectors":     2,
+			},
+		},
+		{
+			name: "ATA disk with only an airflow temperature",
+			disk: proxmox.GetNodeDisksListData{DevPath: "/dev/sdb", Model: "SSD", Serial: "S1"},
+			smart: wrappedProxmox.GetNodeDisksSMARTData{Type: "ata", Attributes: []wrappedProxmox.GetNodeDisksSMARTAttribute{
+				{ID: "190", Name: "Airflow_Temperature_Cel", Raw: "33"},
+			}},
+			expected: map[string]float64{"temperature": 33},
+		},
+		{
+			name: "NVMe disk",
+			disk: proxmox.GetNodeDisksListData{DevPath: "/dev/nvme0n1", Model: "Samsung SSD", Serial: "S123"},
+			smart: wrappedProxmox.GetNodeDisksSMARTData{Type: "text", Text: strPtr(
+				"SMART/Health Information (NVMe Log 0x02)\n" +
+					"Critical Warning:                   0x00\n" +
+					"Temperature:                        38 Celsius\n" +
+					"Available Spare:                    100%\n" +
+					"Percentage Used:                    2%\n" +
+					"Power On Hours:                     12,345\n" +
+					"Media and Data Integrity Errors:    1\n",
+			)},
+			expected: map[string]float64{
+				"temperature":    38,
+				"powerOnHours":   12345,
+				"mediaErrors":    1,
+				"percentageUsed": 2,
+			},
+		},
+		{
+			name:     "disk without SMART support",
+			disk:     proxmox.GetNodeDisksListData{DevPath: "/dev/sdc"},
+			smart:    wrappedProxmox.GetNodeDisksSMARTData{Health: "UNKNOWN"},
+			expected: map[string]float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCollector()
+			ch := make(chan prometheus.Metric, 20)
+
+			c.collectDiskSMARTMetrics(ch, "node1", tt.disk, &wrappedProxmox.GetNodeDisksSMARTResponse{Data: tt.smart})
+			metrics := drainMetrics(ch)
+
+			if len(metrics) != len(tt.expected) {
+				t.Fatalf("expected %d metrics, got %d", len(tt.expected), len(metrics))
+			}
+
+			descs := map[string]*prometheus.Desc{
+				"temperature":        c.diskTemperature,
+				"powerOnHours":       c.diskPowerOnHours,
+				"reallocatedSectors": c.diskReallocatedSectors,
+				"pendingSectors":     c.diskPendingSectors,
+				"mediaErrors":        c.diskMediaErrors,
+				"percentageUsed":     c.diskPercentageUsed,
+			}
+			for name, value := range tt.expected {
+				found := findByDesc(metrics, descs[name])
+				if len(found) != 1 {
+					t.Fatalf("%s: expected 1 metric, got %d", name, len(found))
+				}
+				labels := getMetricLabels(found[0])
+				if labels["node"] != "node1" || labels["devpath"] != tt.disk.DevPath || labels["model"] != tt.disk.Model || labels["serial"] != tt.disk.Serial {
+					t.Errorf("%s: unexpected labels %v", name, labels)
+				}
+				if v := getMetricValue(found[0]); v != value {
+					t.Errorf("%s: expected %f, got %f", name, value, v)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLeadingNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+		ok       bool
+	}{
+		{"42", 42, true},
+		{" 35 (Min/Max 20/50)", 35, true},
+		{"1,234", 1234, true},
+		{"2%", 2, true},
+		{"17520h+12m+01.500s", 17520, true},
+		{"0x00", 0, true},
+		{"", 0, false},
+		{"N/A", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, ok := parseLeadingNumber(tt.input)
+			if ok != tt.ok || v != tt.expected {
+				t.Errorf("expected (%f, %t), got (%f, %t)", tt.expected, tt.ok, v, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package prometheus
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// backupStorage is a storage that can hold backups
+type backupStorage struct {
+	name   string
+	shared bool
+}
+
+// guestBackups contains the totals of a guest's backups on a storage
+type guestBackups struct {
+	count  int
+	newest int
+	oldest int
+	size   int
+}
+
+// backupStoragesByNode returns the backup-capable storages to list the content of from each online node.
+// Shared storages are only assigned to the first online node they're available on, so they're listed once rather than once per node.
+func backupStoragesByNode(storageResources []proxmox.GetClusterResourcesData, onlineNodes []string) map[string][]backupStorage {
+	online := make(map[string]bool)
+	for _, node := range onlineNodes {
+		online[node] = true
+	}
+
+	listedShared := make(map[string]bool)
+	storages := make(map[string][]backupStorage)
+	for _, storage := range storageResources {
+		if storage.Storage == nil || !online[storage.Node] || !strings.EqualFold(storage.Status, "available") {
+			continue
+		}
+		if storage.Content == nil || !hasContentType(*storage.Content, "backup") {
+			continue
+		}
+
+		shared := storage.Shared != nil && *storage.Shared == 1
+		if shared {
+			if listedShared[*storage.Storage] {
+				continue
+			}
+			listedShared[*storage.Storage] = true
+		}
+		storages[storage.Node] = append(storages[storage.Node], backupStorage{
+			name:   *storage.Storage,
+			shared: shared,
+		})
+	}
+	return storages
+}
+
+// hasContentType returns true if a storage's comma separated content types include contentType
+func hasContentType(content, contentType string) bool {
+	for _, c := range strings.Split(content, ",") {
+		if strings.TrimSpace(c) == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// collectStorageBackupMetrics exports the number, age and size of each guest's backups on a storage.
+// The node label is empty for shared storages, since their backups don't belong to the node they were listed from
+func (c *Collector) collectStorageBackupMetrics(ch chan<- prometheus.Metric, nodeName string, storage backupStorage, content *wrappedProxmox.GetNodeStorageContentResponse) {
+	backups := make(map[int]*guestBackups)
+	for _, volume := range content.Data {
+		if volume.Content != "backup" || volume.VMID == nil {
+			continue
+		}
+		guest, ok := backups[*volume.VMID]
+		if !ok {
+			guest = &guestBackups{}
+			backups[*volume.VMID] = guest
+		}
+		guest.count++
+		guest.size += volume.Size
+		if volume.CTime != nil {
+			if *volume.CTime > guest.newest {
+				guest.newest = *volume.CTime
+			}
+			if guest.oldest == 0 || *volume.CTime < guest.oldest {
+				guest.oldest = *volume.CTime
+			}
+		}
+	}
+
+	node := nodeName
+	if storage.shared {
+		node = ""
+	}
+	for vmid, guest := range backups {
+		id := strconv.Itoa(vmid)
+		ch <- prometheus.MustNewConstMetric(c.guestBackups, prometheus.GaugeValue, float64(guest.count), node, storage.name, id)
+		ch <- prometheus.MustNewConstMetric(c.guestBackupSize, prometheus.GaugeValue, float64(guest.size), node, storage.name, id)
+		if guest.newest > 0 {
+			ch <- prometheus.MustNewConstMetric(c.guestBackupNewest, prometheus.GaugeValue, float64(guest.newest), node, storage.name, id)
+			ch <- prometheus.MustNewConstMetric(c.guestBackupOldest, prometheus.GaugeValue, float64(guest.oldest), node, storage.name, id)
+		}
+	}
+}
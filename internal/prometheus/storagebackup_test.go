@@ -0,0 +1,103 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestBackupStoragesByNode(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+
+	storages := []proxmox.GetClusterResourcesData{
+		{Node: "node1", Status: "available", Storage: strPtr("local"), Content: strPtr("iso,vztmpl,backup"), Shared: intPtr(0)},
+		{Node: "node1", Status: "available", Storage: strPtr("local-lvm"), Content: strPtr("images,rootdir"), Shared: intPtr(0)},
+		{Node: "node1", Status: "unknown", Storage: strPtr("nfs"), Content: strPtr("backup"), Shared: intPtr(1)},
+		{Node: "node1", Status: "available", Storage: strPtr("pbs"), Content: strPtr("backup"), Shared: intPtr(1)},
+		{Node: "node2", Status: "available", Storage: strPtr("local"), Content: strPtr("backup"), Shared: intPtr(0)},
+		{Node: "node2", Status: "available", Storage: strPtr("nfs"), Content: strPtr("backup,iso"), Shared: intPtr(1)},
+		{Node: "node2", Status: "available", Storage: strPtr("pbs"), Content: strPtr("backup"), Shared: intPtr(1)},
+		{Node: "node3", Status: "available", Storage: strPtr("local"), Content: strPtr("backup"), Shared: intPtr(0)},
+	}
+
+	// node3 is offline, so its storages aren't listed
+	result := backupStoragesByNode(storages, []string{"node1", "node2"})
+
+	expected := map[string][]backupStorage{
+		"node1": {{name: "local"}, {name: "pbs", shared: true}},
+		"node2": {{name: "local"}, {name: "nfs", shared: true}},
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("expected storages for %d nodes, got %d: %v", len(expected), len(result), result)
+	}
+	for node, want := range expected {
+		got := result[node]
+		if len(got) != len(want) {
+			t.Errorf("node %s: expected %v, got %v", node, want, got)
+			continue
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("node %s storage %d: expected %+v, got %+v", node, i, want[i], got[i])
+			}
+		}
+	}
+}
+
+func TestCollectStorageBackupMetrics(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	content := &wrappedProxmox.GetNodeStorageContentResponse{
+		Data: []wrappedProxmox.GetNodeStorageContentData{
+			{VolID: "pbs:backup/vm/100/a", Content: "backup", Size: 1000, CTime: intPtr(1704157200), VMID: intPtr(100)},
+			{VolID: "pbs:backup/vm/100/b", Content: "backup", Size: 2000, CTime: intPtr(1703984400), VMID: intPtr(100)},
+			{VolID: "pbs:backup/vm/100/c", Content: "backup", Size: 3000, CTime: intPtr(1704070800), VMID: intPtr(100)},
+			{VolID: "pbs:backup/ct/200/a", Content: "backup", Size: 500, CTime: intPtr(1704157500), VMID: intPtr(200)},
+			{VolID: "pbs:iso/debian.iso", Content: "iso", Size: 600000000},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		storage      backupStorage
+		expectedNode string
+	}{
+		{"unshared storage", backupStorage{name: "local"}, "node1"},
+		{"shared storage", backupStorage{name: "pbs", shared: true}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCollector()
+			ch := make(chan prometheus.Metric, 100)
+
+			c.collectStorageBackupMetrics(ch, "node1", tt.storage, content)
+			metrics := drainMetrics(ch)
+
+			if len(metrics) != 8 {
+				t.Fatalf("expected 8 metrics, got %d", len(metrics))
+			}
+
+			expected := map[*prometheus.Desc]map[string]float64{
+				c.guestBackups:      {"100": 3, "200": 1},
+				c.guestBackupSize:   {"100": 6000, "200": 500},
+				c.guestBackupNewest: {"100": 1704157200, "200": 1704157500},
+				c.guestBackupOldest: {"100": 1703984400, "200": 1704157500},
+			}
+			for desc, values := range expected {
+				for _, m := range findByDesc(metrics, desc) {
+					labels := getMetricLabels(m)
+					if labels["node"] != tt.expectedNode || labels["storage"] != tt.storage.name {
+						t.Errorf("unexpected labels: %v", labels)
+					}
+					if v := getMetricValue(m); v != values[labels["vmid"]] {
+						t.Errorf("%s vmid %s: expected %f, got %f", desc, labels["vmid"], values[labels["vmid"]], v)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// subscriptionStates are the statuses of a node's subscription
+var subscriptionStates = []string{"active", "new", "notfound", "expired", "invalid", "suspended"}
+
+// subscriptionLevels are the names of the subscription levels PVE abbreviates
+var subscriptionLevels = map[string]string{
+	"c": "community",
+	"b": "basic",
+	"s": "standard",
+	"p": "premium",
+}
+
+// collectSubscriptionMetrics exports the status and renewal date of a node's subscription
+func (c *Collector) collectSubscriptionMetrics(ch chan<- prometheus.Metric, nodeName string, sub *wrappedProxmox.GetNodeSubscriptionResponse) {
+	collectStateSet(ch, c.subscriptionStatus, subscriptionStates, sub.Data.Status, nodeName)
+
+	level := ""
+	if sub.Data.Level != nil {
+		level = *sub.Data.Level
+		if name, ok := subscriptionLevels[level]; ok {
+			level = name
+		}
+	}
+	productName := ""
+	if sub.Data.ProductName != nil {
+		productName = *sub.Data.ProductName
+	}
+	ch <- prometheus.MustNewConstMetric(c.subscriptionInfo, prometheus.GaugeValue, 1, nodeName, level, productName)
+
+	// The next due date is a date without a time zone, so it's read as midnight UTC like the subscription server does,
+	// regardless of the time zone job schedules are evaluated in
+	if sub.Data.NextDueDate != nil && *sub.Data.NextDueDate != "" {
+		nextDue, err := time.Parse(time.DateOnly, *sub.Data.NextDueDate)
+		if err == nil {
+			ch <- prometheus.MustNewConstMetric(c.subscriptionNextDue, prometheus.GaugeValue, float64(nextDue.Unix()), nodeName)
+		}
+	}
+	if sub.Data.CheckTime != nil && *sub.Data.CheckTime > 0 {
+		ch <- prometheus.MustNewConstMetric(c.subscriptionLastCheck, prometheus.GaugeValue, float64(*sub.Data.CheckTime), nodeName)
+	}
+}
@@ -0,0 +1,108 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestCollectSubscriptionMetrics(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+
+	// The schedule time zone doesn't apply to subscription due dates, which are read in UTC
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	oldCfg := cfg
+	cfg = Config{ScheduleLocation: loc}
+	defer func() { cfg = oldCfg }()
+
+	tests := []struct {
+		name              string
+		sub               wrappedProxmox.GetNodeSubscriptionData
+		expectedStatus    string
+		expectedLevel     string
+		expectedProduct   string
+		expectedNextDue   float64
+		expectedLastCheck float64
+		expectedCount     int
+	}{
+		{
+			name: "active subscription",
+			sub: wrappedProxmox.GetNodeSubscriptionData{
+				Status:      "Active",
+				Level:       strPtr("c"),
+				ProductName: strPtr("Proxmox VE Community Subscription 1 CPU/year"),
+				NextDueDate: strPtr("2025-03-01"),
+				CheckTime:   intPtr(1704067200),
+			},
+			expectedStatus:    "active",
+			expectedLevel:     "community",
+			expectedProduct:   "Proxmox VE Community Subscription 1 CPU/year",
+			expectedNextDue:   float64(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC).Unix()),
+			expectedLastCheck: 1704067200,
+			expectedCount:     len(subscriptionStates) + 3,
+		},
+		{
+			name:           "no subscription",
+			sub:            wrappedProxmox.GetNodeSubscriptionData{Status: "notfound", Message: strPtr("There is no subscription key")},
+			expectedStatus: "notfound",
+			expectedCount:  len(subscriptionStates) + 1,
+		},
+		{
+			name:           "invalid next due date",
+			sub:            wrappedProxmox.GetNodeSubscriptionData{Status: "expired", Level: strPtr("x"), NextDueDate: strPtr("never")},
+			expectedStatus: "expired",
+			expectedLevel:  "x",
+			expectedCount:  len(subscriptionStates) + 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCollector()
+			ch := make(chan prometheus.Metric, 20)
+
+			c.collectSubscriptionMetrics(ch, "node1", &wrappedProxmox.GetNodeSubscriptionResponse{Data: tt.sub})
+			metrics := drainMetrics(ch)
+
+			if len(metrics) != tt.expectedCount {
+				t.Fatalf("expected %d metrics, got %d", tt.expectedCount, len(metrics))
+			}
+
+			for _, m := range findByDesc(metrics, c.subscriptionStatus) {
+				labels := getMetricLabels(m)
+				expected := 0.0
+				if labels["status"] == tt.expectedStatus {
+					expected = 1.0
+				}
+				if v := getMetricValue(m); v != expected {
+					t.Errorf("status %s: expected %f, got %f", labels["status"], expected, v)
+				}
+			}
+
+			info := findByDesc(metrics, c.subscriptionInfo)
+			if len(info) != 1 {
+				t.Fatalf("expected 1 info metric, got %d", len(info))
+			}
+			labels := getMetricLabels(info[0])
+			if labels["level"] != tt.expectedLevel || labels["product"] != tt.expectedProduct {
+				t.Errorf("unexpected info labels: %v", labels)
+			}
+
+			if tt.expectedNextDue > 0 {
+				nextDue := findByDesc(metrics, c.subscriptionNextDue)
+				if len(nextDue) != 1 || getMetricValue(nextDue[0]) != tt.expectedNextDue {
+					t.Errorf("expected next due %f, got %v", tt.expectedNextDue, nextDue)
+				}
+			}
+			if tt.expectedLastCheck > 0 {
+				lastCheck := findByDesc(metrics, c.subscriptionLastCheck)
+				if len(lastCheck) != 1 || getMetricValue(lastCheck[0]) != tt.expectedLastCheck {
+					t.Errorf("expected last check %f, got %v", tt.expectedLastCheck, lastCheck)
+				}
+			}
+		})
+	}
+}
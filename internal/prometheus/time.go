@@ -0,0 +1,58 @@
+package prometheus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// nodeTimeOffsets contains the clock offset of each node, gathered during a scrape to compare them between nodes
+type nodeTimeOffsets struct {
+	mu      sync.Mutex
+	offsets map[string]float64
+}
+
+func newNodeTimeOffsets() *nodeTimeOffsets {
+	return &nodeTimeOffsets{offsets: make(map[string]float64)}
+}
+
+// add records the clock offset of a node
+func (o *nodeTimeOffsets) add(nodeName string, offset float64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.offsets[nodeName] = offset
+}
+
+// collectNodeTimeMetrics exports the offset of a node's clock from the exporter's clock, and records it to compare between nodes
+func (c *Collector) collectNodeTimeMetrics(ch chan<- prometheus.Metric, nodeName string, nodeTime *wrappedProxmox.GetNodeTimeResponse, offsets *nodeTimeOffsets) {
+	// The node's time is truncated to whole seconds, so half a second is added to center its error around 0
+	nodeClock := float64(nodeTime.Data.Time) + 0.5
+	offset := nodeClock - float64(nodeTime.RequestTime.UnixNano())/float64(time.Second)
+
+	ch <- prometheus.MustNewConstMetric(c.nodeTimeOffset, prometheus.GaugeValue, offset, nodeName)
+	offsets.add(nodeName, offset)
+}
+
+// collectTimeSkewMetrics exports the largest difference between the clocks of the cluster's nodes
+func (c *Collector) collectTimeSkewMetrics(ch chan<- prometheus.Metric, offsets *nodeTimeOffsets) {
+	offsets.mu.Lock()
+	defer offsets.mu.Unlock()
+
+	if len(offsets.offsets) == 0 {
+		return
+	}
+	first := true
+	var lowest, highest float64
+	for _, offset := range offsets.offsets {
+		if first || offset < lowest {
+			lowest = offset
+		}
+		if first || offset > highest {
+			highest = offset
+		}
+		first = false
+	}
+	ch <- prometheus.MustNewConstMetric(c.clusterTimeSkew, prometheus.GaugeValue, highest-lowest)
+}
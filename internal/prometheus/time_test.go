@@ -0,0 +1,84 @@
+package prometheus
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestCollectNodeTimeMetrics(t *testing.T) {
+	requestTime := time.Unix(1704067200, 250*int64(time.Millisecond))
+
+	tests := []struct {
+		name           string
+		nodeTime       int
+		expectedOffset float64
+	}{
+		{"in sync", 1704067200, 0.25},
+		{"ahead", 1704067205, 5.25},
+		{"behind", 1704067190, -9.75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCollector()
+			ch := make(chan prometheus.Metric, 10)
+			offsets := newNodeTimeOffsets()
+
+			c.collectNodeTimeMetrics(ch, "node1", &wrappedProxmox.GetNodeTimeResponse{
+				Data:        wrappedProxmox.GetNodeTimeData{Time: tt.nodeTime},
+				RequestTime: requestTime,
+			}, offsets)
+			metrics := drainMetrics(ch)
+
+			if len(metrics) != 1 {
+				t.Fatalf("expected 1 metric, got %d", len(metrics))
+			}
+			if v := getMetricValue(metrics[0]); math.Abs(v-tt.expectedOffset) > 1e-6 {
+				t.Errorf("expected offset %f, got %f", tt.expectedOffset, v)
+			}
+			if v := offsets.offsets["node1"]; math.Abs(v-tt.expectedOffset) > 1e-6 {
+				t.Errorf("expected recorded offset %f, got %f", tt.expectedOffset, v)
+			}
+		})
+	}
+}
+
+func TestCollectTimeSkewMetrics(t *testing.T) {
+	tests := []struct {
+		name          string
+		offsets       map[string]float64
+		expectedCount int
+		expectedSkew  float64
+	}{
+		{"no nodes", map[string]float64{}, 0, 0},
+		{"single node", map[string]float64{"node1": 3}, 1, 0},
+		{"nodes ahead and behind", map[string]float64{"node1": 0.5, "node2": -1.5, "node3": 2}, 1, 3.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCollector()
+			ch := make(chan prometheus.Metric, 10)
+			offsets := newNodeTimeOffsets()
+			for node, offset := range tt.offsets {
+				offsets.add(node, offset)
+			}
+
+			c.collectTimeSkewMetrics(ch, offsets)
+			metrics := drainMetrics(ch)
+
+			if len(metrics) != tt.expectedCount {
+				t.Fatalf("expected %d metrics, got %d", tt.expectedCount, len(metrics))
+			}
+			if tt.expectedCount > 0 {
+				if v := getMetricValue(metrics[0]); v != tt.expectedSkew {
+					t.Errorf("expected skew %f, got %f", tt.expectedSkew, v)
+				}
+			}
+		})
+	}
+}
@@ -2,7 +2,13 @@ package prometheus
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
 )
 
 // fqPrefix is the prefix to all metrics exported by this tool
@@ -20,3 +26,122 @@ func daysUntilUnixTime(notAfter int) int {
 	differenceDays := differenceSeconds / (60 * 60 * 24)
 	return int(differenceDays)
 }
+
+// collectStateSet sends one metric per state of a stateset, where the current state has a value of 1 and all other known states 0.
+// The state label must be the last label of the descriptor. A current state that isn't known is also sent so it isn't lost.
+func collectStateSet(ch chan<- prometheus.Metric, desc *prometheus.Desc, states []string, current string, labelValues ...string) {
+	known := false
+	for _, state := range states {
+		value := 0.0
+		if strings.EqualFold(state, current) {
+			value = 1.0
+			known = true
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, append(labelValues, state)...)
+	}
+	if !known && current != "" {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1.0, append(labelValues, strings.ToLower(current))...)
+	}
+}
+
+// parseIntOrString parses a numeric field that the API may return as either a number or a string.
+// Returns false for values that aren't numbers (ex: "N/A", "")
+func parseIntOrString(v proxmox.IntOrString) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(string(v)), 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+// compareVersions compares two package versions, returning -1, 0 or 1 if a is older than, the same as, or newer than b.
+// Runs of digits are compared numerically and everything else lexically, so 6.5.13-1 is newer than 6.5.9-2
+func compareVersions(a, b string) int {
+	for a != "" || b != "" {
+		var segA, segB string
+		segA, a = nextVersionSegment(a)
+		segB, b = nextVersionSegment(b)
+
+		numA, errA := strconv.Atoi(segA)
+		numB, errB := strconv.Atoi(segB)
+		switch {
+		case errA == nil && errB == nil:
+			if numA != numB {
+				if numA < numB {
+					return -1
+				}
+				return 1
+			}
+		case segA != segB:
+			if segA < segB {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// nextVersionSegment splits the leading run of digits or non-digits off of a version
+func nextVersionSegment(v string) (segment, rest string) {
+	if v == "" {
+		return "", ""
+	}
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	digits := isDigit(v[0])
+	end := 1
+	for end < len(v) && isDigit(v[end]) == digits {
+		end++
+	}
+	return v[:end], v[end:]
+}
+
+// splitTags splits a guest's tags string into individual tags. PVE separates tags with semicolons, but also accepts commas and spaces.
+func splitTags(tags string) []string {
+	return strings.FieldsFunc(tags, func(r rune) bool {
+		return r == ';' || r == ',' || r == ' '
+	})
+}
+
+// hasAnyTag returns true if a guest's tags string contains any of the given tags
+func hasAnyTag(tags string, match []string) bool {
+	if len(match) == 0 {
+		return false
+	}
+	for _, tag := range splitTags(tags) {
+		for _, m := range match {
+			if strings.EqualFold(tag, m) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// forEachConcurrently calls fn with each index from 0 up to n, with at most workers calls running at once, and returns once they all returned
+func forEachConcurrently(n, workers int, fn func(i int)) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := range n {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// scheduleLocation returns the time zone that PVE job schedules are evaluated in
+func scheduleLocation() *time.Location {
+	if cfg.ScheduleLocation != nil {
+		return cfg.ScheduleLocation
+	}
+	return time.Local
+}
@@ -1,8 +1,12 @@
 package prometheus
 
 import (
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
 )
 
 func TestFqAddPrefix(t *testing.T) {
@@ -63,3 +67,170 @@ func TestDaysUntilUnixTime_Epoch(t *testing.T) {
 		t.Errorf("days until epoch should be negative, got %d", result)
 	}
 }
+
+func TestCollectStateSet(t *testing.T) {
+	desc := prometheus.NewDesc("test_state", "test", []string{"node", "state"}, nil)
+	states := []string{"ok", "degraded", "failed"}
+
+	tests := []struct {
+		name          string
+		current       string
+		expectedCount int
+		expectedOn    string
+	}{
+		{"known state", "degraded", 3, "degraded"},
+		{"known state case insensitive", "FAILED", 3, "failed"},
+		{"unknown state is added", "rebuilding", 4, "rebuilding"},
+		{"empty state", "", 3, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan prometheus.Metric, 10)
+			collectStateSet(ch, desc, states, tt.current, "node1")
+
+			metrics := drainMetrics(ch)
+			if len(metrics) != tt.expectedCount {
+				t.Fatalf("expected %d metrics, got %d", tt.expectedCount, len(metrics))
+			}
+			for _, m := range metrics {
+				labels := getMetricLabels(m)
+				if labels["node"] != "node1" {
+					t.Errorf("expected node=node1, got %s", labels["node"])
+				}
+				if (labels["state"] == tt.expectedOn) != (getMetricValue(m) == 1) {
+					t.Errorf("unexpected value for state=%s: %f", labels["state"], getMetricValue(m))
+				}
+			}
+		})
+	}
+}
+
+func TestParseIntOrString(t *testing.T) {
+	tests := []struct {
+		input    proxmox.IntOrString
+		expected float64
+		ok       bool
+	}{
+		{"95", 95, true},
+		{"7200", 7200, true},
+		{"-1", -1, true},
+		{"N/A", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.input), func(t *testing.T) {
+			v, ok := parseIntOrString(tt.input)
+			if ok != tt.ok || v != tt.expected {
+				t.Errorf("expected (%f, %t), got (%f, %t)", tt.expected, tt.ok, v, ok)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"6.5.13-1", "6.5.13-1", 0},
+		{"6.5.13-1", "6.5.11-8", 1},
+		{"6.5.9-2", "6.5.13-1", -1},
+		{"6.8.4-2", "6.5.13-1", 1},
+		{"8.1.4", "8.1.10", -1},
+		{"18.2.1-pve2", "18.2.1-pve1", 1},
+		{"6.5.13-1", "6.5.13", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := compareVersions(tt.a, tt.b); got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSplitTags(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"prod;web", []string{"prod", "web"}},
+		{"prod,web db", []string{"prod", "web", "db"}},
+		{"single", []string{"single"}},
+		{"", nil},
+		{";;", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := splitTags(tt.input)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("splitTags(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("splitTags(%q) = %v, want %v", tt.input, got, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestHasAnyTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     string
+		match    []string
+		expected bool
+	}{
+		{"match", "prod;web", []string{"web"}, true},
+		{"case insensitive", "prod;Web", []string{"WEB"}, true},
+		{"no match", "prod;web", []string{"dev"}, false},
+		{"partial tag does not match", "production", []string{"prod"}, false},
+		{"no match list", "prod", nil, false},
+		{"no tags", "", []string{"prod"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasAnyTag(tt.tags, tt.match); got != tt.expected {
+				t.Errorf("hasAnyTag(%q, %v) = %v, want %v", tt.tags, tt.match, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestForEachConcurrently(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	called := make([]atomic.Int32, 20)
+	forEachConcurrently(len(called), 3, func(i int) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		called[i].Add(1)
+	})
+
+	for i := range called {
+		if got := called[i].Load(); got != 1 {
+			t.Errorf("index %d: expected 1 call, got %d", i, got)
+		}
+	}
+	if got := maxInFlight.Load(); got > 3 {
+		t.Errorf("expected at most 3 calls running at once, got %d", got)
+	}
+
+	// No items doesn't start any workers or call fn
+	forEachConcurrently(0, 3, func(int) {
+		t.Error("unexpected call")
+	})
+}
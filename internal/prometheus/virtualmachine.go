@@ -11,18 +11,56 @@ import (
 	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
 )
 
-// collectGuestMetricsResponse tracks per-node CPU/memory allocations for both VMs and LXCs
+// collectGuestMetricsResponse tracks per-node and per-pool allocations for both VMs and LXCs
 type collectGuestMetricsResponse struct {
 	cpusPerNode map[string]int
 	memPerNode  map[string]int
+
+	guestsPerPool  map[string]int
+	runningPerPool map[string]int
+	cpusPerPool    map[string]int
+	memPerPool     map[string]int
+	diskPerPool    map[string]int
+}
+
+// newCollectGuestMetricsResponse returns a collectGuestMetricsResponse with all of its maps initialized
+func newCollectGuestMetricsResponse() *collectGuestMetricsResponse {
+	return &collectGuestMetricsResponse{
+		cpusPerNode:    make(map[string]int),
+		memPerNode:     make(map[string]int),
+		guestsPerPool:  make(map[string]int),
+		runningPerPool: make(map[string]int),
+		cpusPerPool:    make(map[string]int),
+		memPerPool:     make(map[string]int),
+		diskPerPool:    make(map[string]int),
+	}
+}
+
+// addPoolAllocation adds a guest's allocations to the totals of the resource pool it belongs to, if any
+func (r *collectGuestMetricsResponse) addPoolAllocation(guest proxmox.GetClusterResourcesData) {
+	if guest.Pool == nil || *guest.Pool == "" {
+		return
+	}
+	pool := *guest.Pool
+
+	r.guestsPerPool[pool]++
+	if strings.EqualFold(guest.Status, "running") {
+		r.runningPerPool[pool]++
+	}
+	if guest.MaxCPU != nil {
+		r.cpusPerPool[pool] += *guest.MaxCPU
+	}
+	if guest.MaxMem != nil {
+		r.memPerPool[pool] += *guest.MaxMem
+	}
+	if guest.MaxDisk != nil {
+		r.diskPerPool[pool] += *guest.MaxDisk
+	}
 }
 
 // collectVirtualMachineMetrics processes qemu entries from cluster resources
 func (c *Collector) collectVirtualMachineMetrics(ch chan<- prometheus.Metric, vms []proxmox.GetClusterResourcesData) *collectGuestMetricsResponse {
-	res := &collectGuestMetricsResponse{
-		cpusPerNode: make(map[string]int),
-		memPerNode:  make(map[string]int),
-	}
+	res := newCollectGuestMetricsResponse()
 	for _, vm := range vms {
 		name := ""
 		if vm.Name != nil {
@@ -54,6 +92,7 @@ func (c *Collector) collectVirtualMachineMetrics(ch chan<- prometheus.Metric, vm
 		if vm.MaxMem != nil {
 			res.memPerNode[vm.Node] += *vm.MaxMem
 		}
+		res.addPoolAllocation(vm)
 
 		if cfg.EnableSnapshotMetrics {
 			c.collectQemuSnapshotMetrics(ch, vm.Node, name, vmid, tags)
@@ -0,0 +1,73 @@
+package prometheus
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+// zfsStates are the states of a ZFS pool or vdev reported by zpool status
+var zfsStates = []string{"ONLINE", "DEGRADED", "FAULTED", "OFFLINE", "UNAVAIL", "REMOVED"}
+
+// zfsCountSuffixes are the multipliers of the suffixes zpool status abbreviates large error counts with
+var zfsCountSuffixes = map[byte]float64{
+	'K': 1e3,
+	'M': 1e6,
+	'G': 1e9,
+	'T': 1e12,
+}
+
+// collectZFSPoolMetrics exports the health and usage of a node's ZFS pool
+func (c *Collector) collectZFSPoolMetrics(ch chan<- prometheus.Metric, nodeName string, pool wrappedProxmox.GetNodeDisksZFSData) {
+	collectStateSet(ch, c.zfsPoolHealth, zfsStates, pool.Health, nodeName, pool.Name)
+	ch <- prometheus.MustNewConstMetric(c.zfsPoolSize, prometheus.GaugeValue, float64(pool.Size), nodeName, pool.Name)
+	ch <- prometheus.MustNewConstMetric(c.zfsPoolAlloc, prometheus.GaugeValue, float64(pool.Alloc), nodeName, pool.Name)
+	ch <- prometheus.MustNewConstMetric(c.zfsPoolFree, prometheus.GaugeValue, float64(pool.Free), nodeName, pool.Name)
+	ch <- prometheus.MustNewConstMetric(c.zfsPoolFrag, prometheus.GaugeValue, float64(pool.Frag), nodeName, pool.Name)
+	ch <- prometheus.MustNewConstMetric(c.zfsPoolDedup, prometheus.GaugeValue, pool.Dedup, nodeName, pool.Name)
+}
+
+// collectZFSVdevMetrics exports the state and error counts of every vdev and device in a ZFS pool's tree
+func (c *Collector) collectZFSVdevMetrics(ch chan<- prometheus.Metric, nodeName string, detail *wrappedProxmox.GetNodeDisksZFSDetailResponse) {
+	seen := make(map[string]bool)
+	var walk func(children []wrappedProxmox.GetNodeDisksZFSDetailChild)
+	walk = func(children []wrappedProxmox.GetNodeDisksZFSDetailChild) {
+		for _, vdev := range children {
+			// Section headers like logs, cache and spares don't have a state of their own
+			if vdev.State != nil && !seen[vdev.Name] {
+				seen[vdev.Name] = true
+				collectStateSet(ch, c.zfsVdevState, zfsStates, *vdev.State, nodeName, detail.Data.Name, vdev.Name)
+				ch <- prometheus.MustNewConstMetric(c.zfsVdevReadErrors, prometheus.GaugeValue, parseZFSErrorCount(vdev.Read), nodeName, detail.Data.Name, vdev.Name)
+				ch <- prometheus.MustNewConstMetric(c.zfsVdevWriteErrors, prometheus.GaugeValue, parseZFSErrorCount(vdev.Write), nodeName, detail.Data.Name, vdev.Name)
+				ch <- prometheus.MustNewConstMetric(c.zfsVdevChecksumErrors, prometheus.GaugeValue, parseZFSErrorCount(vdev.Cksum), nodeName, detail.Data.Name, vdev.Name)
+			}
+			walk(vdev.Children)
+		}
+	}
+	walk(detail.Data.Children)
+}
+
+// parseZFSErrorCount parses an error count from zpool status, which may be abbreviated with a suffix (ex: 1.2K)
+func parseZFSErrorCount(count *proxmox.IntOrString) float64 {
+	if count == nil {
+		return 0
+	}
+	s := strings.TrimSpace(string(*count))
+	if s == "" {
+		return 0
+	}
+
+	multiplier := 1.0
+	if m, ok := zfsCountSuffixes[s[len(s)-1]]; ok {
+		multiplier = m
+		s = s[:len(s)-1]
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return v * multiplier
+}
@@ -0,0 +1,148 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	proxmox "github.com/starttoaster/go-proxmox"
+	wrappedProxmox "github.com/starttoaster/proxmox-exporter/internal/proxmox"
+)
+
+func TestCollectZFSPoolMetrics(t *testing.T) {
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 100)
+
+	c.collectZFSPoolMetrics(ch, "node1", wrappedProxmox.GetNodeDisksZFSData{
+		Name:   "rpool",
+		Health: "DEGRADED",
+		Size:   1000,
+		Alloc:  400,
+		Free:   600,
+		Frag:   12,
+		Dedup:  1.5,
+	})
+	metrics := drainMetrics(ch)
+
+	if len(metrics) != len(zfsStates)+5 {
+		t.Fatalf("expected %d metrics, got %d", len(zfsStates)+5, len(metrics))
+	}
+	for _, m := range findByDesc(metrics, c.zfsPoolHealth) {
+		labels := getMetricLabels(m)
+		expected := 0.0
+		if labels["state"] == "DEGRADED" {
+			expected = 1.0
+		}
+		if v := getMetricValue(m); v != expected {
+			t.Errorf("state %s: expected %f, got %f", labels["state"], expected, v)
+		}
+	}
+
+	expected := map[*prometheus.Desc]float64{
+		c.zfsPoolSize:  1000,
+		c.zfsPoolAlloc: 400,
+		c.zfsPoolFree:  600,
+		c.zfsPoolFrag:  12,
+		c.zfsPoolDedup: 1.5,
+	}
+	for desc, value := range expected {
+		found := findByDesc(metrics, desc)
+		if len(found) != 1 {
+			t.Fatalf("%s: expected 1 metric, got %d", desc, len(found))
+		}
+		if labels := getMetricLabels(found[0]); labels["node"] != "node1" || labels["pool"] != "rpool" {
+			t.Errorf("%s: unexpected labels %v", desc, labels)
+		}
+		if v := getMetricValue(found[0]); v != value {
+			t.Errorf("%s: expected %f, got %f", desc, value, v)
+		}
+	}
+}
+
+func TestCollectZFSVdevMetrics(t *testing.T) {
+	strPtr := func(v string) *string { return &v }
+	countPtr := func(v string) *proxmox.IntOrString { c := proxmox.IntOrString(v); return &c }
+
+	detail := &wrappedProxmox.GetNodeDisksZFSDetailResponse{
+		Data: wrappedProxmox.GetNodeDisksZFSDetailData{
+			Name:  "tank",
+			State: "DEGRADED",
+			Children: []wrappedProxmox.GetNodeDisksZFSDetailChild{
+				{Name: "tank", State: strPtr("DEGRADED"), Read: countPtr("0"), Write: countPtr("0"), Cksum: countPtr("0"), Children: []wrappedProxmox.GetNodeDisksZFSDetailChild{
+					{Name: "mirror-0", State: strPtr("DEGRADED"), Read: countPtr("0"), Write: countPtr("0"), Cksum: countPtr("0"), Children: []wrappedProxmox.GetNodeDisksZFSDetailChild{
+						{Name: "sda", State: strPtr("ONLINE"), Read: countPtr("0"), Write: countPtr("0"), Cksum: countPtr("0")},
+						{Name: "sdb", State: strPtr("FAULTED"), Read: countPtr("3"), Write: countPtr("1.2K"), Cksum: countPtr("27")},
+					}},
+				}},
+				// Section headers don't have a state, and a spare is listed both in use and under spares
+				{Name: "spares", Children: []wrappedProxmox.GetNodeDisksZFSDetailChild{
+					{Name: "sdc", State: strPtr("AVAIL")},
+					{Name: "sdc", State: strPtr("AVAIL")},
+				}},
+			},
+		},
+	}
+
+	c := testCollector()
+	ch := make(chan prometheus.Metric, 200)
+	c.collectZFSVdevMetrics(ch, "node1", detail)
+	metrics := drainMetrics(ch)
+
+	// tank, mirror-0, sda, sdb and sdc, each with a stateset and 3 error counts.
+	// sdc's AVAIL state isn't one of the known states, so it's exported as an extra state
+	expectedCount := 5*(len(zfsStates)+3) + 1
+	if len(metrics) != expectedCount {
+		t.Fatalf("expected %d metrics, got %d", expectedCount, len(metrics))
+	}
+
+	for _, m := range findByDesc(metrics, c.zfsVdevState) {
+		labels := getMetricLabels(m)
+		if labels["pool"] != "tank" {
+			t.Errorf("expected pool tank, got %s", labels["pool"])
+		}
+		if labels["vdev"] == "sdb" && labels["state"] == "FAULTED" && getMetricValue(m) != 1 {
+			t.Error("expected sdb to be FAULTED")
+		}
+	}
+
+	expected := map[*prometheus.Desc]float64{
+		c.zfsVdevReadErrors:     3,
+		c.zfsVdevWriteErrors:    1200,
+		c.zfsVdevChecksumErrors: 27,
+	}
+	for desc, value := range expected {
+		for _, m := range findByDesc(metrics, desc) {
+			want := 0.0
+			if getMetricLabels(m)["vdev"] == "sdb" {
+				want = value
+			}
+			if v := getMetricValue(m); v != want {
+				t.Errorf("%s vdev %s: expected %f, got %f", desc, getMetricLabels(m)["vdev"], want, v)
+			}
+		}
+	}
+}
+
+func TestParseZFSErrorCount(t *testing.T) {
+	countPtr := func(v string) *proxmox.IntOrString { c := proxmox.IntOrString(v); return &c }
+
+	tests := []struct {
+		name     string
+		count    *proxmox.IntOrString
+		expected float64
+	}{
+		{"nil", nil, 0},
+		{"empty", countPtr(""), 0},
+		{"plain", countPtr("42"), 42},
+		{"thousands", countPtr("1.2K"), 1200},
+		{"millions", countPtr("3M"), 3000000},
+		{"invalid", countPtr("n/a"), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseZFSErrorCount(tt.count); got != tt.expected {
+				t.Errorf("expected %f, got %f", tt.expected, got)
+			}
+		})
+	}
+}
@@ -3,9 +3,11 @@ package proxmox
 import (
 	"crypto/tls"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/patrickmn/go-cache"
@@ -19,6 +21,7 @@ var (
 	ClusterName string
 
 	clients     map[string]wrappedClient
+	clientsMu   sync.RWMutex
 	banDuration = time.Duration(1 * time.Minute)
 	cash        *cache.Cache
 )
@@ -47,7 +50,7 @@ func Init(endpoints []string, tokenID, token string, tlsVerify bool) error {
 	}
 
 	// Make and init proxmox client map
-	clients = make(map[string]wrappedClient)
+	newClients := make(map[string]wrappedClient)
 	for _, endpoint := range endpoints {
 		// Parse URL for hostname
 		parsedURL, err := url.Parse(endpoint)
@@ -67,10 +70,13 @@ func Init(endpoints []string, tokenID, token string, tlsVerify bool) error {
 		}
 
 		// Add client to map
-		clients[hostname] = wrappedClient{
+		newClients[hostname] = wrappedClient{
 			client: c,
 		}
 	}
+	clientsMu.Lock()
+	clients = newClients
+	clientsMu.Unlock()
 
 	// init cache -- at longest, cache will live for 29 seconds
 	// which should ensure metrics are updated if scraping in 30 second intervals
@@ -113,7 +119,7 @@ func retrieveClusterName() {
 func refreshClientBans() {
 	for {
 		// Loop through clients from client map
-		for name, c := range clients {
+		for name, c := range currentClients() {
 			// Check if the client is banned -- if banned we need to check if the client's banUntil time has expired
 			if c.banned && time.Now().After(c.bannedUntil) {
 				// If the ban expired, make a request, see if it succeeds, and unban it if successful. Increase the ban timer if not
@@ -121,9 +127,11 @@ func refreshClientBans() {
 				if err == nil {
 					// Unban client - request successful
 					log.Logger.Debug("unbanning client, test request successful", "name", name)
+					clientsMu.Lock()
 					clients[name] = wrappedClient{
 						client: c.client,
 					}
+					clientsMu.Unlock()
 					continue
 				} else {
 					// Re-up ban timer - request failed
@@ -141,9 +149,18 @@ func refreshClientBans() {
 // banClient bans a client for the defined duration
 func banClient(name string, c wrappedClient) {
 	log.Logger.Debug("banning client", "name", name, "duration", banDuration)
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
 	clients[name] = wrappedClient{
 		client:      c.client,
 		banned:      true,
 		bannedUntil: time.Now().Add(banDuration),
 	}
 }
+
+// currentClients returns a copy of the client map, which requests made concurrently can iterate over while clients are banned
+func currentClients() map[string]wrappedClient {
+	clientsMu.RLock()
+	defer clientsMu.RUnlock()
+	return maps.Clone(clients)
+}
@@ -2,6 +2,7 @@ package proxmox
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/patrickmn/go-cache"
 	proxmox "github.com/starttoaster/go-proxmox"
@@ -23,7 +24,7 @@ func GetClusterStatus() (*proxmox.GetClusterStatusResponse, error) {
 
 	// Make request if not found in cache
 	var err error
-	for clientName, c := range clients {
+	for clientName, c := range currentClients() {
 		// Check if client was banned, skip if is
 		if c.banned {
 			continue
@@ -65,7 +66,7 @@ func GetClusterResources() (*proxmox.GetClusterResourcesResponse, error) {
 
 	// Make request if not found in cache
 	var err error
-	for clientName, c := range clients {
+	for clientName, c := range currentClients() {
 		// Check if client was banned, skip if is
 		if c.banned {
 			continue
@@ -91,3 +92,173 @@ func GetClusterResources() (*proxmox.GetClusterResourcesResponse, error) {
 
 	return resources, nil
 }
+
+// GetClusterHAStatusResponse contains the response for the /cluster/ha/status/current endpoint
+type GetClusterHAStatusResponse struct {
+	Data []GetClusterHAStatusData `json:"data"`
+}
+
+// GetClusterHAStatusData contains one entry of the HA manager status, which may be of type quorum, master, lrm or service
+type GetClusterHAStatusData struct {
+	ID           string  `json:"id"`
+	Type         string  `json:"type"`
+	Node         string  `json:"node"`
+	Status       string  `json:"status"`
+	CRMState     *string `json:"crm_state"`
+	Quorate      *int    `json:"quorate"`
+	RequestState *string `json:"request_state"`
+	SID          *string `json:"sid"`
+	State        *string `json:"state"`
+	Timestamp    *int    `json:"timestamp"`
+}
+
+// GetClusterHAStatus returns the HA manager status from the /cluster/ha/status/current endpoint
+func GetClusterHAStatus() (*GetClusterHAStatusResponse, error) {
+	return getResource[GetClusterHAStatusResponse]("GetClusterHAStatus", "cluster/ha/status/current", nil, cache.DefaultExpiration)
+}
+
+// GetClusterHAResourcesResponse contains the response for the /cluster/ha/resources endpoint
+type GetClusterHAResourcesResponse struct {
+	Data []GetClusterHAResourcesData `json:"data"`
+}
+
+// GetClusterHAResourcesData contains the configuration of one HA managed resource
+type GetClusterHAResourcesData struct {
+	SID         string  `json:"sid"`
+	Type        string  `json:"type"`
+	State       *string `json:"state"`
+	Group       *string `json:"group"`
+	MaxRelocate *int    `json:"max_relocate"`
+	MaxRestart  *int    `json:"max_restart"`
+}
+
+// GetClusterHAResources returns the configured HA resources from the /cluster/ha/resources endpoint
+func GetClusterHAResources() (*GetClusterHAResourcesResponse, error) {
+	return getResource[GetClusterHAResourcesResponse]("GetClusterHAResources", "cluster/ha/resources", nil, cache.DefaultExpiration)
+}
+
+// GetClusterBackupInfoNotBackedUpResponse contains the response for the /cluster/backup-info/not-backed-up endpoint
+type GetClusterBackupInfoNotBackedUpResponse struct {
+	Data []GetClusterBackupInfoNotBackedUpData `json:"data"`
+}
+
+// GetClusterBackupInfoNotBackedUpData contains a guest that isn't included in any backup job
+type GetClusterBackupInfoNotBackedUpData struct {
+	VMID int     `json:"vmid"`
+	Type string  `json:"type"`
+	Name *string `json:"name"`
+}
+
+// GetClusterBackupInfoNotBackedUp returns the guests that aren't included in any backup job from the /cluster/backup-info/not-backed-up endpoint
+func GetClusterBackupInfoNotBackedUp() (*GetClusterBackupInfoNotBackedUpResponse, error) {
+	return getResource[GetClusterBackupInfoNotBackedUpResponse]("GetClusterBackupInfoNotBackedUp", "cluster/backup-info/not-backed-up", nil, cache.DefaultExpiration)
+}
+
+// GetClusterBackupResponse contains the response for the /cluster/backup endpoint
+type GetClusterBackupResponse struct {
+	Data []GetClusterBackupData `json:"data"`
+}
+
+// GetClusterBackupData contains the configuration of one vzdump backup job.
+// Jobs created before PVE 7 may have a Dow and StartTime instead of a Schedule
+type GetClusterBackupData struct {
+	ID        string  `json:"id"`
+	Type      string  `json:"type"`
+	Enabled   *int    `json:"enabled"`
+	Schedule  *string `json:"schedule"`
+	Dow       *string `json:"dow"`
+	StartTime *string `json:"starttime"`
+	Storage   *string `json:"storage"`
+	Mode      *string `json:"mode"`
+	Node      *string `json:"node"`
+	All       *int    `json:"all"`
+	VMID      *string `json:"vmid"`
+	Pool      *string `json:"pool"`
+	Exclude   *string `json:"exclude"`
+	Comment   *string `json:"comment"`
+}
+
+// GetClusterBackup returns the configured vzdump backup jobs from the /cluster/backup endpoint
+func GetClusterBackup() (*GetClusterBackupResponse, error) {
+	return getResource[GetClusterBackupResponse]("GetClusterBackup", "cluster/backup", nil, cache.DefaultExpiration)
+}
+
+// GetClusterSDNZonesResponse contains the response for the /cluster/sdn/zones endpoint
+type GetClusterSDNZonesResponse struct {
+	Data []GetClusterSDNZonesData `json:"data"`
+}
+
+// GetClusterSDNZonesData contains the configuration of one SDN zone
+type GetClusterSDNZonesData struct {
+	Zone  string  `json:"zone"`
+	Type  string  `json:"type"`
+	Nodes *string `json:"nodes"`
+	IPAM  *string `json:"ipam"`
+	MTU   *int    `json:"mtu"`
+}
+
+// GetClusterSDNZones returns the configured SDN zones from the /cluster/sdn/zones endpoint
+func GetClusterSDNZones() (*GetClusterSDNZonesResponse, error) {
+	return getResource[GetClusterSDNZonesResponse]("GetClusterSDNZones", "cluster/sdn/zones", nil, cache.DefaultExpiration)
+}
+
+// GetClusterSDNVnetsResponse contains the response for the /cluster/sdn/vnets endpoint
+type GetClusterSDNVnetsResponse struct {
+	Data []GetClusterSDNVnetsData `json:"data"`
+}
+
+// GetClusterSDNVnetsData contains the configuration of one SDN vnet
+type GetClusterSDNVnetsData struct {
+	Vnet      string  `json:"vnet"`
+	Zone      string  `json:"zone"`
+	Tag       *int    `json:"tag"`
+	Alias     *string `json:"alias"`
+	VlanAware *int    `json:"vlanaware"`
+}
+
+// GetClusterSDNVnets returns the configured SDN vnets from the /cluster/sdn/vnets endpoint
+func GetClusterSDNVnets() (*GetClusterSDNVnetsResponse, error) {
+	return getResource[GetClusterSDNVnetsResponse]("GetClusterSDNVnets", "cluster/sdn/vnets", nil, cache.DefaultExpiration)
+}
+
+// firewallExpiration is how long firewall options and rules are cached for.
+// They're requested for every guest, which is expensive on large clusters, and rarely change.
+// Guest firewalls are cached with a jittered expiration, so they're requested again spread out over time
+const firewallExpiration = 10 * time.Minute
+
+// GetFirewallRulesResponse contains the response for the cluster, node and guest firewall rules endpoints
+type GetFirewallRulesResponse struct {
+	Data []GetFirewallRulesData `json:"data"`
+}
+
+// GetFirewallRulesData contains one firewall rule. Type is the direction of the rule, or group for a security group reference
+type GetFirewallRulesData struct {
+	Pos     int     `json:"pos"`
+	Type    string  `json:"type"`
+	Action  string  `json:"action"`
+	Enable  *int    `json:"enable"`
+	Comment *string `json:"comment"`
+}
+
+// GetClusterFirewallOptionsResponse contains the response for the /cluster/firewall/options endpoint
+type GetClusterFirewallOptionsResponse struct {
+	Data GetClusterFirewallOptionsData `json:"data"`
+}
+
+// GetClusterFirewallOptionsData contains the cluster wide firewall options. Options that were never set are omitted
+type GetClusterFirewallOptionsData struct {
+	Enable    *int    `json:"enable"`
+	PolicyIn  *string `json:"policy_in"`
+	PolicyOut *string `json:"policy_out"`
+	Ebtables  *int    `json:"ebtables"`
+}
+
+// GetClusterFirewallOptions returns the cluster wide firewall options from the /cluster/firewall/options endpoint
+func GetClusterFirewallOptions() (*GetClusterFirewallOptionsResponse, error) {
+	return getResource[GetClusterFirewallOptionsResponse]("GetClusterFirewallOptions", "cluster/firewall/options", nil, firewallExpiration)
+}
+
+// GetClusterFirewallRules returns the cluster wide firewall rules from the /cluster/firewall/rules endpoint
+func GetClusterFirewallRules() (*GetFirewallRulesResponse, error) {
+	return getResource[GetFirewallRulesResponse]("GetClusterFirewallRules", "cluster/firewall/rules", nil, firewallExpiration)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -654,3 +655,774 @@ func TestRetrieveClusterName_NoClusterEntry(t *testing.T) {
 		t.Errorf("expected empty ClusterName when no cluster entry, got %q", ClusterName)
 	}
 }
+
+const integrationHAStatusJSON = `{
+	"data": [
+		{"id": "quorum", "type": "quorum", "node": "node1", "status": "OK", "quorate": 1},
+		{"id": "master", "type": "master", "node": "node1", "status": "node1 (active, Mon Jan  1 00:00:00 2024)", "timestamp": 1704067200},
+		{"id": "lrm:node1", "type": "lrm", "node": "node1", "status": "node1 (active, Mon Jan  1 00:00:00 2024)", "timestamp": 1704067200},
+		{"id": "lrm:node2", "type": "lrm", "node": "node2", "status": "node2 (idle, Mon Jan  1 00:00:00 2024)", "timestamp": 1704067200},
+		{"id": "service:vm:100", "type": "service", "node": "node1", "sid": "vm:100", "crm_state": "started", "request_state": "started", "state": "started", "status": "vm:100 (node1, started)"}
+	]
+}`
+
+const integrationHAResourcesJSON = `{
+	"data": [
+		{"sid": "vm:100", "type": "vm", "state": "started", "group": "prod", "max_relocate": 1, "max_restart": 1, "digest": "abc"},
+		{"sid": "ct:200", "type": "ct", "state": "disabled", "digest": "abc"}
+	]
+}`
+
+func TestGetClusterHAStatus_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/cluster/ha/status/current", jsonHandler(integrationHAStatusJSON))
+	setupIntegrationTest(t, mux)
+
+	resp, err := GetClusterHAStatus()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 5 {
+		t.Fatalf("expected 5 entries, got %d", len(resp.Data))
+	}
+
+	service := resp.Data[4]
+	if service.Type != "service" {
+		t.Errorf("expected type service, got %s", service.Type)
+	}
+	if service.SID == nil || *service.SID != "vm:100" {
+		t.Errorf("expected sid vm:100")
+	}
+	if service.CRMState == nil || *service.CRMState != "started" {
+		t.Errorf("expected crm_state started")
+	}
+}
+
+func TestGetClusterHAResources_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/cluster/ha/resources", jsonHandler(integrationHAResourcesJSON))
+	setupIntegrationTest(t, mux)
+
+	resp, err := GetClusterHAResources()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 2 {
+		t.Fatalf("expected 2 resources, got %d", len(resp.Data))
+	}
+	if resp.Data[0].SID != "vm:100" || resp.Data[0].Type != "vm" {
+		t.Errorf("unexpected first resource: %s %s", resp.Data[0].SID, resp.Data[0].Type)
+	}
+	if resp.Data[0].Group == nil || *resp.Data[0].Group != "prod" {
+		t.Errorf("expected group prod")
+	}
+	if resp.Data[1].State == nil || *resp.Data[1].State != "disabled" {
+		t.Errorf("expected state disabled")
+	}
+}
+
+func TestGetClusterBackupInfoNotBackedUp_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/cluster/backup-info/not-backed-up", jsonHandler(`{"data": [{"vmid": 101, "type": "qemu", "name": "db-server"}]}`))
+	setupIntegrationTest(t, mux)
+
+	resp, err := GetClusterBackupInfoNotBackedUp()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 1 {
+		t.Fatalf("expected 1 guest, got %d", len(resp.Data))
+	}
+	if resp.Data[0].VMID != 101 || resp.Data[0].Type != "qemu" {
+		t.Errorf("unexpected guest: %d %s", resp.Data[0].VMID, resp.Data[0].Type)
+	}
+	if resp.Data[0].Name == nil || *resp.Data[0].Name != "db-server" {
+		t.Errorf("expected name db-server")
+	}
+}
+
+func TestGetNodeTasks_Integration(t *testing.T) {
+	var query string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/tasks", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		jsonHandler(`{"data": [
+			{"upid": "UPID:node1:00001234:00ABCDEF:65A0D000:vzdump::root@pam:", "node": "node1", "type": "vzdump", "id": "", "user": "root@pam", "starttime": 1705000000},
+			{"upid": "UPID:node1:00002222:00BBBBBB:65960000:vzdump:101:root@pam:", "node": "node1", "type": "vzdump", "id": "101", "user": "root@pam", "starttime": 1704329216, "endtime": 1704329336, "status": "OK"}
+		]}`)(w, r)
+	})
+	setupIntegrationTest(t, mux)
+
+	resp, err := GetNodeTasks("node1", GetNodeTasksOptions{TypeFilter: "vzdump", Since: 1704000000, Limit: 500})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != "limit=500&since=1704000000&typefilter=vzdump" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if len(resp.Data) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(resp.Data))
+	}
+	if resp.Data[0].EndTime != nil || resp.Data[0].Status != nil {
+		t.Errorf("expected running task without endtime and status")
+	}
+	if resp.Data[1].ID != "101" || resp.Data[1].Status == nil || *resp.Data[1].Status != "OK" {
+		t.Errorf("unexpected finished task: %+v", resp.Data[1])
+	}
+}
+
+func TestGetNodeTaskLog_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/tasks/{upid}/log", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("upid") != "UPID:node1:00001234:00ABCDEF:65A0D000:vzdump::root@pam:" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		jsonHandler(`{"data": [{"n": 1, "t": "INFO: Starting Backup of VM 100 (qemu)"}, {"n": 2, "t": "INFO: Finished Backup of VM 100 (00:01:23)"}]}`)(w, r)
+	})
+	setupIntegrationTest(t, mux)
+
+	resp, err := GetNodeTaskLog("node1", "UPID:node1:00001234:00ABCDEF:65A0D000:vzdump::root@pam:", GetNodeTaskLogOptions{Limit: 5000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(resp.Data))
+	}
+	if resp.Data[1].N != 2 || resp.Data[1].T != "INFO: Finished Backup of VM 100 (00:01:23)" {
+		t.Errorf("unexpected line: %+v", resp.Data[1])
+	}
+}
+
+func TestGetClusterBackup_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/cluster/backup", jsonHandler(`{"data": [
+		{"id": "backup-1a2b3c4d-0001", "type": "vzdump", "enabled": 1, "schedule": "sat 02:00", "storage": "pbs", "mode": "snapshot", "all": 1, "exclude": "900"},
+		{"id": "backup-1a2b3c4d-0002", "type": "vzdump", "dow": "mon,wed", "starttime": "21:00", "storage": "local", "vmid": "100,101", "node": "node1"}
+	]}`))
+	setupIntegrationTest(t, mux)
+
+	resp, err := GetClusterBackup()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(resp.Data))
+	}
+	if resp.Data[0].Schedule == nil || *resp.Data[0].Schedule != "sat 02:00" {
+		t.Errorf("expected schedule 'sat 02:00'")
+	}
+	if resp.Data[0].All == nil || *resp.Data[0].All != 1 {
+		t.Errorf("expected all=1")
+	}
+	if resp.Data[1].Enabled != nil || resp.Data[1].Schedule != nil {
+		t.Errorf("expected legacy job without enabled and schedule")
+	}
+	if resp.Data[1].Dow == nil || *resp.Data[1].Dow != "mon,wed" || resp.Data[1].StartTime == nil || *resp.Data[1].StartTime != "21:00" {
+		t.Errorf("expected legacy job dow and starttime")
+	}
+	if resp.Data[1].VMID == nil || *resp.Data[1].VMID != "100,101" {
+		t.Errorf("expected vmid '100,101'")
+	}
+}
+
+func TestGetNodeStorageContent_Integration(t *testing.T) {
+	var counter atomic.Int32
+	var query string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/storage/pbs/content", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		countingHandler(`{"data": [
+			{"volid": "pbs:backup/vm/100/2024-01-01T01:00:00Z", "content": "backup", "format": "pbs-vm", "size": 34359738368, "ctime": 1704070800, "vmid": 100, "subtype": "qemu", "protected": 1},
+			{"volid": "pbs:backup/ct/200/2024-01-01T01:05:00Z", "content": "backup", "format": "pbs-ct", "size": 1073741824, "ctime": 1704071100, "vmid": 200, "subtype": "lxc"}
+		]}`, &counter)(w, r)
+	})
+	setupIntegrationTest(t, mux)
+
+	resp, err := GetNodeStorageContent("node1", "pbs", GetNodeStorageContentOptions{Content: "backup"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != "content=backup" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if len(resp.Data) != 2 {
+		t.Fatalf("expected 2 volumes, got %d", len(resp.Data))
+	}
+	if resp.Data[0].Size != 34359738368 || resp.Data[0].CTime == nil || *resp.Data[0].CTime != 1704070800 {
+		t.Errorf("unexpected first volume: %+v", resp.Data[0])
+	}
+	if resp.Data[1].VMID == nil || *resp.Data[1].VMID != 200 {
+		t.Errorf("expected vmid 200")
+	}
+
+	// Storage content is cached longer than the default expiration
+	if _, expiration, found := cash.GetWithExpiration("GetNodeStorageContent_node1_pbs_backup_0"); !found || time.Until(expiration) <= 24*time.Second {
+		t.Errorf("expected storage content to be cached for longer than the default expiration")
+	}
+	_, _ = GetNodeStorageContent("node1", "pbs", GetNodeStorageContentOptions{Content: "backup"})
+	if counter.Load() != 1 {
+		t.Errorf("expected 1 request, got %d", counter.Load())
+	}
+}
+
+func TestGetNodeReplication_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/replication", jsonHandler(`{"data": [
+		{"id": "100-0", "guest": 100, "jobnum": 0, "target": "node2", "type": "local", "schedule": "*/15", "last_sync": 1704067200, "last_try": 1704067200, "next_sync": 1704068100, "duration": 3.52, "fail_count": 0, "vmtype": "qemu"},
+		{"id": "200-0", "guest": 200, "jobnum": 0, "target": "node2", "type": "local", "schedule": "*/15", "last_sync": 1704060000, "last_try": 1704067200, "next_sync": 1704067500, "duration": 1.1, "fail_count": 3, "error": "no tunnel IP received", "vmtype": "lxc"}
+	]}`))
+	setupIntegrationTest(t, mux)
+
+	resp, err := GetNodeReplication("node1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(resp.Data))
+	}
+	job := resp.Data[0]
+	if job.ID != "100-0" || job.Guest != 100 || job.Target != "node2" {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if job.Duration == nil || *job.Duration != 3.52 {
+		t.Errorf("expected duration 3.52")
+	}
+	if job.Error != nil {
+		t.Errorf("expected no error")
+	}
+	failing := resp.Data[1]
+	if failing.FailCount == nil || *failing.FailCount != 3 || failing.Error == nil || *failing.Error != "no tunnel IP received" {
+		t.Errorf("unexpected failing job: %+v", failing)
+	}
+}
+
+func TestGetNodeDisksZFS_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/disks/zfs", jsonHandler(`{"data": [
+		{"name": "rpool", "health": "ONLINE", "size": 996432412672, "alloc": 107374182400, "free": 889058230272, "frag": 12, "dedup": 1.00}
+	]}`))
+	mux.HandleFunc("/api2/json/nodes/node1/disks/zfs/rpool", jsonHandler(`{"data": {
+		"name": "rpool", "state": "DEGRADED", "errors": "No known data errors",
+		"children": [{"name": "rpool", "state": "DEGRADED", "read": 0, "write": 0, "cksum": 0, "children": [
+			{"name": "mirror-0", "state": "DEGRADED", "read": 0, "write": 0, "cksum": 0, "children": [
+				{"name": "nvme0n1p3", "state": "ONLINE", "read": 0, "write": 0, "cksum": 0, "leaf": 1},
+				{"name": "nvme1n1p3", "state": "FAULTED", "read": "1.2K", "write": 3, "cksum": 0, "msg": "too many errors", "leaf": 1}
+			]}
+		]}]
+	}}`))
+	setupIntegrationTest(t, mux)
+
+	pools, err := GetNodeDisksZFS("node1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pools.Data) != 1 || pools.Data[0].Name != "rpool" || pools.Data[0].Frag != 12 || pools.Data[0].Dedup != 1.0 {
+		t.Fatalf("unexpected pools: %+v", pools.Data)
+	}
+
+	detail, err := GetNodeDisksZFSDetail("node1", "rpool")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if detail.Data.State != "DEGRADED" || len(detail.Data.Children) != 1 {
+		t.Fatalf("unexpected detail: %+v", detail.Data)
+	}
+	faulted := detail.Data.Children[0].Children[0].Children[1]
+	if faulted.Name != "nvme1n1p3" || faulted.State == nil || *faulted.State != "FAULTED" {
+		t.Errorf("unexpected device: %+v", faulted)
+	}
+	if faulted.Read == nil || string(*faulted.Read) != "1.2K" || faulted.Write == nil || string(*faulted.Write) != "3" {
+		t.Errorf("unexpected error counts: %v %v", faulted.Read, faulted.Write)
+	}
+}
+
+func TestGetNodeDisksLVM_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/disks/lvmthin", jsonHandler(`{"data": [
+		{"lv": "data", "vg": "pve", "lv_size": 1884119105536, "used": 195571563154, "metadata_size": 1073741824, "metadata_used": 53687091}
+	]}`))
+	mux.HandleFunc("/api2/json/nodes/node1/disks/lvm", jsonHandler(`{"data": {"leaf": 0, "children": [
+		{"name": "pve", "size": 1999844147200, "free": 17175674880, "lvcount": 3, "leaf": 0, "children": [
+			{"name": "/dev/nvme0n1p3", "size": 1999844147200, "free": 17175674880, "leaf": 1}
+		]}
+	]}}`))
+	setupIntegrationTest(t, mux)
+
+	thinPools, err := GetNodeDisksLVMThin("node1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(thinPools.Data) != 1 {
+		t.Fatalf("expected 1 thin pool, got %d", len(thinPools.Data))
+	}
+	pool := thinPools.Data[0]
+	if pool.LV != "data" || pool.VG != "pve" || pool.LVSize != 1884119105536 || pool.MetadataSize != 1073741824 || pool.MetadataUsed != 53687091 {
+		t.Errorf("unexpected thin pool: %+v", pool)
+	}
+
+	lvm, err := GetNodeDisksLVM("node1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lvm.Data.Children) != 1 {
+		t.Fatalf("expected 1 volume group, got %d", len(lvm.Data.Children))
+	}
+	vg := lvm.Data.Children[0]
+	if vg.Name != "pve" || vg.Size != 1999844147200 || vg.Free != 17175674880 || len(vg.Children) != 1 {
+		t.Errorf("unexpected volume group: %+v", vg)
+	}
+}
+
+func TestGetNodeDisksSMART_Integration(t *testing.T) {
+	var requests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/disks/smart", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("disk") {
+		case "/dev/sda":
+			_, _ = fmt.Fprint(w, `{"data": {"health": "PASSED", "type": "ata", "attributes": [
+				{"id": "  5", "name": "Reallocated_Sector_Ct", "value": 100, "worst": 100, "threshold": 10, "raw": "8", "normalized": 100, "flags": "PO--CK", "fail": "-"},
+				{"id": "194", "name": "Temperature_Celsius", "value": 65, "worst": 50, "threshold": 0, "raw": "35 (Min/Max 20/50)", "normalized": 65, "flags": "-O---K", "fail": "-"}
+			]}}`)
+		case "/dev/nvme0n1":
+			_, _ = fmt.Fprint(w, `{"data": {"health": "PASSED", "type": "text", "text": "Temperature:                        38 Celsius\nPercentage Used:                    2%\n"}}`)
+		default:
+			http.Error(w, `{"errors": {"disk": "invalid"}}`, http.StatusBadRequest)
+		}
+	})
+	setupIntegrationTest(t, mux)
+
+	ata, err := GetNodeDisksSMART("node1", "/dev/sda")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ata.Data.Type != "ata" || len(ata.Data.Attributes) != 2 {
+		t.Fatalf("unexpected SMART data: %+v", ata.Data)
+	}
+	if attr := ata.Data.Attributes[1]; attr.Name != "Temperature_Celsius" || attr.Raw != "35 (Min/Max 20/50)" {
+		t.Errorf("unexpected attribute: %+v", attr)
+	}
+
+	nvme, err := GetNodeDisksSMART("node1", "/dev/nvme0n1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nvme.Data.Type != "text" || nvme.Data.Text == nil || len(nvme.Data.Attributes) != 0 {
+		t.Errorf("unexpected SMART data: %+v", nvme.Data)
+	}
+
+	// Repeated requests are served from the cache
+	if _, err := GetNodeDisksSMART("node1", "/dev/sda"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestGetNodeSubscription_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/subscription", jsonHandler(`{"data": {
+		"status": "active", "level": "c", "productname": "Proxmox VE Community Subscription 1 CPU/year",
+		"key": "pve1c-0123456789", "serverid": "0123456789ABCDEF", "nextduedate": "2025-03-01", "regdate": "2024-03-01 00:00:00",
+		"checktime": 1704067200, "sockets": 1
+	}}`))
+	setupIntegrationTest(t, mux)
+
+	sub, err := GetNodeSubscription("node1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sub.Data.Status != "active" {
+		t.Errorf("expected status active, got %s", sub.Data.Status)
+	}
+	if sub.Data.Level == nil || *sub.Data.Level != "c" {
+		t.Errorf("unexpected level: %v", sub.Data.Level)
+	}
+	if sub.Data.NextDueDate == nil || *sub.Data.NextDueDate != "2025-03-01" {
+		t.Errorf("unexpected next due date: %v", sub.Data.NextDueDate)
+	}
+	if sub.Data.CheckTime == nil || *sub.Data.CheckTime != 1704067200 {
+		t.Errorf("unexpected check time: %v", sub.Data.CheckTime)
+	}
+}
+
+func TestGetNodeApt_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/apt/update", jsonHandler(`{"data": [
+		{"Package": "pve-manager", "Title": "Proxmox Virtual Environment Management Tools", "Version": "8.1.4", "OldVersion": "8.1.3", "Origin": "Proxmox", "Priority": "optional", "Section": "admin", "Arch": "amd64"}
+	]}`))
+	mux.HandleFunc("/api2/json/nodes/node1/apt/versions", jsonHandler(`{"data": [
+		{"Package": "proxmox-ve", "Title": "Proxmox Virtual Environment", "Version": "8.1.0", "CurrentState": "Installed", "RunningKernel": "6.5.11-8-pve", "ManagerVersion": "8.1.3"},
+		{"Package": "proxmox-kernel-6.5.13-1-pve-signed", "Title": "Proxmox Kernel Image (signed)", "Version": "6.5.13-1", "CurrentState": "Installed"}
+	]}`))
+	setupIntegrationTest(t, mux)
+
+	updates, err := GetNodeAptUpdate("node1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(updates.Data) != 1 || updates.Data[0].Package != "pve-manager" || updates.Data[0].Origin != "Proxmox" || updates.Data[0].OldVersion != "8.1.3" {
+		t.Errorf("unexpected updates: %+v", updates.Data)
+	}
+
+	versions, err := GetNodeAptVersions("node1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(versions.Data) != 2 {
+		t.Fatalf("expected 2 packages, got %d", len(versions.Data))
+	}
+	if pkg := versions.Data[0]; pkg.RunningKernel == nil || *pkg.RunningKernel != "6.5.11-8-pve" {
+		t.Errorf("unexpected package: %+v", pkg)
+	}
+	if pkg := versions.Data[1]; pkg.Package != "proxmox-kernel-6.5.13-1-pve-signed" || pkg.Version != "6.5.13-1" || pkg.CurrentState != "Installed" {
+		t.Errorf("unexpected package: %+v", pkg)
+	}
+}
+
+func TestGetNodeServices_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/services", jsonHandler(`{"data": [
+		{"service": "pveproxy", "name": "pveproxy", "desc": "PVE API Proxy Server", "state": "running", "active-state": "active", "unit-state": "enabled"},
+		{"service": "pvestatd", "name": "pvestatd", "desc": "PVE Status Daemon", "state": "dead", "active-state": "failed", "unit-state": "enabled"}
+	]}`))
+	setupIntegrationTest(t, mux)
+
+	services, err := GetNodeServices("node1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(services.Data) != 2 {
+		t.Fatalf("expected 2 services, got %d", len(services.Data))
+	}
+	svc := services.Data[1]
+	if svc.Service != "pvestatd" || svc.State != "dead" || svc.ActiveState == nil || *svc.ActiveState != "failed" || svc.UnitState == nil || *svc.UnitState != "enabled" {
+		t.Errorf("unexpected service: %+v", svc)
+	}
+}
+
+func TestGetNodeTime_Integration(t *testing.T) {
+	var requests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/time", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"data": {"timezone": "Europe/Berlin", "time": %d, "localtime": %d}}`, time.Now().Unix(), time.Now().Unix()+3600)
+	})
+	setupIntegrationTest(t, mux)
+
+	before := time.Now()
+	nodeTime, err := GetNodeTime("node1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	after := time.Now()
+
+	if nodeTime.Data.Timezone != "Europe/Berlin" || nodeTime.Data.LocalTime-nodeTime.Data.Time != 3600 {
+		t.Errorf("unexpected node time: %+v", nodeTime.Data)
+	}
+	// The request time is the midpoint of the request, so it's after the request was sent and before the response was read
+	if !nodeTime.RequestTime.After(before) || !nodeTime.RequestTime.Before(after) {
+		t.Errorf("expected request time between %s and %s, got %s", before, after, nodeTime.RequestTime)
+	}
+
+	// A cached response keeps the request time it was read with
+	cached, err := GetNodeTime("node1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cached.RequestTime.Equal(nodeTime.RequestTime) {
+		t.Errorf("expected cached request time %s, got %s", nodeTime.RequestTime, cached.RequestTime)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestGetNodeNetwork_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/network", jsonHandler(`{"data": [
+		{"iface": "bond0", "type": "bond", "active": 1, "autostart": 1, "slaves": "eno1 eno2", "bond_mode": "802.3ad", "families": ["inet"], "method": "manual"},
+		{"iface": "vmbr0", "type": "bridge", "active": 1, "autostart": 1, "bridge_ports": "bond0", "cidr": "10.0.0.1/24", "families": ["inet"], "method": "static"},
+		{"iface": "eno2", "type": "eth", "exists": 1, "families": ["inet"], "method": "manual"}
+	], "changes": "--- /etc/network/interfaces\n+++ /etc/network/interfaces.new\n"}`))
+	setupIntegrationTest(t, mux)
+
+	network, err := GetNodeNetwork("node1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(network.Data) != 3 {
+		t.Fatalf("expected 3 interfaces, got %d", len(network.Data))
+	}
+	bond := network.Data[0]
+	if bond.Iface != "bond0" || bond.Slaves == nil || *bond.Slaves != "eno1 eno2" || bond.BondMode == nil || *bond.BondMode != "802.3ad" {
+		t.Errorf("unexpected bond: %+v", bond)
+	}
+	if eth := network.Data[2]; eth.Active != nil || eth.Exists == nil || *eth.Exists != 1 {
+		t.Errorf("unexpected interface: %+v", eth)
+	}
+	if network.Changes == nil || *network.Changes == "" {
+		t.Error("expected pending changes")
+	}
+}
+
+func TestGetQemuAgent_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/qemu/100/agent/ping", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"data": {"result": {}}}`)
+	})
+	mux.HandleFunc("/api2/json/nodes/node1/qemu/100/agent/get-fsinfo", jsonHandler(`{"data": {"result": [
+		{"name": "sda1", "mountpoint": "/", "type": "ext4", "total-bytes": 33501757440, "used-bytes": 8964567040, "disk": [{"dev": "/dev/sda1", "bus-type": "scsi"}]},
+		{"name": "sr0", "mountpoint": "/media/cdrom", "type": "iso9660", "disk": []}
+	]}}`))
+	setupIntegrationTest(t, mux)
+
+	if _, err := PostQemuAgentPing("node1", 100); err != nil {
+		t.Fatalf("unexpected ping error: %v", err)
+	}
+
+	fsInfo, err := GetQemuAgentFsInfo("node1", 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fsInfo.Data.Result) != 2 {
+		t.Fatalf("expected 2 filesystems, got %d", len(fsInfo.Data.Result))
+	}
+	root := fsInfo.Data.Result[0]
+	if root.Mountpoint != "/" || root.Type != "ext4" || root.TotalBytes == nil || *root.TotalBytes != 33501757440 || root.UsedBytes == nil || *root.UsedBytes != 8964567040 {
+		t.Errorf("unexpected filesystem: %+v", root)
+	}
+	if cdrom := fsInfo.Data.Result[1]; cdrom.TotalBytes != nil || cdrom.UsedBytes != nil {
+		t.Errorf("expected no usage for %+v", cdrom)
+	}
+}
+
+func TestGetGuestNetwork_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/qemu/100/agent/get-osinfo", jsonHandler(`{"data": {"result": {"id": "ubuntu", "name": "Ubuntu", "pretty-name": "Ubuntu 24.04 LTS", "version": "24.04 LTS (Noble Numbat)", "version-id": "24.04", "kernel-release": "6.8.0-31-generic", "kernel-version": "#31-Ubuntu SMP", "machine": "x86_64"}}}`))
+	mux.HandleFunc("/api2/json/nodes/node1/qemu/100/agent/network-get-interfaces", jsonHandler(`{"data": {"result": [
+		{"name": "lo", "hardware-address": "00:00:00:00:00:00", "ip-addresses": [{"ip-address": "127.0.0.1", "ip-address-type": "ipv4", "prefix": 8}], "statistics": {"rx-bytes": 100}},
+		{"name": "ens18", "hardware-address": "bc:24:11:5e:8a:01", "ip-addresses": [{"ip-address": "192.168.1.100", "ip-address-type": "ipv4", "prefix": 24}, {"ip-address": "fe80::be24:11ff:fe5e:8a01", "ip-address-type": "ipv6", "prefix": 64}]},
+		{"name": "docker0", "hardware-address": "02:42:ac:11:00:01"}
+	]}}`))
+	mux.HandleFunc("/api2/json/nodes/node1/lxc/200/interfaces", jsonHandler(`{"data": [
+		{"name": "lo", "hwaddr": "00:00:00:00:00:00", "inet": "127.0.0.1/8", "inet6": "::1/128"},
+		{"name": "eth0", "hwaddr": "bc:24:11:2f:4c:02", "inet": "192.168.1.53/24"}
+	]}`))
+	setupIntegrationTest(t, mux)
+
+	osInfo, err := GetQemuAgentOsInfo("node1", 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := osInfo.Data.Result
+	if result.ID == nil || *result.ID != "ubuntu" || result.VersionID == nil || *result.VersionID != "24.04" || result.KernelRelease == nil || *result.KernelRelease != "6.8.0-31-generic" {
+		t.Errorf("unexpected os info: %+v", result)
+	}
+
+	interfaces, err := GetQemuAgentNetworkInterfaces("node1", 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(interfaces.Data.Result) != 3 {
+		t.Fatalf("expected 3 interfaces, got %d", len(interfaces.Data.Result))
+	}
+	ens18 := interfaces.Data.Result[1]
+	if ens18.Name != "ens18" || len(ens18.IPAddresses) != 2 || ens18.IPAddresses[0].IPAddress != "192.168.1.100" || ens18.IPAddresses[1].IPAddressType != "ipv6" {
+		t.Errorf("unexpected interface: %+v", ens18)
+	}
+	if docker0 := interfaces.Data.Result[2]; len(docker0.IPAddresses) != 0 {
+		t.Errorf("expected no addresses for %+v", docker0)
+	}
+
+	lxcInterfaces, err := GetLxcInterfaces("node1", 200)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lxcInterfaces.Data) != 2 {
+		t.Fatalf("expected 2 interfaces, got %d", len(lxcInterfaces.Data))
+	}
+	eth0 := lxcInterfaces.Data[1]
+	if eth0.Name != "eth0" || eth0.Inet == nil || *eth0.Inet != "192.168.1.53/24" || eth0.Inet6 != nil {
+		t.Errorf("unexpected interface: %+v", eth0)
+	}
+}
+
+func TestGuestAgentErrors_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/qemu/100/agent/ping", errorHandler(500))
+	mux.HandleFunc("/api2/json/nodes/node1/qemu/101/agent/ping", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+	setupIntegrationTest(t, mux)
+	SetGuestAgentLimits(50*time.Millisecond, 4)
+	t.Cleanup(func() { SetGuestAgentLimits(5*time.Second, 4) })
+
+	// An agent that isn't running makes PVE respond with a 500
+	if _, err := PostQemuAgentPing("node1", 100); err == nil {
+		t.Fatal("expected error from 500 response")
+	}
+
+	// An agent that hangs is given up on after the timeout
+	start := time.Now()
+	if _, err := PostQemuAgentPing("node1", 101); err == nil {
+		t.Fatal("expected error from hung agent")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the request to time out after 50ms, took %s", elapsed)
+	}
+
+	// Neither is the API server's fault, so the client isn't banned
+	if GetBannedClientCount() != 0 {
+		t.Errorf("expected 0 banned clients, got %d", GetBannedClientCount())
+	}
+}
+
+func TestGuestAgentBackoff_Integration(t *testing.T) {
+	// More VMs have a hung agent than there may be agent requests in flight
+	var requests atomic.Int32
+	var hung atomic.Bool
+	hung.Store(true)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/qemu/{vmid}/agent/ping", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if hung.Load() {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"data": {"result": {}}}`)
+	})
+	setupIntegrationTest(t, mux)
+	SetGuestAgentLimits(50*time.Millisecond, 2)
+	t.Cleanup(func() { SetGuestAgentLimits(5*time.Second, 4) })
+
+	pingAll := func() (failed int) {
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for vmID := 100; vmID < 108; vmID++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := PostQemuAgentPing("node1", vmID); err != nil {
+					mu.Lock()
+					failed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		return failed
+	}
+
+	if failed := pingAll(); failed != 8 {
+		t.Fatalf("expected 8 failed pings, got %d", failed)
+	}
+	if n := requests.Load(); n != 8 {
+		t.Fatalf("expected 8 requests, got %d", n)
+	}
+
+	// The failed pings are skipped until their backoff passes, rather than waiting for the timeout again
+	start := time.Now()
+	if failed := pingAll(); failed != 8 {
+		t.Errorf("expected 8 skipped pings, got %d", failed)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("expected skipped pings to return immediately, took %s", elapsed)
+	}
+	if n := requests.Load(); n != 8 {
+		t.Errorf("expected no more requests during the backoff, got %d", n-8)
+	}
+
+	// Once the backoff has passed, a request that succeeds clears the VM's failures
+	x, found := cash.Get("agentFailure_PostQemuAgentPing_100")
+	if !found {
+		t.Fatal("expected the failed ping to be tracked")
+	}
+	failure := x.(agentFailure)
+	if failure.failures != 1 {
+		t.Errorf("expected 1 failure, got %d", failure.failures)
+	}
+	failure.retryAt = time.Now()
+	cash.Set("agentFailure_PostQemuAgentPing_100", failure, time.Minute)
+	hung.Store(false)
+	if _, err := PostQemuAgentPing("node1", 100); err != nil {
+		t.Fatalf("unexpected error after the backoff: %v", err)
+	}
+	if _, found := cash.Get("agentFailure_PostQemuAgentPing_100"); found {
+		t.Error("expected the failures to be cleared after a successful ping")
+	}
+}
+
+func TestAgentBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{6, 10 * time.Minute},
+		{100, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := agentBackoff(tt.failures); got != tt.expected {
+			t.Errorf("%d failures: expected %s, got %s", tt.failures, tt.expected, got)
+		}
+	}
+}
+
+func TestGuestAgentConcurrency_Integration(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/nodes/node1/qemu/{vmid}/agent/ping", func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"data": {"result": {}}}`)
+	})
+	setupIntegrationTest(t, mux)
+	SetGuestAgentLimits(time.Second, 2)
+	t.Cleanup(func() { SetGuestAgentLimits(5*time.Second, 4) })
+
+	var wg sync.WaitGroup
+	for vmID := 100; vmID < 106; vmID++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := PostQemuAgentPing("node1", vmID); err != nil {
+				t.Errorf("unexpected error for VM %d: %v", vmID, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := maxInFlight.Load(); n > 2 {
+		t.Errorf("expected at most 2 agent requests in flight, got %d", n)
+	}
+}
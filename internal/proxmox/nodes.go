@@ -2,6 +2,9 @@ package proxmox
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/patrickmn/go-cache"
 	proxmox "github.com/starttoaster/go-proxmox"
@@ -23,7 +26,7 @@ func GetNodeStatus(name string) (*proxmox.GetNodeStatusResponse, error) {
 
 	// Make request if not found in cache
 	var err error
-	for clientName, c := range clients {
+	for clientName, c := range currentClients() {
 		// Check if client was banned, skip if is
 		if c.banned {
 			continue
@@ -65,7 +68,7 @@ func GetNodeDisksList(name string) (*proxmox.GetNodeDisksListResponse, error) {
 
 	// Make request if not found in cache
 	var err error
-	for clientName, c := range clients {
+	for clientName, c := range currentClients() {
 		// Check if client was banned, skip if is
 		if c.banned {
 			continue
@@ -107,7 +110,7 @@ func GetNodeCertificatesInfo(name string) (*proxmox.GetNodeCertificatesInfoRespo
 
 	// Make request if not found in cache
 	var err error
-	for clientName, c := range clients {
+	for clientName, c := range currentClients() {
 		// Check if client was banned, skip if is
 		if c.banned {
 			continue
@@ -152,7 +155,7 @@ func GetQemuSnapshots(nodeName string, vmID int) (*proxmox.GetQemuSnapshotsRespo
 
 	// Make request if not found in cache
 	var err error
-	for clientName, c := range clients {
+	for clientName, c := range currentClients() {
 		// Check if client was banned, skip if is
 		if c.banned {
 			continue
@@ -197,7 +200,7 @@ func GetLxcSnapshots(nodeName string, vmID int) (*proxmox.GetLxcSnapshotsRespons
 
 	// Make request if not found in cache
 	var err error
-	for clientName, c := range clients {
+	for clientName, c := range currentClients() {
 		// Check if client was banned, skip if is
 		if c.banned {
 			continue
@@ -223,3 +226,632 @@ func GetLxcSnapshots(nodeName string, vmID int) (*proxmox.GetLxcSnapshotsRespons
 
 	return out, nil
 }
+
+// GetNodeTasksOptions contains the query parameters for the /nodes/%s/tasks endpoint
+type GetNodeTasksOptions struct {
+	// TypeFilter only lists tasks of this type (ex: vzdump)
+	TypeFilter string `url:"typefilter,omitempty"`
+	// Source is one of archive (finished tasks, the default), active or all
+	Source string `url:"source,omitempty"`
+	// Since only lists tasks started at or after this unix time
+	Since int `url:"since,omitempty"`
+	Start int `url:"start,omitempty"`
+	Limit int `url:"limit,omitempty"`
+}
+
+// GetNodeTasksResponse contains the response for the /nodes/%s/tasks endpoint
+type GetNodeTasksResponse struct {
+	Data []GetNodeTasksData `json:"data"`
+}
+
+// GetNodeTasksData contains one task from a node's task history. Status and EndTime are only set for finished tasks
+type GetNodeTasksData struct {
+	UPID      string  `json:"upid"`
+	Node      string  `json:"node"`
+	Type      string  `json:"type"`
+	ID        string  `json:"id"`
+	User      string  `json:"user"`
+	StartTime int     `json:"starttime"`
+	EndTime   *int    `json:"endtime"`
+	Status    *string `json:"status"`
+}
+
+// GetNodeTasks returns a page of tasks from a node's task history, newest first
+func GetNodeTasks(name string, opt GetNodeTasksOptions) (*GetNodeTasksResponse, error) {
+	cacheKey := fmt.Sprintf("GetNodeTasks_%s_%s_%s_%d_%d_%d", name, opt.TypeFilter, opt.Source, opt.Since, opt.Start, opt.Limit)
+	return getResource[GetNodeTasksResponse](cacheKey, fmt.Sprintf("nodes/%s/tasks", name), &opt, cache.DefaultExpiration)
+}
+
+// GetNodeTaskLogOptions contains the query parameters for the /nodes/%s/tasks/%s/log endpoint
+type GetNodeTaskLogOptions struct {
+	Start int `url:"start,omitempty"`
+	Limit int `url:"limit,omitempty"`
+}
+
+// GetNodeTaskLogResponse contains the response for the /nodes/%s/tasks/%s/log endpoint
+type GetNodeTaskLogResponse struct {
+	Data []GetNodeTaskLogData `json:"data"`
+}
+
+// GetNodeTaskLogData contains one numbered line of a task's log
+type GetNodeTaskLogData struct {
+	N int    `json:"n"`
+	T string `json:"t"`
+}
+
+// GetNodeTaskLog returns a page of log lines for a task by its UPID
+func GetNodeTaskLog(name, upid string, opt GetNodeTaskLogOptions) (*GetNodeTaskLogResponse, error) {
+	cacheKey := fmt.Sprintf("GetNodeTaskLog_%s_%d_%d", upid, opt.Start, opt.Limit)
+	return getResource[GetNodeTaskLogResponse](cacheKey, fmt.Sprintf("nodes/%s/tasks/%s/log", name, url.PathEscape(upid)), &opt, cache.DefaultExpiration)
+}
+
+// storageContentExpiration is how long storage content listings are cached for.
+// Listing backup storages can be slow (ex: Proxmox Backup Server), and the backups on them only change a few times a day
+const storageContentExpiration = 5 * time.Minute
+
+// GetNodeStorageContentOptions contains the query parameters for the /nodes/%s/storage/%s/content endpoint
+type GetNodeStorageContentOptions struct {
+	// Content only lists volumes of this content type (ex: backup, images, iso)
+	Content string `url:"content,omitempty"`
+	// VMID only lists volumes that belong to this guest
+	VMID int `url:"vmid,omitempty"`
+}
+
+// GetNodeStorageContentResponse contains the response for the /nodes/%s/storage/%s/content endpoint
+type GetNodeStorageContentResponse struct {
+	Data []GetNodeStorageContentData `json:"data"`
+}
+
+// GetNodeStorageContentData contains one volume of a storage
+type GetNodeStorageContentData struct {
+	VolID     string  `json:"volid"`
+	Content   string  `json:"content"`
+	Format    string  `json:"format"`
+	Size      int     `json:"size"`
+	CTime     *int    `json:"ctime"`
+	VMID      *int    `json:"vmid"`
+	Subtype   *string `json:"subtype"`
+	Protected *int    `json:"protected"`
+	Notes     *string `json:"notes"`
+}
+
+// GetNodeStorageContent returns the volumes of a storage, as seen from a node
+func GetNodeStorageContent(name, storage string, opt GetNodeStorageContentOptions) (*GetNodeStorageContentResponse, error) {
+	cacheKey := fmt.Sprintf("GetNodeStorageContent_%s_%s_%s_%d", name, storage, opt.Content, opt.VMID)
+	return getResource[GetNodeStorageContentResponse](cacheKey, fmt.Sprintf("nodes/%s/storage/%s/content", name, storage), &opt, storageContentExpiration)
+}
+
+// GetNodeReplicationResponse contains the response for the /nodes/%s/replication endpoint
+type GetNodeReplicationResponse struct {
+	Data []GetNodeReplicationData `json:"data"`
+}
+
+// GetNodeReplicationData contains the status of a storage replication job that runs on a node
+type GetNodeReplicationData struct {
+	ID        string   `json:"id"`
+	Guest     int      `json:"guest"`
+	Target    string   `json:"target"`
+	Type      string   `json:"type"`
+	JobNum    *int     `json:"jobnum"`
+	Schedule  *string  `json:"schedule"`
+	Disable   *int     `json:"disable"`
+	LastSync  *int     `json:"last_sync"`
+	LastTry   *int     `json:"last_try"`
+	NextSync  *int     `json:"next_sync"`
+	Duration  *float64 `json:"duration"`
+	FailCount *int     `json:"fail_count"`
+	Error     *string  `json:"error"`
+	VMType    *string  `json:"vmtype"`
+}
+
+// GetNodeReplication returns the status of the storage replication jobs that run on a node
+func GetNodeReplication(name string) (*GetNodeReplicationResponse, error) {
+	return getResource[GetNodeReplicationResponse](fmt.Sprintf("GetNodeReplication_%s", name), fmt.Sprintf("nodes/%s/replication", name), nil, cache.DefaultExpiration)
+}
+
+// GetNodeDisksZFSResponse contains the response for the /nodes/%s/disks/zfs endpoint
+type GetNodeDisksZFSResponse struct {
+	Data []GetNodeDisksZFSData `json:"data"`
+}
+
+// GetNodeDisksZFSData contains the usage and health of a ZFS pool
+type GetNodeDisksZFSData struct {
+	Name   string  `json:"name"`
+	Health string  `json:"health"`
+	Size   int     `json:"size"`
+	Alloc  int     `json:"alloc"`
+	Free   int     `json:"free"`
+	Frag   int     `json:"frag"`
+	Dedup  float64 `json:"dedup"`
+}
+
+// GetNodeDisksZFS returns the ZFS pools of a node
+func GetNodeDisksZFS(name string) (*GetNodeDisksZFSResponse, error) {
+	return getResource[GetNodeDisksZFSResponse](fmt.Sprintf("GetNodeDisksZFS_%s", name), fmt.Sprintf("nodes/%s/disks/zfs", name), nil, cache.DefaultExpiration)
+}
+
+// GetNodeDisksZFSDetailResponse contains the response for the /nodes/%s/disks/zfs/%s endpoint
+type GetNodeDisksZFSDetailResponse struct {
+	Data GetNodeDisksZFSDetailData `json:"data"`
+}
+
+// GetNodeDisksZFSDetailData contains the status of a ZFS pool, as reported by zpool status
+type GetNodeDisksZFSDetailData struct {
+	Name     string                       `json:"name"`
+	State    string                       `json:"state"`
+	Status   *string                      `json:"status"`
+	Action   *string                      `json:"action"`
+	Scan     *string                      `json:"scan"`
+	Errors   *string                      `json:"errors"`
+	Children []GetNodeDisksZFSDetailChild `json:"children"`
+}
+
+// GetNodeDisksZFSDetailChild contains the state and error counts of a vdev or device in a ZFS pool.
+// Error counts are strings when zpool status abbreviates them (ex: 1.2K)
+type GetNodeDisksZFSDetailChild struct {
+	Name     string                       `json:"name"`
+	State    *string                      `json:"state"`
+	Read     *proxmox.IntOrString         `json:"read"`
+	Write    *proxmox.IntOrString         `json:"write"`
+	Cksum    *proxmox.IntOrString         `json:"cksum"`
+	Msg      *string                      `json:"msg"`
+	Leaf     *int                         `json:"leaf"`
+	Children []GetNodeDisksZFSDetailChild `json:"children"`
+}
+
+// GetNodeDisksZFSDetail returns the status of a ZFS pool and its vdevs
+func GetNodeDisksZFSDetail(name, pool string) (*GetNodeDisksZFSDetailResponse, error) {
+	return getResource[GetNodeDisksZFSDetailResponse](fmt.Sprintf("GetNodeDisksZFSDetail_%s_%s", name, pool), fmt.Sprintf("nodes/%s/disks/zfs/%s", name, pool), nil, cache.DefaultExpiration)
+}
+
+// GetNodeDisksLVMThinResponse contains the response for the /nodes/%s/disks/lvmthin endpoint
+type GetNodeDisksLVMThinResponse struct {
+	Data []GetNodeDisksLVMThinData `json:"data"`
+}
+
+// GetNodeDisksLVMThinData contains the data and metadata usage of an LVM thin pool
+type GetNodeDisksLVMThinData struct {
+	LV           string `json:"lv"`
+	VG           string `json:"vg"`
+	LVSize       int    `json:"lv_size"`
+	Used         int    `json:"used"`
+	MetadataSize int    `json:"metadata_size"`
+	MetadataUsed int    `json:"metadata_used"`
+}
+
+// GetNodeDisksLVMThin returns the LVM thin pools of a node
+func GetNodeDisksLVMThin(name string) (*GetNodeDisksLVMThinResponse, error) {
+	return getResource[GetNodeDisksLVMThinResponse](fmt.Sprintf("GetNodeDisksLVMThin_%s", name), fmt.Sprintf("nodes/%s/disks/lvmthin", name), nil, cache.DefaultExpiration)
+}
+
+// GetNodeDisksLVMResponse contains the response for the /nodes/%s/disks/lvm endpoint
+type GetNodeDisksLVMResponse struct {
+	Data GetNodeDisksLVMData `json:"data"`
+}
+
+// GetNodeDisksLVMData contains the volume groups of a node, as children of the root of a tree
+type GetNodeDisksLVMData struct {
+	Leaf     int                        `json:"leaf"`
+	Children []GetNodeDisksLVMChildData `json:"children"`
+}
+
+// GetNodeDisksLVMChildData contains the size and free space of a volume group, or of a physical volume in one of its children
+type GetNodeDisksLVMChildData struct {
+	Name     string                     `json:"name"`
+	Size     int                        `json:"size"`
+	Free     int                        `json:"free"`
+	LVCount  *int                       `json:"lvcount"`
+	Leaf     int                        `json:"leaf"`
+	Children []GetNodeDisksLVMChildData `json:"children"`
+}
+
+// GetNodeDisksLVM returns the LVM volume groups of a node
+func GetNodeDisksLVM(name string) (*GetNodeDisksLVMResponse, error) {
+	return getResource[GetNodeDisksLVMResponse](fmt.Sprintf("GetNodeDisksLVM_%s", name), fmt.Sprintf("nodes/%s/disks/lvm", name), nil, cache.DefaultExpiration)
+}
+
+// smartExpiration is how long a disk's SMART data is cached for.
+// Reading SMART data runs smartctl against the disk on the node, and the attributes it's exported from change slowly
+const smartExpiration = 30 * time.Minute
+
+// GetNodeDisksSMARTOptions contains the query parameters for the /nodes/%s/disks/smart endpoint
+type GetNodeDisksSMARTOptions struct {
+	// Disk is the block device path of the disk (ex: /dev/sda)
+	Disk string `url:"disk"`
+}
+
+// GetNodeDisksSMARTResponse contains the response for the /nodes/%s/disks/smart endpoint
+type GetNodeDisksSMARTResponse struct {
+	Data GetNodeDisksSMARTData `json:"data"`
+}
+
+// GetNodeDisksSMARTData contains the SMART data of a disk.
+// ATA disks report a list of attributes, while NVMe and SAS disks report smartctl's text output
+type GetNodeDisksSMARTData struct {
+	Health     string                       `json:"health"`
+	Type       string                       `json:"type"`
+	Attributes []GetNodeDisksSMARTAttribute `json:"attributes"`
+	Text       *string                      `json:"text"`
+}
+
+// GetNodeDisksSMARTAttribute contains one SMART attribute of an ATA disk
+type GetNodeDisksSMARTAttribute struct {
+	ID         proxmox.IntOrString `json:"id"`
+	Name       string              `json:"name"`
+	Value      proxmox.IntOrString `json:"value"`
+	Worst      proxmox.IntOrString `json:"worst"`
+	Threshold  proxmox.IntOrString `json:"threshold"`
+	Raw        string              `json:"raw"`
+	Normalized *float64            `json:"normalized"`
+	Flags      *string             `json:"flags"`
+	Fail       *string             `json:"fail"`
+}
+
+// GetNodeDisksSMART returns the SMART data of a node's disk
+func GetNodeDisksSMART(name, disk string) (*GetNodeDisksSMARTResponse, error) {
+	opt := GetNodeDisksSMARTOptions{Disk: disk}
+	return getResource[GetNodeDisksSMARTResponse](fmt.Sprintf("GetNodeDisksSMART_%s_%s", name, disk), fmt.Sprintf("nodes/%s/disks/smart", name), &opt, smartExpiration)
+}
+
+// GetNodeSubscriptionResponse contains the response for the /nodes/%s/subscription endpoint
+type GetNodeSubscriptionResponse struct {
+	Data GetNodeSubscriptionData `json:"data"`
+}
+
+// GetNodeSubscriptionData contains the subscription status of a node.
+// The subscription key is left out so it isn't kept in memory
+type GetNodeSubscriptionData struct {
+	Status      string  `json:"status"`
+	Level       *string `json:"level"`
+	ProductName *string `json:"productname"`
+	NextDueDate *string `json:"nextduedate"`
+	RegDate     *string `json:"regdate"`
+	CheckTime   *int    `json:"checktime"`
+	Sockets     *int    `json:"sockets"`
+	Message     *string `json:"message"`
+}
+
+// GetNodeSubscription returns the subscription status of a node
+func GetNodeSubscription(name string) (*GetNodeSubscriptionResponse, error) {
+	return getResource[GetNodeSubscriptionResponse](fmt.Sprintf("GetNodeSubscription_%s", name), fmt.Sprintf("nodes/%s/subscription", name), nil, cache.DefaultExpiration)
+}
+
+// GetNodeAptUpdateResponse contains the response for the /nodes/%s/apt/update endpoint
+type GetNodeAptUpdateResponse struct {
+	Data []GetNodeAptUpdateData `json:"data"`
+}
+
+// GetNodeAptUpdateData contains a package that has an update available
+type GetNodeAptUpdateData struct {
+	Package    string `json:"Package"`
+	Title      string `json:"Title"`
+	Version    string `json:"Version"`
+	OldVersion string `json:"OldVersion"`
+	Origin     string `json:"Origin"`
+	Priority   string `json:"Priority"`
+	Section    string `json:"Section"`
+	Arch       string `json:"Arch"`
+}
+
+// GetNodeAptUpdate returns the packages that have updates available on a node, as of the node's last package database update
+func GetNodeAptUpdate(name string) (*GetNodeAptUpdateResponse, error) {
+	return getResource[GetNodeAptUpdateResponse](fmt.Sprintf("GetNodeAptUpdate_%s", name), fmt.Sprintf("nodes/%s/apt/update", name), nil, cache.DefaultExpiration)
+}
+
+// GetNodeAptVersionsResponse contains the response for the /nodes/%s/apt/versions endpoint
+type GetNodeAptVersionsResponse struct {
+	Data []GetNodeAptVersionsData `json:"data"`
+}
+
+// GetNodeAptVersionsData contains the installed version of a Proxmox related package
+type GetNodeAptVersionsData struct {
+	Package        string  `json:"Package"`
+	Title          string  `json:"Title"`
+	Version        string  `json:"Version"`
+	OldVersion     *string `json:"OldVersion"`
+	CurrentState   string  `json:"CurrentState"`
+	RunningKernel  *string `json:"RunningKernel"`
+	ManagerVersion *string `json:"ManagerVersion"`
+}
+
+// GetNodeAptVersions returns the versions of the Proxmox related packages on a node
+func GetNodeAptVersions(name string) (*GetNodeAptVersionsResponse, error) {
+	return getResource[GetNodeAptVersionsResponse](fmt.Sprintf("GetNodeAptVersions_%s", name), fmt.Sprintf("nodes/%s/apt/versions", name), nil, cache.DefaultExpiration)
+}
+
+// GetNodeServicesResponse contains the response for the /nodes/%s/services endpoint
+type GetNodeServicesResponse struct {
+	Data []GetNodeServicesData `json:"data"`
+}
+
+// GetNodeServicesData contains the state of a PVE related systemd service
+type GetNodeServicesData struct {
+	Service     string  `json:"service"`
+	Name        string  `json:"name"`
+	Desc        string  `json:"desc"`
+	State       string  `json:"state"`
+	ActiveState *string `json:"active-state"`
+	UnitState   *string `json:"unit-state"`
+}
+
+// GetNodeServices returns the state of the PVE related services on a node
+func GetNodeServices(name string) (*GetNodeServicesResponse, error) {
+	return getResource[GetNodeServicesResponse](fmt.Sprintf("GetNodeServices_%s", name), fmt.Sprintf("nodes/%s/services", name), nil, cache.DefaultExpiration)
+}
+
+// GetNodeTimeResponse contains the response for the /nodes/%s/time endpoint
+type GetNodeTimeResponse struct {
+	Data GetNodeTimeData `json:"data"`
+
+	// RequestTime is the exporter's time when the node read its clock, estimated as the midpoint of the request's round trip
+	RequestTime time.Time `json:"-"`
+}
+
+// GetNodeTimeData contains the time of a node
+type GetNodeTimeData struct {
+	Timezone  string `json:"timezone"`
+	Time      int    `json:"time"`
+	LocalTime int    `json:"localtime"`
+}
+
+// GetNodeTime returns the time of a node, and the exporter's time when it was read to compare it with.
+// The request is timed itself rather than through getResource, so its round trip time isn't included in the comparison
+func GetNodeTime(name string) (*GetNodeTimeResponse, error) {
+	cacheKey := fmt.Sprintf("GetNodeTime_%s", name)
+	if x, found := cash.Get(cacheKey); found {
+		out, ok := x.(*GetNodeTimeResponse)
+		if ok {
+			log.Logger.Debug("proxmox request was found in cache", "key", cacheKey)
+			return out, nil
+		}
+	}
+
+	start := time.Now()
+	out, err := requestResource[GetNodeTimeResponse](fmt.Sprintf("nodes/%s/time", name), nil)
+	if err != nil {
+		return nil, err
+	}
+	out.RequestTime = start.Add(time.Since(start) / 2)
+
+	cash.Set(cacheKey, out, cache.DefaultExpiration)
+	return out, nil
+}
+
+// GetNodeNetworkResponse contains the response for the /nodes/%s/network endpoint
+type GetNodeNetworkResponse struct {
+	Data []GetNodeNetworkData `json:"data"`
+	// Changes is the diff of the node's pending network configuration changes, which is empty if there aren't any
+	Changes *string `json:"changes"`
+}
+
+// GetNodeNetworkData contains the configuration and state of a network interface
+type GetNodeNetworkData struct {
+	Iface       string   `json:"iface"`
+	Type        string   `json:"type"`
+	Active      *int     `json:"active"`
+	Autostart   *int     `json:"autostart"`
+	Exists      *int     `json:"exists"`
+	Method      *string  `json:"method"`
+	Families    []string `json:"families"`
+	CIDR        *string  `json:"cidr"`
+	BridgePorts *string  `json:"bridge_ports"`
+	Slaves      *string  `json:"slaves"`
+	BondMode    *string  `json:"bond_mode"`
+	OVSBonds    *string  `json:"ovs_bonds"`
+	OVSPorts    *string  `json:"ovs_ports"`
+	VLANRawDev  *string  `json:"vlan-raw-device"`
+	Comments    *string  `json:"comments"`
+}
+
+// GetNodeNetwork returns the network interfaces of a node
+func GetNodeNetwork(name string) (*GetNodeNetworkResponse, error) {
+	return getResource[GetNodeNetworkResponse](fmt.Sprintf("GetNodeNetwork_%s", name), fmt.Sprintf("nodes/%s/network", name), nil, cache.DefaultExpiration)
+}
+
+// GetNodeFirewallOptionsResponse contains the response for the /nodes/%s/firewall/options endpoint
+type GetNodeFirewallOptionsResponse struct {
+	Data GetNodeFirewallOptionsData `json:"data"`
+}
+
+// GetNodeFirewallOptionsData contains a node's firewall options. Options that were never set are omitted
+type GetNodeFirewallOptionsData struct {
+	Enable *int `json:"enable"`
+}
+
+// GetNodeFirewallOptions returns the firewall options of a node
+func GetNodeFirewallOptions(name string) (*GetNodeFirewallOptionsResponse, error) {
+	return getResource[GetNodeFirewallOptionsResponse](fmt.Sprintf("GetNodeFirewallOptions_%s", name), fmt.Sprintf("nodes/%s/firewall/options", name), nil, firewallExpiration)
+}
+
+// GetNodeFirewallRules returns the firewall rules of a node
+func GetNodeFirewallRules(name string) (*GetFirewallRulesResponse, error) {
+	return getResource[GetFirewallRulesResponse](fmt.Sprintf("GetNodeFirewallRules_%s", name), fmt.Sprintf("nodes/%s/firewall/rules", name), nil, firewallExpiration)
+}
+
+// GetGuestFirewallOptionsResponse contains the response for the /nodes/%s/%s/%d/firewall/options endpoint
+type GetGuestFirewallOptionsResponse struct {
+	Data GetGuestFirewallOptionsData `json:"data"`
+}
+
+// GetGuestFirewallOptionsData contains a guest's firewall options. Options that were never set are omitted
+type GetGuestFirewallOptionsData struct {
+	Enable    *int    `json:"enable"`
+	PolicyIn  *string `json:"policy_in"`
+	PolicyOut *string `json:"policy_out"`
+	DHCP      *int    `json:"dhcp"`
+	IPFilter  *int    `json:"ipfilter"`
+	MACFilter *int    `json:"macfilter"`
+}
+
+// GetGuestFirewallOptions returns the firewall options of a guest. guestType is either qemu or lxc
+func GetGuestFirewallOptions(name, guestType string, vmID int) (*GetGuestFirewallOptionsResponse, error) {
+	return getResource[GetGuestFirewallOptionsResponse](fmt.Sprintf("GetGuestFirewallOptions_%s_%d", guestType, vmID), fmt.Sprintf("nodes/%s/%s/%d/firewall/options", name, guestType, vmID), nil, jitter(firewallExpiration))
+}
+
+// GetGuestFirewallRules returns the firewall rules of a guest. guestType is either qemu or lxc
+func GetGuestFirewallRules(name, guestType string, vmID int) (*GetFirewallRulesResponse, error) {
+	return getResource[GetFirewallRulesResponse](fmt.Sprintf("GetGuestFirewallRules_%s_%d", guestType, vmID), fmt.Sprintf("nodes/%s/%s/%d/firewall/rules", name, guestType, vmID), nil, jitter(firewallExpiration))
+}
+
+// guestConfigExpiration is how long guest configurations are cached for.
+// They're requested for every guest, and the options exported from them rarely change.
+// They're cached with a jittered expiration, so they're requested again spread out over time
+const guestConfigExpiration = 10 * time.Minute
+
+// GetQemuConfigResponse contains the response for the /nodes/%s/qemu/%d/config endpoint
+type GetQemuConfigResponse struct {
+	Data GetQemuConfigData `json:"data"`
+}
+
+// GetQemuConfigData contains the configuration of a VM. Options that were never set are omitted
+type GetQemuConfigData struct {
+	Name       *string              `json:"name"`
+	OnBoot     *int                 `json:"onboot"`
+	Protection *int                 `json:"protection"`
+	Agent      *proxmox.IntOrString `json:"agent"`
+	Balloon    *int                 `json:"balloon"`
+	Memory     *proxmox.IntOrString `json:"memory"`
+	Sockets    *int                 `json:"sockets"`
+	Cores      *int                 `json:"cores"`
+	CPU        *string              `json:"cpu"`
+	Machine    *string              `json:"machine"`
+	OSType     *string              `json:"ostype"`
+	Net0       *string              `json:"net0"`
+}
+
+// GetQemuConfig returns the current configuration of a VM
+func GetQemuConfig(name string, vmID int) (*GetQemuConfigResponse, error) {
+	return getResource[GetQemuConfigResponse](fmt.Sprintf("GetQemuConfig_%d", vmID), fmt.Sprintf("nodes/%s/qemu/%d/config", name, vmID), nil, jitter(guestConfigExpiration))
+}
+
+// GetLxcConfigResponse contains the response for the /nodes/%s/lxc/%d/config endpoint
+type GetLxcConfigResponse struct {
+	Data GetLxcConfigData `json:"data"`
+}
+
+// GetLxcConfigData contains the configuration of a LXC. Options that were never set are omitted
+type GetLxcConfigData struct {
+	Hostname     *string `json:"hostname"`
+	OnBoot       *int    `json:"onboot"`
+	Protection   *int    `json:"protection"`
+	Cores        *int    `json:"cores"`
+	Memory       *int    `json:"memory"`
+	Swap         *int    `json:"swap"`
+	OSType       *string `json:"ostype"`
+	Arch         *string `json:"arch"`
+	Unprivileged *int    `json:"unprivileged"`
+	Net0         *string `json:"net0"`
+}
+
+// GetLxcConfig returns the current configuration of a LXC
+func GetLxcConfig(name string, vmID int) (*GetLxcConfigResponse, error) {
+	return getResource[GetLxcConfigResponse](fmt.Sprintf("GetLxcConfig_%d", vmID), fmt.Sprintf("nodes/%s/lxc/%d/config", name, vmID), nil, jitter(guestConfigExpiration))
+}
+
+// PostQemuAgentPingResponse contains the response for the /nodes/%s/qemu/%d/agent/ping endpoint
+type PostQemuAgentPingResponse struct {
+	Data interface{} `json:"data"`
+}
+
+// PostQemuAgentPing pings the QEMU guest agent of a VM, which fails if the agent isn't running
+func PostQemuAgentPing(name string, vmID int) (*PostQemuAgentPingResponse, error) {
+	return getAgentResource[PostQemuAgentPingResponse](fmt.Sprintf("PostQemuAgentPing_%d", vmID), http.MethodPost, fmt.Sprintf("nodes/%s/qemu/%d/agent/ping", name, vmID))
+}
+
+// GetQemuAgentFsInfoResponse contains the response for the /nodes/%s/qemu/%d/agent/get-fsinfo endpoint
+type GetQemuAgentFsInfoResponse struct {
+	Data GetQemuAgentFsInfoData `json:"data"`
+}
+
+// GetQemuAgentFsInfoData contains the result of the guest agent's get-fsinfo command
+type GetQemuAgentFsInfoData struct {
+	Result []GetQemuAgentFsInfoResult `json:"result"`
+}
+
+// GetQemuAgentFsInfoResult contains a filesystem mounted inside a VM. The agent only reports its usage for filesystems it can stat
+type GetQemuAgentFsInfoResult struct {
+	Name       string `json:"name"`
+	Mountpoint string `json:"mountpoint"`
+	Type       string `json:"type"`
+	TotalBytes *int   `json:"total-bytes"`
+	UsedBytes  *int   `json:"used-bytes"`
+}
+
+// GetQemuAgentFsInfo returns the filesystems mounted inside a VM from its QEMU guest agent
+func GetQemuAgentFsInfo(name string, vmID int) (*GetQemuAgentFsInfoResponse, error) {
+	return getAgentResource[GetQemuAgentFsInfoResponse](fmt.Sprintf("GetQemuAgentFsInfo_%d", vmID), http.MethodGet, fmt.Sprintf("nodes/%s/qemu/%d/agent/get-fsinfo", name, vmID))
+}
+
+// GetQemuAgentOsInfoResponse contains the response for the /nodes/%s/qemu/%d/agent/get-osinfo endpoint
+type GetQemuAgentOsInfoResponse struct {
+	Data GetQemuAgentOsInfoData `json:"data"`
+}
+
+// GetQemuAgentOsInfoData contains the result of the guest agent's get-osinfo command
+type GetQemuAgentOsInfoData struct {
+	Result GetQemuAgentOsInfoResult `json:"result"`
+}
+
+// GetQemuAgentOsInfoResult contains the operating system of a VM. Fields the guest's OS doesn't report are omitted
+type GetQemuAgentOsInfoResult struct {
+	ID            *string `json:"id"`
+	Name          *string `json:"name"`
+	PrettyName    *string `json:"pretty-name"`
+	Version       *string `json:"version"`
+	VersionID     *string `json:"version-id"`
+	KernelRelease *string `json:"kernel-release"`
+	KernelVersion *string `json:"kernel-version"`
+	Machine       *string `json:"machine"`
+}
+
+// GetQemuAgentOsInfo returns the operating system of a VM from its QEMU guest agent
+func GetQemuAgentOsInfo(name string, vmID int) (*GetQemuAgentOsInfoResponse, error) {
+	return getAgentResource[GetQemuAgentOsInfoResponse](fmt.Sprintf("GetQemuAgentOsInfo_%d", vmID), http.MethodGet, fmt.Sprintf("nodes/%s/qemu/%d/agent/get-osinfo", name, vmID))
+}
+
+// GetQemuAgentNetworkInterfacesResponse contains the response for the /nodes/%s/qemu/%d/agent/network-get-interfaces endpoint
+type GetQemuAgentNetworkInterfacesResponse struct {
+	Data GetQemuAgentNetworkInterfacesData `json:"data"`
+}
+
+// GetQemuAgentNetworkInterfacesData contains the result of the guest agent's network-get-interfaces command
+type GetQemuAgentNetworkInterfacesData struct {
+	Result []GetQemuAgentNetworkInterfacesResult `json:"result"`
+}
+
+// GetQemuAgentNetworkInterfacesResult contains a network interface inside a VM and its addresses
+type GetQemuAgentNetworkInterfacesResult struct {
+	Name            string                                 `json:"name"`
+	HardwareAddress *string                                `json:"hardware-address"`
+	IPAddresses     []GetQemuAgentNetworkInterfacesAddress `json:"ip-addresses"`
+}
+
+// GetQemuAgentNetworkInterfacesAddress contains an address of a network interface inside a VM. IPAddressType is either ipv4 or ipv6
+type GetQemuAgentNetworkInterfacesAddress struct {
+	IPAddress     string `json:"ip-address"`
+	IPAddressType string `json:"ip-address-type"`
+	Prefix        int    `json:"prefix"`
+}
+
+// GetQemuAgentNetworkInterfaces returns the network interfaces inside a VM from its QEMU guest agent
+func GetQemuAgentNetworkInterfaces(name string, vmID int) (*GetQemuAgentNetworkInterfacesResponse, error) {
+	return getAgentResource[GetQemuAgentNetworkInterfacesResponse](fmt.Sprintf("GetQemuAgentNetworkInterfaces_%d", vmID), http.MethodGet, fmt.Sprintf("nodes/%s/qemu/%d/agent/network-get-interfaces", name, vmID))
+}
+
+// GetLxcInterfacesResponse contains the response for the /nodes/%s/lxc/%d/interfaces endpoint
+type GetLxcInterfacesResponse struct {
+	Data []GetLxcInterfacesData `json:"data"`
+}
+
+// GetLxcInterfacesData contains a network interface inside a running LXC. Inet and Inet6 are addresses in CIDR notation
+type GetLxcInterfacesData struct {
+	Name   string  `json:"name"`
+	HWAddr *string `json:"hwaddr"`
+	Inet   *string `json:"inet"`
+	Inet6  *string `json:"inet6"`
+}
+
+// GetLxcInterfaces returns the network interfaces inside a running LXC
+func GetLxcInterfaces(name string, vmID int) (*GetLxcInterfacesResponse, error) {
+	return getResource[GetLxcInterfacesResponse](fmt.Sprintf("GetLxcInterfaces_%d", vmID), fmt.Sprintf("nodes/%s/lxc/%d/interfaces", name, vmID), nil, cache.DefaultExpiration)
+}
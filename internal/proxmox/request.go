@@ -0,0 +1,216 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	proxmox "github.com/starttoaster/go-proxmox"
+	log "github.com/starttoaster/proxmox-exporter/internal/logger"
+)
+
+// QEMU guest agent requests are passed through to an agent inside the guest, which may not be running or may hang until PVE gives up on it.
+// They're made with their own timeout, and limited in how many may be in flight at once so hung agents don't hold up other requests
+var (
+	agentTimeout  = 5 * time.Second
+	agentRequests = make(chan struct{}, 4)
+)
+
+// Failed QEMU guest agent requests aren't made again until a backoff has passed, which doubles with each consecutive failure.
+// This keeps hung agents, which fail only after the full timeout, from taking up the limited agent requests on every scrape
+const (
+	agentBackoffMin = 30 * time.Second
+	agentBackoffMax = 10 * time.Minute
+)
+
+// agentFailure contains the consecutive failures of a guest agent request
+type agentFailure struct {
+	failures int
+	retryAt  time.Time
+	err      error
+}
+
+// SetGuestAgentLimits sets the timeout of each QEMU guest agent request, and how many may be in flight at once
+func SetGuestAgentLimits(timeout time.Duration, concurrency int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	agentTimeout = timeout
+	agentRequests = make(chan struct{}, concurrency)
+}
+
+// jitter returns expiration lengthened by a random amount of up to half of it.
+// Resources cached for every guest in the same scrape are cached with a jittered expiration, so they don't all expire and get requested again in one scrape
+func jitter(expiration time.Duration) time.Duration {
+	return expiration + rand.N(expiration/2+1)
+}
+
+// getResource makes a GET request to an API path that the go-proxmox client doesn't have a method for, and decodes the response into a new T.
+// The response is cached under cacheKey for the given expiration, which may be cache.DefaultExpiration.
+func getResource[T any](cacheKey, path string, opt interface{}, expiration time.Duration) (*T, error) {
+	// Check cache
+	if x, found := cash.Get(cacheKey); found {
+		out, ok := x.(*T)
+		if ok {
+			log.Logger.Debug("proxmox request was found in cache", "key", cacheKey)
+			return out, nil
+		}
+	}
+
+	// Make request if not found in cache
+	out, err := requestResource[T](path, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update cache
+	cash.Set(cacheKey, out, expiration)
+
+	return out, nil
+}
+
+// requestResource makes an uncached GET request to an API path with the first client that isn't banned, and decodes the response into a new T.
+// Clients are only banned for errors that aren't 4xx responses, since those are specific to the request (ex: missing permissions) rather than the API server.
+func requestResource[T any](path string, opt interface{}) (*T, error) {
+	var out *T
+	var err error
+	for clientName, c := range currentClients() {
+		// Check if client was banned, skip if is
+		if c.banned {
+			continue
+		}
+
+		var resp *http.Response
+		out, resp, err = doGet[T](c.client, path, opt)
+		if err == nil {
+			break
+		}
+		if resp != nil && resp.StatusCode >= 400 && resp.StatusCode < 500 {
+			return nil, fmt.Errorf("request to %s failed: %w", path, err)
+		}
+		banClient(clientName, c)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if out == nil {
+		return nil, fmt.Errorf("request to %s was not successful. It's possible all clients are banned", path)
+	}
+
+	return out, nil
+}
+
+// getAgentResource makes a QEMU guest agent request to an API path, and decodes the response into a new T.
+// Successful responses are cached under cacheKey for the default expiration. Failed requests return an error without being made again until their backoff has passed
+func getAgentResource[T any](cacheKey, method, path string) (*T, error) {
+	// Check cache
+	if x, found := cash.Get(cacheKey); found {
+		out, ok := x.(*T)
+		if ok {
+			log.Logger.Debug("proxmox request was found in cache", "key", cacheKey)
+			return out, nil
+		}
+	}
+
+	// Check backoff of a previously failed request
+	failureKey := "agentFailure_" + cacheKey
+	var failure agentFailure
+	if x, found := cash.Get(failureKey); found {
+		if f, ok := x.(agentFailure); ok {
+			failure = f
+			if time.Now().Before(failure.retryAt) {
+				return nil, fmt.Errorf("guest agent request to %s skipped until %s after %d failures: %w", path, failure.retryAt.Format(time.RFC3339), failure.failures, failure.err)
+			}
+		}
+	}
+
+	// Wait for one of the limited guest agent requests to be available
+	requests := agentRequests
+	requests <- struct{}{}
+	defer func() { <-requests }()
+
+	out, err := requestAgentResource[T](method, path)
+	if err != nil {
+		failure.failures++
+		failure.err = err
+		failure.retryAt = time.Now().Add(agentBackoff(failure.failures))
+		// The failure count is kept long enough after the backoff to keep growing, and forgotten once the request hasn't been made for a while
+		cash.Set(failureKey, failure, time.Until(failure.retryAt)+agentBackoffMax)
+		return nil, err
+	}
+	cash.Delete(failureKey)
+
+	// Update cache
+	cash.Set(cacheKey, out, cache.DefaultExpiration)
+
+	return out, nil
+}
+
+// agentBackoff returns how long a guest agent request isn't made again for after it failed a number of consecutive times
+func agentBackoff(failures int) time.Duration {
+	backoff := agentBackoffMin
+	for i := 1; i < failures && backoff < agentBackoffMax; i++ {
+		backoff *= 2
+	}
+	return min(backoff, agentBackoffMax)
+}
+
+// requestAgentResource makes an uncached QEMU guest agent request with the first client that isn't banned, and decodes the response into a new T.
+// Clients are only banned when the API server couldn't be reached. Error responses and timeouts are caused by the guest's agent, like an agent that isn't running
+func requestAgentResource[T any](method, path string) (*T, error) {
+	var out *T
+	var err error
+	for clientName, c := range currentClients() {
+		// Check if client was banned, skip if is
+		if c.banned {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), agentTimeout)
+		var resp *http.Response
+		out, resp, err = doRequest[T](ctx, c.client, method, path, nil)
+		timedOut := ctx.Err() != nil
+		cancel()
+		if err == nil {
+			break
+		}
+		if resp != nil || timedOut {
+			return nil, fmt.Errorf("guest agent request to %s failed: %w", path, err)
+		}
+		banClient(clientName, c)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if out == nil {
+		return nil, fmt.Errorf("request to %s was not successful. It's possible all clients are banned", path)
+	}
+
+	return out, nil
+}
+
+// doGet makes a single GET request with a proxmox client and decodes the response into a new T
+func doGet[T any](c *proxmox.Client, path string, opt interface{}) (*T, *http.Response, error) {
+	return doRequest[T](context.Background(), c, http.MethodGet, path, opt)
+}
+
+// doRequest makes a single request with a proxmox client that's canceled along with ctx, and decodes the response into a new T
+func doRequest[T any](ctx context.Context, c *proxmox.Client, method, path string, opt interface{}) (*T, *http.Response, error) {
+	req, err := c.NewRequest(method, path, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := new(T)
+	resp, err := c.Do(req.WithContext(ctx), out)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return out, resp, nil
+}
@@ -0,0 +1,108 @@
+package proxmox
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+type testResourceResponse struct {
+	Data []struct {
+		Name string `json:"name"`
+	} `json:"data"`
+}
+
+func TestGetResource_Integration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/test/resource", jsonHandler(`{"data": [{"name": "a"}, {"name": "b"}]}`))
+	setupIntegrationTest(t, mux)
+
+	resp, err := getResource[testResourceResponse]("test", "test/resource", nil, cache.DefaultExpiration)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(resp.Data))
+	}
+	if resp.Data[0].Name != "a" {
+		t.Errorf("expected name 'a', got %s", resp.Data[0].Name)
+	}
+}
+
+func TestGetResource_Caching(t *testing.T) {
+	var counter atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/test/resource", countingHandler(`{"data": []}`, &counter))
+	setupIntegrationTest(t, mux)
+
+	for i := 0; i < 3; i++ {
+		if _, err := getResource[testResourceResponse]("test", "test/resource", nil, cache.DefaultExpiration); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+
+	if counter.Load() != 1 {
+		t.Errorf("expected 1 HTTP request (later calls should hit cache), got %d", counter.Load())
+	}
+}
+
+func TestGetResource_CustomExpiration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/test/resource", jsonHandler(`{"data": []}`))
+	setupIntegrationTest(t, mux)
+
+	if _, err := getResource[testResourceResponse]("test", "test/resource", nil, time.Hour); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, expiration, found := cash.GetWithExpiration("test")
+	if !found {
+		t.Fatal("expected response to be cached")
+	}
+	if time.Until(expiration) < 59*time.Minute {
+		t.Errorf("expected cache entry to live for about an hour, expires in %v", time.Until(expiration))
+	}
+}
+
+func TestJitter(t *testing.T) {
+	spread := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		expiration := jitter(10 * time.Minute)
+		if expiration < 10*time.Minute || expiration > 15*time.Minute {
+			t.Fatalf("expected expiration between 10m and 15m, got %v", expiration)
+		}
+		spread[expiration] = true
+	}
+	if len(spread) < 2 {
+		t.Error("expected jittered expirations to differ")
+	}
+}
+
+func TestGetResource_ServerErrorBansClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/test/resource", errorHandler(500))
+	setupIntegrationTest(t, mux)
+
+	if _, err := getResource[testResourceResponse]("test", "test/resource", nil, cache.DefaultExpiration); err == nil {
+		t.Fatal("expected error from 500 response")
+	}
+	if GetBannedClientCount() != 1 {
+		t.Errorf("expected 1 banned client, got %d", GetBannedClientCount())
+	}
+}
+
+func TestGetResource_ClientErrorDoesNotBanClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api2/json/test/resource", errorHandler(403))
+	setupIntegrationTest(t, mux)
+
+	if _, err := getResource[testResourceResponse]("test", "test/resource", nil, cache.DefaultExpiration); err == nil {
+		t.Fatal("expected error from 403 response")
+	}
+	if GetBannedClientCount() != 0 {
+		t.Errorf("expected 0 banned clients, got %d", GetBannedClientCount())
+	}
+}
@@ -3,7 +3,7 @@ package proxmox
 // GetBannedClientCount returns the number of banned clients
 func GetBannedClientCount() int {
 	var count int
-	for _, c := range clients {
+	for _, c := range currentClients() {
 		if c.banned {
 			count++
 		}
@@ -14,7 +14,7 @@ func GetBannedClientCount() int {
 // GetUnbannedClientCount returns the number of unbanned clients
 func GetUnbannedClientCount() int {
 	var count int
-	for _, c := range clients {
+	for _, c := range currentClients() {
 		if !c.banned {
 			count++
 		}